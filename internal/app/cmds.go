@@ -2,6 +2,7 @@ package app
 
 import (
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -10,16 +11,27 @@ import (
 	"gitzen/internal/background"
 	"gitzen/internal/components"
 	"gitzen/internal/git"
+	"gitzen/internal/limits"
 	"gitzen/internal/logger"
 )
 
-type statusLoadedMsg struct{ Status git.Status }
+type statusLoadedMsg struct {
+	Status    git.Status
+	RepoState git.RepoState
+}
 
 type commitsLoadedMsg struct{ Commits []git.CommitItem }
 
 type reflogLoadedMsg struct{ Entries []git.ReflogEntry }
 
-type branchLoadedMsg struct{ Branch string }
+type commitSignaturesLoadedMsg struct {
+	Signatures map[string]git.SignatureStatus
+}
+
+type branchLoadedMsg struct {
+	Branch       string
+	DetachedHash string // short hash khi HEAD detached
+}
 
 type branchesLoadedMsg struct{ Branches []git.Branch }
 
@@ -124,6 +136,50 @@ type diffLoadedMsg struct {
 	Subtitle string // file path, commit hash, etc.
 }
 
+// diffStreamMsg báo diff lớn (hơn limits.MaxDiffLines dòng) đang được stream vào
+// Buffer, DiffView chuyển sang virtual scrolling
+type diffStreamMsg struct {
+	Buffer   *git.DiffBuffer
+	Context  int
+	Subtitle string
+}
+
+// diffStreamProgressMsg báo buffer đã nhận thêm dòng hoặc đã kết thúc
+type diffStreamProgressMsg struct{ Buffer *git.DiffBuffer }
+
+// diffStreamThrottle gom nhiều lần buffer nhận thêm dòng thành một lần render
+const diffStreamThrottle = 150 * time.Millisecond
+
+// streamDiff chạy diff dạng stream. Diff nhỏ được đọc hết rồi chuyển cho small
+// (giữ cách xử lý như diff thường), diff lớn trả về diffStreamMsg ngay khi vượt
+// limits.MaxDiffLines dòng, phần còn lại tiếp tục stream trong nền.
+func streamDiff(r git.Runner, start func() (*git.DiffBuffer, error), ctx int, subtitle string, small func(out string, err error) tea.Msg) tea.Msg {
+	buf, err := start()
+	if err != nil {
+		return small("", err)
+	}
+	buf.WaitLines(r.Context(), limits.MaxDiffLines)
+	if r.Context().Err() != nil {
+		buf.Close()
+		return nil
+	}
+	if done, err := buf.Done(); done && buf.Len() <= limits.MaxDiffLines {
+		out := buf.String()
+		buf.Close()
+		return small(out, err)
+	}
+	return diffStreamMsg{Buffer: buf, Context: ctx, Subtitle: subtitle}
+}
+
+// waitDiffStreamCmd chờ buffer nhận thêm dòng (hoặc kết thúc)
+func waitDiffStreamCmd(buf *git.DiffBuffer) tea.Cmd {
+	return func() tea.Msg {
+		<-buf.Changed()
+		time.Sleep(diffStreamThrottle)
+		return diffStreamProgressMsg{Buffer: buf}
+	}
+}
+
 type hunksLoadedMsg struct {
 	Hunks  []git.Hunk
 	Path   string
@@ -169,7 +225,9 @@ func loadStatusCmd(r git.Runner) tea.Cmd {
 			return errMsg(err.Error())
 		}
 		st := git.ParseStatusPorcelainV1Z(b)
-		return statusLoadedMsg{Status: st}
+		// Lỗi đọc state file không chặn việc hiển thị status
+		state, _ := r.RepoState()
+		return statusLoadedMsg{Status: st, RepoState: state}
 	}
 }
 
@@ -190,6 +248,17 @@ func loadCommitsCmd(r git.Runner) tea.Cmd {
 	}
 }
 
+// loadCommitSignaturesCmd verify chữ ký (%G?) riêng vì có thể chậm với nhiều commit đã ký
+func loadCommitSignaturesCmd(r git.Runner) tea.Cmd {
+	return func() tea.Msg {
+		sigs, err := r.LogSignatures(limits.MaxCommits)
+		if err != nil {
+			return nil
+		}
+		return commitSignaturesLoadedMsg{Signatures: sigs}
+	}
+}
+
 func loadReflogCmd(r git.Runner) tea.Cmd {
 	return func() tea.Msg {
 		out, err := r.Reflog()
@@ -200,19 +269,21 @@ func loadReflogCmd(r git.Runner) tea.Cmd {
 	}
 }
 
-func loadDiffCmd(r git.Runner, path string, staged bool) tea.Cmd {
+func loadDiffCmd(r git.Runner, path string, staged bool, opts git.DiffOptions) tea.Cmd {
 	return func() tea.Msg {
 		if strings.TrimSpace(path) == "" {
 			return diffLoadedMsg{Diff: "", Context: diffContextNone}
 		}
-		out, err := r.DiffFile(path, staged)
-		if err != nil {
-			return errMsg(err.Error())
-		}
-		if strings.TrimSpace(out) == "" {
-			out = "(no diff)"
-		}
-		return diffLoadedMsg{Diff: out, Context: diffContextFile, Subtitle: path}
+		start := func() (*git.DiffBuffer, error) { return r.StreamDiffFile(path, staged, opts) }
+		return streamDiff(r, start, diffContextFile, path, func(out string, err error) tea.Msg {
+			if err != nil {
+				return errMsg(err.Error())
+			}
+			if strings.TrimSpace(out) == "" {
+				out = "(no diff)"
+			}
+			return diffLoadedMsg{Diff: out, Context: diffContextFile, Subtitle: path}
+		})
 	}
 }
 
@@ -221,7 +292,7 @@ func loadHunksCmd(r git.Runner, path string, staged bool) tea.Cmd {
 		if strings.TrimSpace(path) == "" {
 			return hunksLoadedMsg{Hunks: nil, Path: "", Staged: staged}
 		}
-		out, err := r.DiffFile(path, staged)
+		out, err := r.DiffFile(path, staged, git.DiffOptions{})
 		if err != nil {
 			return errMsg(err.Error())
 		}
@@ -230,31 +301,60 @@ func loadHunksCmd(r git.Runner, path string, staged bool) tea.Cmd {
 	}
 }
 
-func loadShowCommitCmd(r git.Runner, hash string) tea.Cmd {
+func loadShowCommitCmd(r git.Runner, hash string, opts git.DiffOptions) tea.Cmd {
 	return func() tea.Msg {
 		if strings.TrimSpace(hash) == "" {
 			return diffLoadedMsg{Diff: "", Context: diffContextNone}
 		}
-		out, err := r.ShowCommit(hash)
-		if err != nil {
-			return errMsg(err.Error())
+		// Lấy chữ ký trước: diff nhỏ chèn vào header, diff lớn (stream) hiển thị trên title
+		sig, sigErr := r.CommitSignature(hash)
+		signed := sigErr == nil && sig.Status != git.SignatureNone
+		subtitle := hash
+		if signed {
+			subtitle += " · " + sig.Summary()
+		}
+		start := func() (*git.DiffBuffer, error) { return r.StreamShowCommit(hash, opts) }
+		return streamDiff(r, start, diffContextCommit, subtitle, func(out string, err error) tea.Msg {
+			if err != nil {
+				return errMsg(err.Error())
+			}
+			if signed {
+				out = withSignatureHeader(out, sig)
+			}
+			return diffLoadedMsg{Diff: out, Context: diffContextCommit, Subtitle: hash}
+		})
+	}
+}
+
+// withSignatureHeader chèn dòng "Signature:" sau header Date: của git show
+func withSignatureHeader(show string, sig git.SignatureInfo) string {
+	line := "Signature: " + sig.Summary()
+	if sig.Fingerprint != "" && sig.Fingerprint != sig.Key {
+		line += "\nFingerprint: " + sig.Fingerprint
+	}
+	lines := strings.Split(show, "\n")
+	for i, l := range lines {
+		if strings.HasPrefix(l, "Date:") {
+			return strings.Join(append(lines[:i+1], append([]string{line}, lines[i+1:]...)...), "\n")
 		}
-		return diffLoadedMsg{Diff: out, Context: diffContextCommit, Subtitle: hash}
 	}
+	return line + "\n" + show
 }
 
 // loadSplitDiffCmd loads both unstaged and staged diffs for a file
-func loadSplitDiffCmd(r git.Runner, path string) tea.Cmd {
+func loadSplitDiffCmd(r git.Runner, path string, opts git.DiffOptions) tea.Cmd {
 	return func() tea.Msg {
 		if strings.TrimSpace(path) == "" {
 			return splitDiffLoadedMsg{}
 		}
 
 		// Get unstaged diff
-		unstaged, _ := r.DiffFile(path, false)
+		unstaged, _ := r.DiffFile(path, false, opts)
+		unstaged = r.SummarizeBinaryDiff(unstaged, true, opts.SmudgeLFS)
 
 		// Get staged diff
-		staged, _ := r.DiffFile(path, true)
+		staged, _ := r.DiffFile(path, true, opts)
+		staged = r.SummarizeBinaryDiff(staged, false, opts.SmudgeLFS)
 
 		return splitDiffLoadedMsg{
 			Unstaged: unstaged,
@@ -267,7 +367,7 @@ func loadSplitDiffCmd(r git.Runner, path string) tea.Cmd {
 // Stage a specific file
 func stageFileCmd(r git.Runner, path string) tea.Cmd {
 	return func() tea.Msg {
-		cmd := fmt.Sprintf("git add -- %s", path)
+		cmd := fmt.Sprintf("git --literal-pathspecs add -- %s", path)
 		if err := r.Add(path); err != nil {
 			return gitResultMsg{Cmd: cmd, Err: err}
 		}
@@ -278,7 +378,7 @@ func stageFileCmd(r git.Runner, path string) tea.Cmd {
 // Unstage a specific file
 func unstageFileCmd(r git.Runner, path string) tea.Cmd {
 	return func() tea.Msg {
-		cmd := fmt.Sprintf("git restore --staged -- %s", path)
+		cmd := fmt.Sprintf("git --literal-pathspecs restore --staged -- %s", path)
 		if err := r.RestoreStaged(path); err != nil {
 			return gitResultMsg{Cmd: cmd, Err: err}
 		}
@@ -286,6 +386,65 @@ func unstageFileCmd(r git.Runner, path string) tea.Cmd {
 	}
 }
 
+// stageFilesCmd stage nhiều file (vùng chọn trong Files pane)
+func stageFilesCmd(r git.Runner, label string, paths []string) tea.Cmd {
+	return func() tea.Msg {
+		cmd := "git --literal-pathspecs add -- " + strings.Join(paths, " ")
+		if err := r.Add(paths...); err != nil {
+			return gitResultMsg{Cmd: cmd, Err: err}
+		}
+		return gitResultMsg{Cmd: cmd, Result: "staged " + label}
+	}
+}
+
+// unstageFilesCmd unstage nhiều file (vùng chọn trong Files pane)
+func unstageFilesCmd(r git.Runner, label string, paths []string) tea.Cmd {
+	return func() tea.Msg {
+		cmd := "git --literal-pathspecs restore --staged -- " + strings.Join(paths, " ")
+		if err := r.RestoreStaged(paths...); err != nil {
+			return gitResultMsg{Cmd: cmd, Err: err}
+		}
+		return gitResultMsg{Cmd: cmd, Result: "unstaged " + label}
+	}
+}
+
+// stashFilesCmd stash thay đổi của các file được chọn, kể cả untracked
+func stashFilesCmd(r git.Runner, message, label string, paths []string) tea.Cmd {
+	return func() tea.Msg {
+		cmd := "git --literal-pathspecs stash push --include-untracked"
+		if message != "" {
+			cmd += fmt.Sprintf(" -m %q", message)
+		}
+		cmd += " -- " + strings.Join(paths, " ")
+		if err := r.StashPush(message, paths...); err != nil {
+			return gitResultMsg{Cmd: cmd, Err: err}
+		}
+		return gitResultMsg{Cmd: cmd, Result: "stashed " + label}
+	}
+}
+
+// ignoreFilesCmd thêm các file được chọn vào .gitignore; untrack là các path đang
+// được track, bỏ khỏi index trước (git rm --cached) để việc ignore có tác dụng
+func ignoreFilesCmd(r git.Runner, label string, paths, untrack []string) tea.Cmd {
+	return func() tea.Msg {
+		cmd := "add to .gitignore: " + strings.Join(paths, " ")
+		if len(untrack) > 0 {
+			cmd = "git --literal-pathspecs rm -r --cached -- " + strings.Join(untrack, " ") + " && " + cmd
+			if err := r.Untrack(untrack...); err != nil {
+				return gitResultMsg{Cmd: cmd, Err: err}
+			}
+		}
+		added, err := r.Ignore(paths...)
+		if err != nil {
+			return gitResultMsg{Cmd: cmd, Err: err}
+		}
+		if len(added) == 0 {
+			return gitResultMsg{Cmd: cmd, Result: label + " already in .gitignore"}
+		}
+		return gitResultMsg{Cmd: cmd, Result: "ignored " + strings.Join(added, " ")}
+	}
+}
+
 func stageAllCmd(r git.Runner) tea.Cmd {
 	return func() tea.Msg {
 		cmd := "git add ."
@@ -296,15 +455,19 @@ func stageAllCmd(r git.Runner) tea.Cmd {
 	}
 }
 
-func commitCmd(r git.Runner, message string) tea.Cmd {
-	return func() tea.Msg {
-		cmd := fmt.Sprintf("git commit -m %q", message)
-		_, err := r.Commit(message)
+func commitCmd(r git.Runner, message string, noVerify bool) tea.Cmd {
+	cmd := fmt.Sprintf("git commit -m %q", message)
+	if noVerify {
+		cmd += " --no-verify"
+	}
+	return streamCmd(cmd, func() (*git.Stream, error) {
+		return r.StreamCommit(message, false, noVerify)
+	}, func(_ string, err error) tea.Msg {
 		if err != nil {
-			return gitResultMsg{Cmd: cmd, Err: err}
+			return gitResultMsg{Err: commitError(r, err)}
 		}
-		return gitResultMsg{Cmd: cmd, Result: "committed"}
-	}
+		return gitResultMsg{Result: "committed"}
+	})
 }
 
 func loadBranchCmd(r git.Runner) tea.Cmd {
@@ -313,7 +476,12 @@ func loadBranchCmd(r git.Runner) tea.Cmd {
 		if err != nil {
 			return branchLoadedMsg{Branch: ""}
 		}
-		return branchLoadedMsg{Branch: strings.TrimSpace(out)}
+		branch := strings.TrimSpace(out)
+		if branch == "HEAD" {
+			hash, _ := r.HeadShortHash()
+			return branchLoadedMsg{Branch: branch, DetachedHash: hash}
+		}
+		return branchLoadedMsg{Branch: branch}
 	}
 }
 
@@ -337,101 +505,270 @@ func loadStashCmd(r git.Runner) tea.Cmd {
 	}
 }
 
-func loadStashDiffCmd(r git.Runner, ref string) tea.Cmd {
+func loadStashDiffCmd(r git.Runner, ref string, opts git.DiffOptions) tea.Cmd {
 	return func() tea.Msg {
 		if strings.TrimSpace(ref) == "" {
 			return diffLoadedMsg{Diff: "", Context: diffContextNone}
 		}
-		out, err := r.ShowStash(ref)
-		if err != nil {
-			return errMsg(err.Error())
-		}
-		return diffLoadedMsg{Diff: out, Context: diffContextStash, Subtitle: ref}
+		start := func() (*git.DiffBuffer, error) { return r.StreamShowStash(ref, opts) }
+		return streamDiff(r, start, diffContextStash, ref, func(out string, err error) tea.Msg {
+			if err != nil {
+				return errMsg(err.Error())
+			}
+			return diffLoadedMsg{Diff: out, Context: diffContextStash, Subtitle: ref}
+		})
 	}
 }
 
-func loadBranchDiffCmd(r git.Runner, branch string) tea.Cmd {
+func loadBranchDiffCmd(r git.Runner, branch string, opts git.DiffOptions) tea.Cmd {
 	return func() tea.Msg {
 		if strings.TrimSpace(branch) == "" {
 			return diffLoadedMsg{Diff: "", Context: diffContextNone}
 		}
-		out, err := r.DiffBranch(branch)
-		if err != nil {
+		start := func() (*git.DiffBuffer, error) { return r.StreamDiffBranch(branch, opts) }
+		return streamDiff(r, start, diffContextBranch, branch, func(out string, err error) tea.Msg {
 			// May fail for current branch, show empty
-			return diffLoadedMsg{Diff: "(no diff from current branch)", Context: diffContextBranch, Subtitle: branch}
+			if err != nil || strings.TrimSpace(out) == "" {
+				out = "(no diff from current branch)"
+			}
+			return diffLoadedMsg{Diff: out, Context: diffContextBranch, Subtitle: branch}
+		})
+	}
+}
+
+// ========== HIGH PRIORITY COMMANDS ==========
+
+// discardFilesCmd discard thay đổi unstaged của nhiều file (e.g. cả một thư mục
+// trong tree view): file tracked được checkout lại, file untracked bị xoá
+func discardFilesCmd(r git.Runner, label string, files []git.FileItem) tea.Cmd {
+	return func() tea.Msg {
+		var tracked, untracked []string
+		for _, f := range files {
+			if f.Status == "?" {
+				untracked = append(untracked, f.Path)
+			} else {
+				tracked = append(tracked, f.Path)
+			}
+		}
+		var cmds []string
+		if len(tracked) > 0 {
+			cmds = append(cmds, "git --literal-pathspecs checkout -- "+strings.Join(tracked, " "))
+			if err := r.DiscardFile(tracked...); err != nil {
+				return gitResultMsg{Cmd: strings.Join(cmds, "; "), Err: err}
+			}
 		}
-		if strings.TrimSpace(out) == "" {
-			out = "(no diff from current branch)"
+		if len(untracked) > 0 {
+			cmds = append(cmds, "git --literal-pathspecs clean -fd -- "+strings.Join(untracked, " "))
+			if err := r.DiscardUntracked(untracked...); err != nil {
+				return gitResultMsg{Cmd: strings.Join(cmds, "; "), Err: err}
+			}
 		}
-		return diffLoadedMsg{Diff: out, Context: diffContextBranch, Subtitle: branch}
+		return gitResultMsg{Cmd: strings.Join(cmds, "; "), Result: "discarded " + label}
 	}
 }
 
-// ========== HIGH PRIORITY COMMANDS ==========
+// discardAllChangesCmd bỏ cả thay đổi staged và unstaged của các file
+func discardAllChangesCmd(r git.Runner, label string, plan git.DiscardPlan) tea.Cmd {
+	return func() tea.Msg {
+		var cmds []string
+		if len(plan.Restore) > 0 {
+			cmds = append(cmds, "git --literal-pathspecs restore --source=HEAD --staged --worktree -- "+strings.Join(plan.Restore, " "))
+		}
+		if len(plan.Remove) > 0 {
+			cmds = append(cmds, "git --literal-pathspecs rm -f -- "+strings.Join(plan.Remove, " "))
+		}
+		if len(plan.Clean) > 0 {
+			cmds = append(cmds, "git --literal-pathspecs clean -fd -- "+strings.Join(plan.Clean, " "))
+		}
+		cmd := strings.Join(cmds, "; ")
+		if err := r.DiscardAllChanges(plan); err != nil {
+			return gitResultMsg{Cmd: cmd, Err: err}
+		}
+		return gitResultMsg{Cmd: cmd, Result: "discarded all changes to " + label}
+	}
+}
 
-// Discard changes in a file
-func discardFileCmd(r git.Runner, path string, isUntracked bool) tea.Cmd {
+// resetHardCmd bỏ mọi thay đổi của file tracked, clean thêm file untracked khi withClean
+func resetHardCmd(r git.Runner, withClean bool) tea.Cmd {
 	return func() tea.Msg {
-		var cmd string
-		var err error
-		if isUntracked {
-			cmd = fmt.Sprintf("git clean -f -- %s", path)
-			err = r.DiscardUntracked(path)
-		} else {
-			cmd = fmt.Sprintf("git checkout -- %s", path)
-			err = r.DiscardFile(path)
+		cmd := "git reset --hard HEAD"
+		if err := r.ResetHard(); err != nil {
+			return gitResultMsg{Cmd: cmd, Err: err}
 		}
-		if err != nil {
+		if !withClean {
+			return gitResultMsg{Cmd: cmd, Result: "Reset hard to HEAD"}
+		}
+		cmd += "; git clean -fd"
+		if err := r.CleanUntracked(); err != nil {
 			return gitResultMsg{Cmd: cmd, Err: err}
 		}
-		return gitResultMsg{Cmd: cmd, Result: "discarded " + path}
+		return gitResultMsg{Cmd: cmd, Result: "Discarded all changes"}
 	}
 }
 
-// Pull from remote
-func pullCmd(r git.Runner) tea.Cmd {
+// cleanPreviewMsg chứa danh sách file untracked sẽ bị git clean xoá
+type cleanPreviewMsg struct {
+	Paths []string
+}
+
+// cleanPreviewCmd chạy git clean -nd để xem trước những gì sẽ bị xoá
+func cleanPreviewCmd(r git.Runner) tea.Cmd {
 	return func() tea.Msg {
-		cmd := "git pull"
-		out, err := r.Pull()
+		paths, err := r.CleanPreview()
 		if err != nil {
-			return gitResultMsg{Cmd: cmd, Err: err}
+			return errMsg(err.Error())
 		}
-		if strings.Contains(out, "Already up to date") {
-			return gitResultMsg{Cmd: cmd, Result: "Already up to date"}
+		return cleanPreviewMsg{Paths: paths}
+	}
+}
+
+// cleanUntrackedCmd xoá đúng các file/thư mục untracked đã xem trước bằng git clean -n
+func cleanUntrackedCmd(r git.Runner, paths []string) tea.Cmd {
+	return func() tea.Msg {
+		cmd := "git --literal-pathspecs clean -fd -- " + strings.Join(paths, " ")
+		if err := r.CleanUntracked(paths...); err != nil {
+			return gitResultMsg{Cmd: cmd, Err: err}
 		}
-		return gitResultMsg{Cmd: cmd, Result: "Pulled successfully"}
+		return gitResultMsg{Cmd: cmd, Result: fmt.Sprintf("Removed %d untracked files/directories", len(paths))}
 	}
 }
 
-// Push to remote
-func pushCmd(r git.Runner) tea.Cmd {
+// pullPreviewLoadedMsg chứa các commit sẽ được pull về
+type pullPreviewLoadedMsg struct {
+	Preview git.PullPreview
+}
+
+// pullDivergedMsg báo pull thất bại vì branch đã phân nhánh, cần hỏi cách reconcile
+type pullDivergedMsg struct {
+	Autostash bool
+}
+
+// Fetch upstream and list incoming commits before pulling
+func loadPullPreviewCmd(r git.Runner) tea.Cmd {
 	return func() tea.Msg {
-		// Check if upstream exists
-		if !r.HasUpstream() {
-			// Get current branch and remote
-			branch, err := r.CurrentBranch()
-			if err != nil {
-				return gitResultMsg{Cmd: "git push", Err: err}
-			}
-			branch = strings.TrimSpace(branch)
-			remote, err := r.GetRemote()
-			if err != nil {
-				return gitResultMsg{Cmd: "git push", Err: fmt.Errorf("No remote configured")}
+		preview, err := r.PreviewPull(20)
+		if err != nil {
+			return errMsg(err.Error())
+		}
+		return pullPreviewLoadedMsg{Preview: preview}
+	}
+}
+
+// Pull from remote with an explicit strategy (merge, rebase, ff-only)
+func pullCmd(r git.Runner, strategy string, autostash bool) tea.Cmd {
+	cmd := "git " + strings.Join(git.PullArgs(strategy, autostash), " ")
+	return streamCmd(cmd, func() (*git.Stream, error) {
+		return r.StreamPull(strategy, autostash)
+	}, func(out string, err error) tea.Msg {
+		if err != nil {
+			if git.IsDivergentPull(err) {
+				return pullDivergedMsg{Autostash: autostash}
 			}
-			cmd := fmt.Sprintf("git push -u %s %s", remote, branch)
-			_, err = r.PushSetUpstream(remote, branch)
-			if err != nil {
-				return gitResultMsg{Cmd: cmd, Err: err}
+			return gitResultMsg{Err: err}
+		}
+		if strings.Contains(out, "Already up to date") {
+			return gitResultMsg{Result: "Already up to date"}
+		}
+		return gitResultMsg{Result: "Pulled successfully (" + strategy + ")"}
+	})
+}
+
+// pushDefaultsLoadedMsg chứa giá trị mặc định cho push modal (hoặc force push khi Force)
+type pushDefaultsLoadedMsg struct {
+	Remotes []string
+	Opts    git.PushOptions
+	Leases  map[string]string // "remote/branch" → SHA của remote-tracking ref
+	Force   bool
+}
+
+// pushRejectedMsg báo push bị từ chối vì non-fast-forward, đề nghị force-with-lease
+type pushRejectedMsg struct {
+	Opts git.PushOptions
+}
+
+// Load remotes, upstream and remote-tracking SHAs for the push modal
+func loadPushDefaultsCmd(r git.Runner, force bool) tea.Cmd {
+	return func() tea.Msg {
+		branch, err := r.CurrentBranch()
+		if err != nil {
+			return errMsg(err.Error())
+		}
+		branch = strings.TrimSpace(branch)
+		if branch == "HEAD" {
+			return errMsg("Cannot push a detached HEAD, create a branch first")
+		}
+		remotes, err := r.ListRemotes()
+		if err != nil || len(remotes) == 0 {
+			return errMsg("No remote configured")
+		}
+
+		opts := git.PushOptions{Remote: remotes[0], LocalBranch: branch, RemoteBranch: branch}
+		if remote, mergeRef, err := r.BranchUpstreamRef(branch); err == nil {
+			opts.Remote = remote
+			opts.RemoteBranch = strings.TrimPrefix(mergeRef, "refs/heads/")
+		} else {
+			opts.SetUpstream = true
+		}
+
+		leases := make(map[string]string)
+		for _, remote := range remotes {
+			if hash, err := r.RemoteTrackingHash(remote, opts.RemoteBranch); err == nil {
+				leases[remote+"/"+opts.RemoteBranch] = hash
 			}
-			return gitResultMsg{Cmd: cmd, Result: "Pushed (set upstream " + remote + "/" + branch + ")"}
 		}
-		cmd := "git push"
-		_, err := r.Push()
+		if force {
+			opts.ForceWithLease = true
+			opts.LeaseHash = leases[opts.Remote+"/"+opts.RemoteBranch]
+		}
+		return pushDefaultsLoadedMsg{Remotes: remotes, Opts: opts, Leases: leases, Force: force}
+	}
+}
+
+// Push to remote
+func pushCmd(r git.Runner, opts git.PushOptions) tea.Cmd {
+	cmd := "git " + strings.Join(git.PushArgs(opts), " ")
+	return streamCmd(cmd, func() (*git.Stream, error) {
+		return r.StreamPush(opts)
+	}, func(_ string, err error) tea.Msg {
 		if err != nil {
-			return gitResultMsg{Cmd: cmd, Err: err}
+			if git.IsPushRejected(err) && !opts.ForceWithLease && !opts.DryRun {
+				return pushRejectedMsg{Opts: opts}
+			}
+			return gitResultMsg{Err: err}
 		}
-		return gitResultMsg{Cmd: cmd, Result: "Pushed successfully"}
+		switch {
+		case opts.DryRun:
+			return gitResultMsg{Result: "Dry run finished (see command log)"}
+		case opts.ForceWithLease:
+			return gitResultMsg{Result: "Force pushed " + opts.Remote + "/" + opts.RemoteBranch}
+		case opts.SetUpstream:
+			return gitResultMsg{Result: "Pushed (set upstream " + opts.Remote + "/" + opts.RemoteBranch + ")"}
+		}
+		return gitResultMsg{Result: "Pushed successfully"}
+	})
+}
+
+// forcePushPromptMsg mang options force push (đã có LeaseHash) để hỏi xác nhận
+type forcePushPromptMsg struct {
+	Opts git.PushOptions
+}
+
+// forcePushCmdPrompt tra SHA remote-tracking rồi hỏi xác nhận force push
+func forcePushCmdPrompt(r git.Runner, opts git.PushOptions) tea.Cmd {
+	return func() tea.Msg {
+		opts.ForceWithLease = true
+		opts.LeaseHash, _ = r.RemoteTrackingHash(opts.Remote, opts.RemoteBranch)
+		return forcePushPromptMsg{Opts: opts}
+	}
+}
+
+// forcePushPrompt mô tả force push kèm SHA remote mong đợi
+func forcePushPrompt(opts git.PushOptions) string {
+	target := opts.Remote + "/" + opts.RemoteBranch
+	if opts.LeaseHash == "" {
+		return fmt.Sprintf("Force push %s to %s with --force-with-lease? No remote-tracking ref, git will refuse if %s exists.", opts.LocalBranch, target, target)
 	}
+	return fmt.Sprintf("Force push %s to %s with --force-with-lease? Expected remote SHA %s, push fails if the remote moved since.", opts.LocalBranch, target, shortHash(opts.LeaseHash))
 }
 
 // Checkout branch
@@ -446,6 +783,33 @@ func checkoutBranchCmd(r git.Runner, branch string) tea.Cmd {
 	}
 }
 
+// Checkout a commit as detached HEAD
+func checkoutCommitCmd(r git.Runner, hash string) tea.Cmd {
+	return func() tea.Msg {
+		cmd := fmt.Sprintf("git checkout --detach %s", hash)
+		_, err := r.CheckoutCommit(hash)
+		if err != nil {
+			return gitResultMsg{Cmd: cmd, Err: err}
+		}
+		return gitResultMsg{Cmd: cmd, Result: "HEAD detached at " + hash}
+	}
+}
+
+// Return from detached HEAD to the previously checked out branch
+func checkoutPreviousBranchCmd(r git.Runner) tea.Cmd {
+	return func() tea.Msg {
+		branch, err := r.PreviousBranch()
+		if err != nil {
+			return gitResultMsg{Cmd: "git checkout @{-1}", Err: err}
+		}
+		cmd := fmt.Sprintf("git checkout %s", branch)
+		if _, err := r.CheckoutBranch(branch); err != nil {
+			return gitResultMsg{Cmd: cmd, Err: err}
+		}
+		return gitResultMsg{Cmd: cmd, Result: "Switched back to " + branch}
+	}
+}
+
 // Create new branch
 func createBranchCmd(r git.Runner, name string) tea.Cmd {
 	return func() tea.Msg {
@@ -460,18 +824,275 @@ func createBranchCmd(r git.Runner, name string) tea.Cmd {
 
 // ========== MEDIUM PRIORITY COMMANDS ==========
 
-// Fetch from remote
-func fetchCmd(r git.Runner) tea.Cmd {
+// repoStateCmd chạy continue/abort/skip cho merge/rebase/cherry-pick/revert/bisect/am
+func repoStateCmd(r git.Runner, args []string, result string) tea.Cmd {
+	cmd := "git " + strings.Join(args, " ")
+	return streamCmd(cmd, func() (*git.Stream, error) {
+		return r.StreamRepoStateAction(args)
+	}, func(_ string, err error) tea.Msg {
+		if err != nil {
+			return gitResultMsg{Err: err}
+		}
+		return gitResultMsg{Result: result}
+	})
+}
+
+// exportPatchesCmd xuất commit (hoặc commit..HEAD) thành các file .patch trong dir
+func exportPatchesCmd(r git.Runner, hash string, toHead bool, dir string) tea.Cmd {
+	return func() tea.Msg {
+		revs := git.PatchRevs(hash, toHead, r.HasParent(hash))
+		outDir := git.ResolvePatchPath(r.RepoRoot, dir)
+		cmd := "git format-patch -o " + outDir + " " + strings.Join(revs, " ")
+		files, err := r.FormatPatch(revs, outDir)
+		if err != nil {
+			return gitResultMsg{Cmd: cmd, Err: err}
+		}
+		return gitResultMsg{Cmd: cmd, Result: fmt.Sprintf("Exported %d patch(es) to %s", len(files), outDir)}
+	}
+}
+
+// exportMboxCmd xuất commit (hoặc commit..HEAD) thành một file mbox
+func exportMboxCmd(r git.Runner, hash string, toHead bool, path string) tea.Cmd {
+	return func() tea.Msg {
+		revs := git.PatchRevs(hash, toHead, r.HasParent(hash))
+		out := git.ResolvePatchPath(r.RepoRoot, path)
+		cmd := "git format-patch --stdout " + strings.Join(revs, " ") + " > " + out
+		n, err := r.FormatPatchMbox(revs, out)
+		if err != nil {
+			return gitResultMsg{Cmd: cmd, Err: err}
+		}
+		return gitResultMsg{Cmd: cmd, Result: fmt.Sprintf("Exported %d patch(es) to %s", n, out)}
+	}
+}
+
+// amCmd áp dụng patch thành commit bằng git am. Khi conflict, am dừng lại và
+// banner trạng thái cho phép continue/skip/abort.
+func amCmd(r git.Runner, path string, threeWay bool) tea.Cmd {
+	files, err := git.PatchFiles(git.ResolvePatchPath(r.RepoRoot, path))
+	if err != nil {
+		return func() tea.Msg { return gitResultMsg{Err: err} }
+	}
+	cmd := "git " + strings.Join(git.AmArgs(files, threeWay), " ")
+	return streamCmd(cmd, func() (*git.Stream, error) {
+		return r.StreamAm(files, threeWay)
+	}, func(_ string, err error) tea.Msg {
+		if err != nil {
+			return gitResultMsg{Err: fmt.Errorf("%w\n\nResolve conflicts then press C to continue, S to skip this patch or X to abort", err)}
+		}
+		return gitResultMsg{Result: fmt.Sprintf("Applied %d patch file(s) with git am", len(files))}
+	})
+}
+
+// applyPatchCmd áp dụng patch vào working tree (và index nếu index=true) bằng git apply
+func applyPatchCmd(r git.Runner, path string, index, threeWay bool) tea.Cmd {
+	return func() tea.Msg {
+		files, err := git.PatchFiles(git.ResolvePatchPath(r.RepoRoot, path))
+		if err != nil {
+			return gitResultMsg{Err: err}
+		}
+		cmd := "git " + strings.Join(git.ApplyArgs(files, index, threeWay), " ")
+		if err := r.ApplyPatchFiles(files, index, threeWay); err != nil {
+			return gitResultMsg{Cmd: cmd, Err: err}
+		}
+		return gitResultMsg{Cmd: cmd, Result: fmt.Sprintf("Applied %d patch file(s)", len(files))}
+	}
+}
+
+// commitPatchLoadedMsg chứa diff của commit để chọn vào custom patch
+type commitPatchLoadedMsg struct {
+	Hash  string
+	Title string
+	Age   int // vị trí trong commits pane, lớn hơn = cũ hơn
+	Diff  string
+}
+
+// loadCommitPatchCmd load diff của commit cho patch builder
+func loadCommitPatchCmd(r git.Runner, commit git.CommitItem, age int) tea.Cmd {
+	return func() tea.Msg {
+		out, err := r.CommitDiff(commit.Hash)
+		if err != nil {
+			return errMsg(err.Error())
+		}
+		return commitPatchLoadedMsg{Hash: commit.Hash, Title: commit.Hash + " " + commit.Message, Age: age, Diff: out}
+	}
+}
+
+// compareMarkMsg đánh dấu ref làm phía "from" để so sánh
+type compareMarkMsg struct {
+	Ref string
+}
+
+// compareOpenMsg mở compare view cho from..to
+type compareOpenMsg struct {
+	From, To string
+}
+
+// compareLoadedMsg chứa kết quả so sánh hai ref
+type compareLoadedMsg struct {
+	Comparison git.Comparison
+}
+
+// compareFileDiffLoadedMsg chứa diff của một file giữa hai ref
+type compareFileDiffLoadedMsg struct {
+	Path string
+	Diff string
+}
+
+// loadCompareCmd so sánh hai ref: danh sách file và commit giữa chúng
+func loadCompareCmd(r git.Runner, from, to string, opts git.DiffOptions) tea.Cmd {
+	return func() tea.Msg {
+		c, err := r.CompareRefs(from, to, opts)
+		if err != nil {
+			return errMsg(err.Error())
+		}
+		return compareLoadedMsg{Comparison: c}
+	}
+}
+
+// loadCompareFileDiffCmd load diff của file giữa hai ref
+func loadCompareFileDiffCmd(r git.Runner, from, to string, file git.CompareFile, opts git.DiffOptions) tea.Cmd {
 	return func() tea.Msg {
-		cmd := "git fetch --all --prune"
-		_, err := r.Fetch()
+		out, err := r.DiffRefsFile(from, to, file, opts)
 		if err != nil {
+			return errMsg(err.Error())
+		}
+		out = r.SummarizeBinaryDiff(out, false, opts.SmudgeLFS)
+		return compareFileDiffLoadedMsg{Path: file.Path, Diff: out}
+	}
+}
+
+// exitPatchViewMsg yêu cầu rời patch builder (sau khi commit gốc bị viết lại)
+type exitPatchViewMsg struct{}
+
+func exitPatchViewCmd() tea.Cmd {
+	return func() tea.Msg { return exitPatchViewMsg{} }
+}
+
+// patchRewrittenMsg là kết quả của lệnh sửa lại commit theo custom patch. Custom
+// patch chỉ bị xoá khi lịch sử đã được sửa, lỗi trước đó giữ nguyên vùng chọn để thử lại.
+type patchRewrittenMsg struct {
+	Msg tea.Msg
+}
+
+// patchRewriteCmd bọc lệnh (kể cả các stream nối tiếp) để kết quả là patchRewrittenMsg
+func patchRewriteCmd(cmd tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		return afterStream(cmd(), func(msg tea.Msg) tea.Msg {
+			return patchRewrittenMsg{Msg: msg}
+		})
+	}
+}
+
+// applyCustomPatchCmd apply custom patch vào working tree, hoặc chỉ index khi cached
+func applyCustomPatchCmd(r git.Runner, patch string, cached, reverse bool) tea.Cmd {
+	return func() tea.Msg {
+		cmd := "git apply"
+		if cached {
+			cmd += " --cached"
+		}
+		if reverse {
+			cmd += " -R"
+		}
+		if err := r.ApplyCustomPatch(patch, cached, reverse); err != nil {
 			return gitResultMsg{Cmd: cmd, Err: err}
 		}
-		return gitResultMsg{Cmd: cmd, Result: "Fetched all remotes"}
+		result := "Applied custom patch"
+		switch {
+		case cached:
+			result = "Staged custom patch"
+		case reverse:
+			result = "Reverted custom patch in working tree"
+		}
+		return gitResultMsg{Cmd: cmd, Result: result}
+	}
+}
+
+// removePatchFromCommitCmd gỡ custom patch khỏi commit, các thay đổi bị gỡ còn lại trong working tree
+func removePatchFromCommitCmd(r git.Runner, hash, patch string, noVerify bool) tea.Cmd {
+	return removePatchStreamCmd(r, hash, patch, noVerify, func() tea.Msg {
+		return gitResultMsg{Result: "Removed patch from " + hash + ", changes left in working tree"}
+	})
+}
+
+// movePatchToNewCommitCmd gỡ custom patch khỏi commit và commit nó riêng trên HEAD
+func movePatchToNewCommitCmd(r git.Runner, hash, patch, message string, noVerify bool) tea.Cmd {
+	cmd := fmt.Sprintf("git apply --cached && git commit -m %q", message)
+	if noVerify {
+		cmd += " --no-verify"
+	}
+	return removePatchStreamCmd(r, hash, patch, noVerify, streamCmd(cmd, func() (*git.Stream, error) {
+		s, err := r.StartCommitMovedPatch(hash, patch, message, noVerify)
+		if err != nil {
+			return nil, commitError(r, err)
+		}
+		return s, nil
+	}, func(_ string, err error) tea.Msg {
+		if err != nil {
+			return gitResultMsg{Err: &git.PatchMovedError{Hash: hash, Err: commitError(r, err)}}
+		}
+		return gitResultMsg{Result: "Moved patch from " + hash + " into a new commit"}
+	}))
+}
+
+// removePatchStreamCmd gỡ patch khỏi index rồi commit sửa lại hash (amend, hoặc fixup!
+// commit rồi autosquash); mỗi bước chạy dạng stream để thấy output hook và huỷ được
+// bằng ctrl+x. Bước nào thất bại thì index được trả về như cũ; xong thì chạy then.
+func removePatchStreamCmd(r git.Runner, hash, patch string, noVerify bool, then tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		s, autosquash, err := r.StartRemovePatchFromCommit(hash, patch, noVerify)
+		if err != nil {
+			return gitResultMsg{Err: commitError(r, err)}
+		}
+		cmd := "git apply --cached -R && git commit --amend --no-edit"
+		if autosquash {
+			cmd = "git apply --cached -R && git commit --fixup=" + hash
+		}
+		if noVerify {
+			cmd += " --no-verify"
+		}
+		finish := func(err error) tea.Msg {
+			if err := r.FinishRemovePatchFromCommit(patch, err); err != nil {
+				return gitResultMsg{Err: commitError(r, err)}
+			}
+			return then()
+		}
+		return streamStartedMsg{
+			Stream: s,
+			Cmd:    cmd,
+			Finish: func() tea.Msg {
+				_, err := s.Wait()
+				if err != nil || !autosquash {
+					return finish(err)
+				}
+				return autosquashStreamCmd(r, hash, func(err error) tea.Msg {
+					return finish(r.FinishAmendCommitWithStaged(err))
+				})()
+			},
+		}
 	}
 }
 
+// saveCustomPatchCmd ghi custom patch ra file
+func saveCustomPatchCmd(r git.Runner, patch, path string) tea.Cmd {
+	return func() tea.Msg {
+		out := git.ResolvePatchPath(r.RepoRoot, path)
+		if err := git.SaveCustomPatch(out, patch); err != nil {
+			return gitResultMsg{Err: err}
+		}
+		return gitResultMsg{Cmd: "save patch " + out, Result: "Saved custom patch to " + out}
+	}
+}
+
+// Fetch from remote, progress được stream lên status pane
+func fetchCmd(r git.Runner) tea.Cmd {
+	return streamCmd("git fetch --all --prune --progress", r.StreamFetch, func(_ string, err error) tea.Msg {
+		if err != nil {
+			return gitResultMsg{Err: err}
+		}
+		return gitResultMsg{Result: "Fetched all remotes"}
+	})
+}
+
 // Delete branch
 func deleteBranchCmd(r git.Runner, name string, force bool) tea.Cmd {
 	return func() tea.Msg {
@@ -491,6 +1112,79 @@ func deleteBranchCmd(r git.Runner, name string, force bool) tea.Cmd {
 	}
 }
 
+// Rename branch locally, and optionally on its remote
+func renameBranchCmd(r git.Runner, oldName, newName string, renameRemote bool) tea.Cmd {
+	return func() tea.Msg {
+		cmd := fmt.Sprintf("git branch -m %s %s", oldName, newName)
+		var remote, mergeRef string
+		if renameRemote {
+			var err error
+			remote, mergeRef, err = r.BranchUpstreamRef(oldName)
+			if err != nil {
+				return gitResultMsg{Cmd: cmd, Err: err}
+			}
+		}
+		if err := r.RenameBranch(oldName, newName); err != nil {
+			return gitResultMsg{Cmd: cmd, Err: err}
+		}
+		if !renameRemote {
+			return gitResultMsg{Cmd: cmd, Result: "Renamed branch to " + newName}
+		}
+
+		oldRemoteBranch := strings.TrimPrefix(mergeRef, "refs/heads/")
+		cmd = fmt.Sprintf("git push -u %s %s && git push %s --delete %s", remote, newName, remote, oldRemoteBranch)
+		if err := r.RenameRemoteBranch(remote, oldRemoteBranch, newName); err != nil {
+			return gitResultMsg{Cmd: cmd, Err: err}
+		}
+		return gitResultMsg{Cmd: cmd, Result: "Renamed branch to " + newName + " (local and " + remote + ")"}
+	}
+}
+
+// Set upstream of a branch
+func setUpstreamCmd(r git.Runner, branch, upstream string) tea.Cmd {
+	return func() tea.Msg {
+		cmd := fmt.Sprintf("git branch --set-upstream-to=%s %s", upstream, branch)
+		if err := r.SetUpstream(branch, upstream); err != nil {
+			return gitResultMsg{Cmd: cmd, Err: err}
+		}
+		return gitResultMsg{Cmd: cmd, Result: branch + " now tracks " + upstream}
+	}
+}
+
+// Unset upstream of a branch
+func unsetUpstreamCmd(r git.Runner, branch string) tea.Cmd {
+	return func() tea.Msg {
+		cmd := fmt.Sprintf("git branch --unset-upstream %s", branch)
+		if err := r.UnsetUpstream(branch); err != nil {
+			return gitResultMsg{Cmd: cmd, Err: err}
+		}
+		return gitResultMsg{Cmd: cmd, Result: "Unset upstream of " + branch}
+	}
+}
+
+// Fast-forward a branch to its upstream without checking it out
+func fastForwardBranchCmd(r git.Runner, branch git.Branch) tea.Cmd {
+	return func() tea.Msg {
+		if branch.IsCurrent {
+			cmd := "git pull --ff-only"
+			if _, err := r.PullFastForwardOnly(); err != nil {
+				return gitResultMsg{Cmd: cmd, Err: err}
+			}
+			return gitResultMsg{Cmd: cmd, Result: "Fast-forwarded " + branch.Name}
+		}
+
+		remote, mergeRef, err := r.BranchUpstreamRef(branch.Name)
+		if err != nil {
+			return gitResultMsg{Cmd: "git fetch", Err: err}
+		}
+		cmd := fmt.Sprintf("git fetch %s %s:refs/heads/%s", remote, mergeRef, branch.Name)
+		if _, err := r.FastForwardBranch(branch.Name); err != nil {
+			return gitResultMsg{Cmd: cmd, Err: err}
+		}
+		return gitResultMsg{Cmd: cmd, Result: "Fast-forwarded " + branch.Name + " to " + branch.Upstream}
+	}
+}
+
 // Stash apply
 func stashApplyCmd(r git.Runner, ref string) tea.Cmd {
 	return func() tea.Msg {
@@ -524,23 +1218,210 @@ func stashDropCmd(r git.Runner, ref string) tea.Cmd {
 	}
 }
 
-// Amend commit
-func commitAmendCmd(r git.Runner, message string) tea.Cmd {
+// commitDraftLoadedMsg chứa nội dung điền sẵn cho commit modal
+type commitDraftLoadedMsg struct {
+	Subject string
+	Body    string
+	History []string
+	Signing string
+}
+
+// commitEditorReadyMsg báo đã ghi message ra file, sẵn sàng mở editor ngoài
+type commitEditorReadyMsg struct {
+	Editor string
+	Path   string
+}
+
+// commitEditorDoneMsg mang message đọc lại sau khi editor ngoài đóng
+type commitEditorDoneMsg struct {
+	Message string
+	Err     error
+}
+
+// Load commit template, ticket ID from branch name and recent messages for the commit modal
+func loadCommitDraftCmd(r git.Runner, amend bool, session []string) tea.Cmd {
 	return func() tea.Msg {
-		var cmd string
-		if message == "" {
-			cmd = "git commit --amend --no-edit"
-		} else {
-			cmd = fmt.Sprintf("git commit --amend -m %q", message)
+		var draft commitDraftLoadedMsg
+
+		recent, _ := r.RecentCommitMessages(20)
+		draft.History = mergeMessageHistory(session, recent)
+		if cfg := r.SigningConfig(); cfg.Enabled {
+			draft.Signing = "signed: " + cfg.Format
+		}
+
+		if amend {
+			if msg, err := r.HeadCommitMessage(); err == nil {
+				draft.Subject, draft.Body = git.SplitCommitMessage(msg)
+			}
+			return draft
+		}
+
+		if tmpl, err := r.CommitTemplate(); err == nil {
+			draft.Subject, draft.Body = git.SplitCommitMessage(tmpl)
 		}
-		_, err := r.CommitAmend(message)
+		branch, _ := r.CurrentBranch()
+		ticket := git.TicketFromBranch(strings.TrimSpace(branch))
+		if ticket != "" && !strings.Contains(draft.Subject+draft.Body, ticket) {
+			if strings.HasPrefix(ticket, "#") {
+				draft.Body = strings.TrimLeft(draft.Body+"\n\nRefs "+ticket, "\n")
+			} else {
+				draft.Subject = ticket + ": " + draft.Subject
+			}
+		}
+		return draft
+	}
+}
+
+// mergeMessageHistory ghép message trong phiên (mới nhất trước) với log, bỏ trùng
+func mergeMessageHistory(session, recent []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, list := range [][]string{session, recent} {
+		for _, msg := range list {
+			if msg == "" || seen[msg] {
+				continue
+			}
+			seen[msg] = true
+			out = append(out, msg)
+		}
+	}
+	return out
+}
+
+// Write the current message to a file under .git and resolve the editor git would use
+func prepareCommitEditorCmd(r git.Runner, message string) tea.Cmd {
+	return func() tea.Msg {
+		editor, err := r.Editor()
 		if err != nil {
-			return gitResultMsg{Cmd: cmd, Err: err}
+			return errMsg(err.Error())
+		}
+		path, err := r.CommitMessageFile()
+		if err != nil {
+			return errMsg(err.Error())
+		}
+		content := message + "\n\n# Write the subject on the first line, then a blank line and the body.\n# Lines starting with '#' will be ignored.\n"
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return errMsg(err.Error())
+		}
+		return commitEditorReadyMsg{Editor: editor, Path: path}
+	}
+}
+
+// Hand the terminal over to the external editor, then read the message back
+func runCommitEditorCmd(editor, path string) tea.Cmd {
+	return tea.ExecProcess(git.EditorCommand(editor, path), func(err error) tea.Msg {
+		if err != nil {
+			return commitEditorDoneMsg{Err: fmt.Errorf("editor %s: %w", editor, err)}
+		}
+		data, err := os.ReadFile(path)
+		_ = os.Remove(path)
+		if err != nil {
+			return commitEditorDoneMsg{Err: err}
+		}
+		return commitEditorDoneMsg{Message: git.StripCommentLines(string(data))}
+	})
+}
+
+// commitError làm rõ lỗi ký commit: giữ nguyên stderr của gpg/ssh-keygen thay vì chuỗi lỗi chung
+func commitError(r git.Runner, err error) error {
+	stderr, ok := git.SigningFailure(err)
+	if !ok {
+		return err
+	}
+	cfg := r.SigningConfig()
+	key := cfg.Key
+	if key == "" {
+		key = "default key"
+	}
+	return fmt.Errorf("commit signing failed (gpg.format=%s, %s)\n%s", cfg.Format, key, stderr)
+}
+
+// Amend commit
+func commitAmendCmd(r git.Runner, message string, noVerify bool) tea.Cmd {
+	var cmd string
+	if message == "" {
+		cmd = "git commit --amend --no-edit"
+	} else {
+		cmd = fmt.Sprintf("git commit --amend -m %q", message)
+	}
+	if noVerify {
+		cmd += " --no-verify"
+	}
+	return streamCmd(cmd, func() (*git.Stream, error) {
+		return r.StreamCommit(message, true, noVerify)
+	}, func(_ string, err error) tea.Msg {
+		if err != nil {
+			return gitResultMsg{Err: commitError(r, err)}
 		}
 		if message == "" {
-			return gitResultMsg{Cmd: cmd, Result: "Amended commit (kept message)"}
+			return gitResultMsg{Result: "Amended commit (kept message)"}
+		}
+		return gitResultMsg{Result: "Amended commit"}
+	})
+}
+
+// Create a fixup! commit for an older commit from staged changes
+func fixupCommitCmd(r git.Runner, hash string) tea.Cmd {
+	cmd := fmt.Sprintf("git commit --fixup=%s", hash)
+	return streamCmd(cmd, func() (*git.Stream, error) {
+		return r.StreamCommitFixup(hash, false)
+	}, func(_ string, err error) tea.Msg {
+		if err != nil {
+			return gitResultMsg{Err: commitError(r, err)}
+		}
+		return gitResultMsg{Result: "Created fixup! commit for " + hash}
+	})
+}
+
+// Amend staged changes into an older commit (fixup + autosquash). fixup! commit và
+// autosquash nối tiếp nhau dạng stream để thấy output hook và huỷ được bằng ctrl+x.
+func amendOlderCommitCmd(r git.Runner, hash string, noVerify bool) tea.Cmd {
+	cmd := fmt.Sprintf("git commit --fixup=%s", hash)
+	if noVerify {
+		cmd += " --no-verify"
+	}
+	return streamCmd(cmd, func() (*git.Stream, error) {
+		return r.StartAmendCommitWithStaged(hash, noVerify)
+	}, func(_ string, err error) tea.Msg {
+		if err != nil {
+			return gitResultMsg{Err: commitError(r, err)}
+		}
+		return autosquashStreamCmd(r, hash, func(err error) tea.Msg {
+			if err := r.FinishAmendCommitWithStaged(err); err != nil {
+				return gitResultMsg{Err: commitError(r, err)}
+			}
+			return gitResultMsg{Result: "Amended " + hash}
+		})()
+	})
+}
+
+// Squash pending fixup!/squash! commits with rebase --autosquash
+func autosquashCmd(r git.Runner, hash string) tea.Cmd {
+	return autosquashStreamCmd(r, hash, func(err error) tea.Msg {
+		if err != nil {
+			return gitResultMsg{Err: commitError(r, err)}
+		}
+		return gitResultMsg{Result: "Autosquashed onto " + hash}
+	})
+}
+
+// autosquashStreamCmd chạy rebase --autosquash dạng stream (không có timeout, huỷ được
+// bằng ctrl+x); done nhận lỗi của cả bước khởi chạy, rebase dở dang đã được abort
+func autosquashStreamCmd(r git.Runner, hash string, done func(err error) tea.Msg) tea.Cmd {
+	cmd := fmt.Sprintf("git rebase -i --autosquash %s~1", hash)
+	return func() tea.Msg {
+		s, err := r.StartRebaseAutosquash(hash)
+		if err != nil {
+			return done(err)
+		}
+		return streamStartedMsg{
+			Stream: s,
+			Cmd:    cmd,
+			Finish: func() tea.Msg {
+				_, err := s.Wait()
+				return done(r.FinishRebaseAutosquash(err))
+			},
 		}
-		return gitResultMsg{Cmd: cmd, Result: "Amended commit"}
 	}
 }
 
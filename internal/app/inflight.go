@@ -0,0 +1,174 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"gitzen/internal/git"
+)
+
+// inflightShowAfter: lệnh chạy lâu hơn ngưỡng này mới hiện trên info bar,
+// tránh nhấp nháy với các diff load nhanh
+const inflightShowAfter = 300 * time.Millisecond
+
+// inflightOp là một lệnh git đang chạy, huỷ được qua cancel
+type inflightOp struct {
+	id         int
+	key        string // cùng key thì lệnh mới thay thế lệnh cũ (e.g. "diff")
+	desc       string
+	started    time.Time
+	cancel     context.CancelFunc
+	superseded bool
+}
+
+// inflightTracker theo dõi các lệnh git đang chạy. Dùng pointer + mutex vì
+// model là value type còn tea.Cmd chạy trên goroutine riêng.
+type inflightTracker struct {
+	mu      sync.Mutex
+	nextID  int
+	ops     []*inflightOp
+	ticking bool // đang có inflightTickMsg chờ để render lại info bar
+}
+
+func newInflightTracker() *inflightTracker {
+	return &inflightTracker{}
+}
+
+// begin đăng ký lệnh mới, huỷ lệnh đang chạy cùng key (đã bị thay thế)
+func (t *inflightTracker) begin(key, desc string) (int, context.Context) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, op := range t.ops {
+		if key != "" && op.key == key && !op.superseded {
+			op.superseded = true
+			op.cancel()
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.nextID++
+	t.ops = append(t.ops, &inflightOp{
+		id:      t.nextID,
+		key:     key,
+		desc:    desc,
+		started: time.Now(),
+		cancel:  cancel,
+	})
+	return t.nextID, ctx
+}
+
+// end gỡ lệnh khỏi danh sách, trả về true nếu lệnh đã bị lệnh mới thay thế
+func (t *inflightTracker) end(id int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, op := range t.ops {
+		if op.id == id {
+			op.cancel()
+			t.ops = append(t.ops[:i], t.ops[i+1:]...)
+			return op.superseded
+		}
+	}
+	return false
+}
+
+// cancelAll huỷ mọi lệnh đang chạy, trả về số lệnh bị huỷ
+func (t *inflightTracker) cancelAll() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := 0
+	for _, op := range t.ops {
+		if !op.superseded {
+			op.cancel()
+			n++
+		}
+	}
+	return n
+}
+
+// startTicking bắt đầu chuỗi inflightTickMsg nếu chưa có, trả về false khi đã đang tick
+func (t *inflightTracker) startTicking() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.ticking {
+		return false
+	}
+	t.ticking = true
+	return true
+}
+
+// continueTicking quyết định có tick tiếp không: còn lệnh đang chạy hoặc queue còn bận
+func (t *inflightTracker) continueTicking(queueBusy bool) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.ticking = len(t.ops) > 0 || queueBusy
+	return t.ticking
+}
+
+// summary mô tả các lệnh chạy lâu cho info bar, rỗng nếu không có
+func (t *inflightTracker) summary() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var slow []*inflightOp
+	for _, op := range t.ops {
+		if !op.superseded && time.Since(op.started) >= inflightShowAfter {
+			slow = append(slow, op)
+		}
+	}
+	if len(slow) == 0 {
+		return ""
+	}
+	s := fmt.Sprintf("⟳ %s (%.1fs)", slow[0].desc, time.Since(slow[0].started).Seconds())
+	if len(slow) > 1 {
+		s += fmt.Sprintf(" +%d more", len(slow)-1)
+	}
+	return s + " · ctrl+x: cancel"
+}
+
+// commandCancelledMsg báo lệnh tracked đã bị huỷ (bởi người dùng hoặc bị thay thế)
+type commandCancelledMsg struct {
+	Desc       string
+	Superseded bool
+}
+
+// inflightTickMsg chỉ để render lại info bar khi có lệnh chạy lâu
+type inflightTickMsg struct{}
+
+// trackedCmd chạy build với Runner gắn context huỷ được. Lệnh cùng key đang chạy
+// (e.g. diff của file trước khi di chuyển cursor) bị huỷ ngay khi lệnh mới bắt đầu.
+func (m model) trackedCmd(key, desc string, build func(r git.Runner) tea.Cmd) tea.Cmd {
+	id, ctx := m.inflight.begin(key, desc)
+	cmd := build(m.git.WithContext(ctx))
+	if cmd == nil {
+		m.inflight.end(id)
+		return nil
+	}
+
+	run := func() tea.Msg {
+		msg := cmd()
+		cancelled := ctx.Err() != nil
+		superseded := m.inflight.end(id)
+		if cancelled {
+			return commandCancelledMsg{Desc: desc, Superseded: superseded}
+		}
+		return msg
+	}
+	if m.inflight.startTicking() {
+		return tea.Batch(run, inflightTickCmd())
+	}
+	return run
+}
+
+// inflightTickCmd render lại info bar định kỳ khi có lệnh đang chạy
+func inflightTickCmd() tea.Cmd {
+	return tea.Tick(inflightShowAfter, func(time.Time) tea.Msg { return inflightTickMsg{} })
+}
@@ -1,11 +1,16 @@
 package app
 
 import (
+	"fmt"
+	"slices"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 
 	"gitzen/internal/components"
+	"gitzen/internal/config"
+	"gitzen/internal/git"
+	"gitzen/internal/tui"
 	"gitzen/internal/ui"
 )
 
@@ -19,6 +24,7 @@ func (m model) handleKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.backgroundCancel != nil {
 			m.backgroundCancel()
 		}
+		m.diffView.Close()
 		return m, tea.Quit
 	case "tab":
 		// In split mode (Main from Files), tab toggles between panes
@@ -30,6 +36,8 @@ func (m model) handleKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.focus == ui.PaneMain || m.focus == ui.PaneCmdLog {
 			m.focus = ui.PaneFiles
 			m.mainViewSource = 0
+			m.inPatchView = false
+			m.inCompareView = false
 		} else {
 			m.focus = m.nextFocusablePane()
 		}
@@ -47,6 +55,8 @@ func (m model) handleKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.focus == ui.PaneMain || m.focus == ui.PaneCmdLog {
 			m.focus = ui.PaneFiles
 			m.mainViewSource = 0
+			m.inPatchView = false
+			m.inCompareView = false
 		} else {
 			m.focus = m.prevFocusablePane()
 		}
@@ -55,6 +65,23 @@ func (m model) handleKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.refreshAllPanes()
 		return m, m.loadDiffForCurrentPane()
 	case "esc":
+		// Patch builder: về commits pane, giữ các dòng đã chọn
+		if m.inPatchView {
+			return m.exitPatchView()
+		}
+		if m.inCompareView {
+			return m.exitCompareView()
+		}
+		// Bỏ vùng chọn trong Files pane
+		if m.focus == ui.PaneFiles && m.filesPane.ClearSelection() {
+			return m, nil
+		}
+		// Bỏ đánh dấu ref đang so sánh
+		if m.compareFrom != "" && m.focus != ui.PaneMain {
+			m.compareFrom = ""
+			m.statusMsg = ""
+			return m, nil
+		}
 		// If in hunk view, exit back to files
 		if m.inHunkView {
 			m.inHunkView = false
@@ -81,47 +108,125 @@ func (m model) handleKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "c":
 		if m.focus == ui.PaneFiles && m.filesPane.HasStaged() {
 			m.modal.OpenCommit(false)
+			return m, loadCommitDraftCmd(m.git, false, m.commitMessages)
 		}
 		return m, nil
 	case "A":
+		if m.focus == ui.PaneCommits {
+			// 'A' in commits pane = amend selected commit
+			return m.handleCommitsKeys(key)
+		}
 		if m.focus == ui.PaneFiles && m.filesPane.HasStaged() {
 			m.modal.OpenCommit(true)
+			return m, loadCommitDraftCmd(m.git, true, m.commitMessages)
+		}
+		return m, nil
+
+	// Detached HEAD actions
+	case "b":
+		if m.statusPane.IsDetached() {
+			m.modal.OpenCreateBranch()
+		}
+		return m, nil
+	case "-":
+		if m.statusPane.IsDetached() {
+			return m, m.journaled("checkout previous branch", "", checkoutPreviousBranchCmd(m.git))
 		}
 		return m, nil
 
+	// So sánh hai ref (commit, branch, stash; tag bằng cách nhập range)
+	case "W":
+		if m.focus == ui.PaneCommits || m.focus == ui.PaneBranches || m.focus == ui.PaneStash {
+			return m.handleCompareKey()
+		}
+
+	// Continue / abort / skip khi đang merge, rebase, cherry-pick, revert, bisect, am
+	case "C", "X", "S":
+		if state := m.statusPane.RepoState(); state.InProgress() {
+			return m.handleRepoStateKey(key, state)
+		}
+		if key == "S" && m.focus == ui.PaneCommits {
+			// 'S' in commits pane = autosquash
+			return m.handleCommitsKeys(key)
+		}
+		return m, nil
+
+	// Undo / redo
+	case "z":
+		entry, ok := m.journal.nextUndo()
+		if !ok && !m.journal.isEmpty() {
+			// Journal đã undo hết, không fallback sang reflog để tránh undo chính lệnh undo
+			m.statusMsg = "Nothing left to undo"
+			return m, nil
+		}
+		return m, planUndoCmd(m.git, entry, ok, false)
+	case "Z":
+		entry, ok := m.journal.nextRedo()
+		return m, planUndoCmd(m.git, entry, ok, true)
+
 	// Global git operations
 	case "p":
 		if m.focus == ui.PaneStash {
 			// 'p' in stash pane = pop
 			return m.handleStashKeys(key)
 		}
-		return m, pullCmd(m.git)
+		return m, m.trackedCmd("pull-preview", "fetch upstream", loadPullPreviewCmd)
 	case "P":
-		return m, pushCmd(m.git)
+		return m, loadPushDefaultsCmd(m.git, false)
+	case "F":
+		if m.focus == ui.PaneCommits {
+			// 'F' in commits pane = fixup
+			return m.handleCommitsKeys(key)
+		}
+		// Force push with lease (e.g. sau khi rebase)
+		return m, loadPushDefaultsCmd(m.git, true)
+
+	// Cancel lệnh đang chạy (hook, push...)
+	case "ctrl+x":
+		if m.activeStream != nil {
+			m.activeStream.Cancel()
+			m.statusMsg = "Cancelling " + m.activeStream.String()
+		}
+		if n := m.inflight.cancelAll(); n > 0 && m.activeStream == nil {
+			m.statusMsg = fmt.Sprintf("Cancelling %d running command(s)", n)
+		}
+		return m, nil
 	case "f":
-		return m, fetchCmd(m.git)
+		if m.focus == ui.PaneBranches {
+			// 'f' in branches pane = fast-forward
+			return m.handleBranchesKeys(key)
+		}
+		return m, m.queuedCmd("fetch", fetchCmd(m.git))
 
 	// Jump keys (sidebar panes only, lazygit style)
 	case "1":
 		m.focus = ui.PaneFiles
+		m.inPatchView = false
+		m.inCompareView = false
 		m.layout = ui.CalculateLayout(m.layout.Width, m.layout.Height, m.focus)
 		m.resizeComponents()
 		m.refreshAllPanes()
 		return m, m.loadDiffForCurrentPane()
 	case "2":
 		m.focus = ui.PaneBranches
+		m.inPatchView = false
+		m.inCompareView = false
 		m.layout = ui.CalculateLayout(m.layout.Width, m.layout.Height, m.focus)
 		m.resizeComponents()
 		m.refreshAllPanes()
 		return m, m.loadBranchDiff()
 	case "3":
 		m.focus = ui.PaneCommits
+		m.inPatchView = false
+		m.inCompareView = false
 		m.layout = ui.CalculateLayout(m.layout.Width, m.layout.Height, m.focus)
 		m.resizeComponents()
 		m.refreshAllPanes()
 		return m, m.loadCommitDiff()
 	case "4":
 		m.focus = ui.PaneStash
+		m.inPatchView = false
+		m.inCompareView = false
 		m.layout = ui.CalculateLayout(m.layout.Width, m.layout.Height, m.focus)
 		m.resizeComponents()
 		m.refreshAllPanes()
@@ -144,6 +249,12 @@ func (m model) handleKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.inHunkView {
 			return m.handleHunkViewKeys(key)
 		}
+		if m.inPatchView {
+			return m.handlePatchViewKeys(key)
+		}
+		if m.inCompareView {
+			return m.handleCompareViewKeys(key)
+		}
 		return m.handleMainKeys(key)
 	}
 
@@ -168,7 +279,10 @@ func (m model) handleFilesKeys(key string) (tea.Model, tea.Cmd) {
 		m.filesPane.CursorBottom()
 		m.filesPane.Refresh()
 		return m, m.loadDiffForCurrentPane()
-	case "enter": // Focus main view (lazygit style) with split diff
+	case "enter": // Focus main view (lazygit style) with split diff, or fold/unfold a directory
+		if m.filesPane.ToggleCollapse() {
+			return m, nil
+		}
 		m.focus = ui.PaneMain
 		m.mainViewSource = ui.PaneFiles
 		m.layout = ui.CalculateLayout(m.layout.Width, m.layout.Height, m.focus)
@@ -178,10 +292,27 @@ func (m model) handleFilesKeys(key string) (tea.Model, tea.Cmd) {
 		// Load split diff for selected file
 		item, _, found := m.filesPane.SelectedItem()
 		if found {
-			return m, loadSplitDiffCmd(m.git, item.Path)
+			return m, m.trackedCmd("diff", "diff "+item.Path, func(r git.Runner) tea.Cmd {
+				return loadSplitDiffCmd(r, item.Path, m.diffOpts)
+			})
 		}
 		return m, nil
-	case "v": // Enter hunk view to stage individual hunks
+	case "v": // Chọn vùng: v rồi di chuyển, v lần nữa để dừng kéo (giữ vùng chọn)
+		m.filesPane.ToggleRangeSelect()
+		return m, nil
+	case "shift+down", "shift+up":
+		m.filesPane.StartRangeSelect()
+		if key == "shift+down" {
+			m.filesPane.CursorDown()
+		} else {
+			m.filesPane.CursorUp()
+		}
+		m.filesPane.Refresh()
+		return m, m.loadDiffForCurrentPane()
+	case "H": // Enter hunk view to stage individual hunks
+		if _, _, found := m.filesPane.SelectedItem(); !found {
+			return m, nil
+		}
 		m.focus = ui.PaneMain
 		m.mainViewSource = ui.PaneFiles
 		m.inHunkView = true
@@ -192,9 +323,21 @@ func (m model) handleFilesKeys(key string) (tea.Model, tea.Cmd) {
 	case " ": // space to toggle stage/unstage
 		return m, m.toggleStageCmd()
 	case "a":
-		return m, stageAllCmd(m.git)
+		return m, m.queuedCmd("stage all", stageAllCmd(m.git))
 	case "d":
-		return m.discardSelectedFile()
+		return m.openDiscardMenu()
+	case "s":
+		return m.stashSelectedFiles()
+	case "i":
+		return m.ignoreSelectedFiles()
+	case "`": // Flat list ↔ directory tree
+		m.filesPane.ToggleTreeView()
+		if m.filesPane.IsTreeView() {
+			m.statusMsg = "Files: tree view"
+		} else {
+			m.statusMsg = "Files: flat view"
+		}
+		return m, m.loadDiffForCurrentPane()
 	}
 	return m, nil
 }
@@ -220,11 +363,33 @@ func (m model) handleBranchesKeys(key string) (tea.Model, tea.Cmd) {
 	case "enter", " ":
 		branch, found := m.branchesPane.SelectedBranch()
 		if found && !branch.IsCurrent {
-			return m, checkoutBranchCmd(m.git, branch.Name)
+			return m, m.journaled("checkout "+branch.Name, "", checkoutBranchCmd(m.git, branch.Name))
 		}
 	case "n":
 		m.modal.OpenCreateBranch()
 		return m, nil
+	case "R":
+		branch, found := m.branchesPane.SelectedBranch()
+		if found {
+			m.openRenameBranch(branch)
+		}
+		return m, nil
+	case "u":
+		branch, found := m.branchesPane.SelectedBranch()
+		if found {
+			m.openUpstreamMenu(branch)
+		}
+		return m, nil
+	case "f":
+		branch, found := m.branchesPane.SelectedBranch()
+		if found {
+			if branch.Upstream == "" {
+				m.modal.OpenError("Branch " + branch.Name + " has no upstream")
+				return m, nil
+			}
+			return m, m.queuedCmd("fast-forward "+branch.Name, fastForwardBranchCmd(m.git, branch))
+		}
+		return m, nil
 	case "d":
 		branch, found := m.branchesPane.SelectedBranch()
 		if found {
@@ -233,7 +398,7 @@ func (m model) handleBranchesKeys(key string) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 			m.modal.OpenConfirm("Delete branch "+branch.Name+"?", func() tea.Cmd {
-				return deleteBranchCmd(m.git, branch.Name, false)
+				return m.queuedCmd("delete branch "+branch.Name, journaledDeleteBranchCmd(m.git, branch.Name, false))
 			})
 		}
 		return m, nil
@@ -245,7 +410,7 @@ func (m model) handleBranchesKeys(key string) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 			m.modal.OpenConfirm("Force delete branch "+branch.Name+"?", func() tea.Cmd {
-				return deleteBranchCmd(m.git, branch.Name, true)
+				return m.queuedCmd("delete branch "+branch.Name, journaledDeleteBranchCmd(m.git, branch.Name, true))
 			})
 		}
 		return m, nil
@@ -278,6 +443,14 @@ func (m model) handleCommitsKeys(key string) (tea.Model, tea.Cmd) {
 		m.resizeComponents()
 		m.refreshAllPanes()
 		return m, m.loadCommitDiff()
+	case " ": // Checkout commit (or reflog entry) as detached HEAD
+		hash, found := m.commitsPane.SelectedHash()
+		if found {
+			m.modal.OpenConfirm("Checkout "+hash+" (detached HEAD)?", func() tea.Cmd {
+				return m.journaled("checkout "+hash, "", checkoutCommitCmd(m.git, hash))
+			})
+		}
+		return m, nil
 	case "[", "]": // Toggle between Commits and Reflog (lazygit style)
 		m.commitsPane.ToggleMode()
 		m.commitsPane.Refresh()
@@ -285,14 +458,72 @@ func (m model) handleCommitsKeys(key string) (tea.Model, tea.Cmd) {
 	case "r": // Reset soft
 		if m.commitsPane.SelectedIndex() == 0 && m.commitsPane.ItemCount() > 0 {
 			m.modal.OpenConfirm("Undo last commit (keep staged)?", func() tea.Cmd {
-				return resetSoftCmd(m.git, 1)
+				return m.journaled("reset --soft HEAD~1", "soft", resetSoftCmd(m.git, 1))
 			})
 		}
 		return m, nil
 	case "R": // Reset mixed
 		if m.commitsPane.SelectedIndex() == 0 && m.commitsPane.ItemCount() > 0 {
 			m.modal.OpenConfirm("Undo last commit (keep unstaged)?", func() tea.Cmd {
-				return resetMixedCmd(m.git, 1)
+				return m.journaled("reset --mixed HEAD~1", "mixed", resetMixedCmd(m.git, 1))
+			})
+		}
+		return m, nil
+	case "F": // Create fixup! commit for selected commit
+		commit, found := m.commitsPane.SelectedCommit()
+		if !found {
+			return m, nil
+		}
+		if !m.filesPane.HasStaged() {
+			m.statusMsg = "Stage changes first"
+			return m, nil
+		}
+		return m, m.journaled("fixup "+commit.Hash, "soft", fixupCommitCmd(m.git, commit.Hash))
+	case "A": // Amend staged changes into selected commit
+		commit, found := m.commitsPane.SelectedCommit()
+		if !found {
+			return m, nil
+		}
+		if !m.filesPane.HasStaged() {
+			m.statusMsg = "Stage changes first"
+			return m, nil
+		}
+		if m.commitsPane.SelectedIndex() == 0 {
+			return m, m.journaled("amend", "soft", commitAmendCmd(m.git, "", false))
+		}
+		amend := func(noVerify bool) func() tea.Cmd {
+			return func() tea.Cmd {
+				return m.journaled("amend "+commit.Hash, "soft", amendOlderCommitCmd(m.git, commit.Hash, noVerify))
+			}
+		}
+		m.modal.OpenMenuWithHeader("Amend "+commit.Hash+" "+commit.Message, []string{"Commits after it will be rebased."}, []components.MenuItem{
+			{Key: "a", Label: "amend staged changes into " + commit.Hash, Action: amend(false)},
+			{Key: "A", Label: "amend staged changes into " + commit.Hash + ", skip hooks", Action: amend(true)},
+		})
+		return m, nil
+	case "e": // Export commit(s) as patch files or mbox
+		commit, found := m.commitsPane.SelectedCommit()
+		if found {
+			m.openExportPatchMenu(commit)
+		}
+		return m, nil
+	case "I": // Import patches with git am / git apply
+		m.openImportPatchMenu()
+		return m, nil
+	case "B": // Build custom patch from files/hunks/lines of this commit
+		commit, found := m.commitsPane.SelectedCommit()
+		if found {
+			age := m.commitsPane.SelectedIndex()
+			return m, m.trackedCmd("diff", "show "+commit.Hash, func(r git.Runner) tea.Cmd {
+				return loadCommitPatchCmd(r, commit, age)
+			})
+		}
+		return m, nil
+	case "S": // Squash fixup! commits with rebase --autosquash
+		commit, found := m.commitsPane.SelectedCommit()
+		if found {
+			m.modal.OpenConfirm("Autosquash fixup! commits onto "+commit.Hash+"?", func() tea.Cmd {
+				return m.journaled("autosquash onto "+commit.Hash, "soft", autosquashCmd(m.git, commit.Hash))
 			})
 		}
 		return m, nil
@@ -328,18 +559,18 @@ func (m model) handleStashKeys(key string) (tea.Model, tea.Cmd) {
 	case " ": // Stash apply
 		entry, found := m.stashPane.SelectedEntry()
 		if found {
-			return m, stashApplyCmd(m.git, entry.Ref)
+			return m, m.queuedCmd("stash apply "+entry.Ref, stashApplyCmd(m.git, entry.Ref))
 		}
 	case "p": // Stash pop
 		entry, found := m.stashPane.SelectedEntry()
 		if found {
-			return m, stashPopCmd(m.git, entry.Ref)
+			return m, m.queuedCmd("stash pop "+entry.Ref, stashPopCmd(m.git, entry.Ref))
 		}
 	case "d": // Stash drop
 		entry, found := m.stashPane.SelectedEntry()
 		if found {
 			m.modal.OpenConfirm("Drop "+entry.Ref+"?", func() tea.Cmd {
-				return stashDropCmd(m.git, entry.Ref)
+				return m.queuedCmd("stash drop "+entry.Ref, stashDropCmd(m.git, entry.Ref))
 			})
 		}
 		return m, nil
@@ -362,6 +593,19 @@ func (m model) handleCmdLogKeys(key string) (tea.Model, tea.Cmd) {
 }
 
 func (m model) handleMainKeys(key string) (tea.Model, tea.Cmd) {
+	if opts, changed := nextDiffOptions(m.diffOpts, key); changed {
+		m.setDiffOptions(opts)
+		return m, m.loadMainDiff()
+	}
+	if key == "|" {
+		if m.mainViewSource == ui.PaneFiles {
+			m.statusMsg = sideBySideStatus(m.splitDiffView.ToggleSideBySide())
+		} else {
+			m.statusMsg = sideBySideStatus(m.diffView.ToggleSideBySide())
+		}
+		return m, nil
+	}
+
 	// In split mode (from Files pane), handle split pane navigation
 	if m.mainViewSource == ui.PaneFiles {
 		switch key {
@@ -386,6 +630,27 @@ func (m model) handleMainKeys(key string) (tea.Model, tea.Cmd) {
 
 	// Normal single diff view
 	switch key {
+	case "n", "N", "]", "[":
+		if !m.diffView.CanJump() {
+			m.statusMsg = "Turn off side-by-side (|) to jump between hunks/files"
+			return m, nil
+		}
+		var moved bool
+		switch key {
+		case "n":
+			moved = m.diffView.JumpHunk(1)
+		case "N":
+			moved = m.diffView.JumpHunk(-1)
+		case "]":
+			moved = m.diffView.JumpFile(1)
+		case "[":
+			moved = m.diffView.JumpFile(-1)
+		}
+		if !moved {
+			m.statusMsg = "No more hunks/files in that direction"
+		}
+	case "l":
+		m.openDiffFileMenu()
 	case "j", "down":
 		m.diffView.ScrollDown(1)
 	case "k", "up":
@@ -402,6 +667,217 @@ func (m model) handleMainKeys(key string) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// openDiffFileMenu mở danh sách file của diff đang xem để nhảy tới
+func (m *model) openDiffFileMenu() {
+	files := m.diffView.Files()
+	if len(files) == 0 {
+		m.statusMsg = "No files in this diff"
+		return
+	}
+	if !m.diffView.CanJump() {
+		m.statusMsg = "Turn off side-by-side (|) to jump between hunks/files"
+		return
+	}
+	items := make([]components.MenuItem, len(files))
+	for i, f := range files {
+		line := f.Line
+		items[i] = components.MenuItem{Label: f.Path, Action: func() tea.Cmd {
+			m.diffView.JumpToLine(line)
+			return nil
+		}}
+	}
+	m.modal.OpenMenu(fmt.Sprintf("Jump to file (%d)", len(files)), items)
+}
+
+// nextDiffOptions đổi tuỳ chọn diff theo phím trong main view
+func nextDiffOptions(opts git.DiffOptions, key string) (git.DiffOptions, bool) {
+	switch key {
+	case "w": // whitespace: hiện → -w → -b
+		return opts.NextWhitespace(), true
+	case "{":
+		return opts.WithContext(-1), true
+	case "}":
+		return opts.WithContext(1), true
+	case "a": // thuật toán: mặc định → patience → histogram → myers
+		return opts.NextAlgorithm(), true
+	case "M": // rename/copy detection
+		return opts.NextRenames(), true
+	case "W":
+		opts.WordDiff = !opts.WordDiff
+		return opts, true
+	case "L": // diff nội dung thật của LFS object khi có sẵn local
+		opts.SmudgeLFS = !opts.SmudgeLFS
+		return opts, true
+	}
+	return opts, false
+}
+
+// setDiffOptions đổi tuỳ chọn diff và cập nhật title của các view diff
+func (m *model) setDiffOptions(opts git.DiffOptions) {
+	m.diffOpts = opts
+	m.diffView.SetOptions(opts.String())
+	m.splitDiffView.SetOptions(opts.String())
+	m.compareView.SetOptions(opts.String())
+	m.statusMsg = "Diff: " + describeDiffOptions(opts)
+}
+
+// selectedRef trả về ref đang chọn trong commits/branches/stash pane
+func (m model) selectedRef() (string, bool) {
+	switch m.focus {
+	case ui.PaneCommits:
+		return m.commitsPane.SelectedHash()
+	case ui.PaneBranches:
+		if branch, ok := m.branchesPane.SelectedBranch(); ok {
+			return branch.Name, true
+		}
+	case ui.PaneStash:
+		if entry, ok := m.stashPane.SelectedEntry(); ok {
+			return entry.Ref, true
+		}
+	}
+	return "", false
+}
+
+// handleCompareKey: lần đầu đánh dấu ref "from" (hoặc nhập range), lần sau so sánh với ref đang chọn
+func (m model) handleCompareKey() (tea.Model, tea.Cmd) {
+	ref, ok := m.selectedRef()
+	if !ok {
+		return m, nil
+	}
+	if m.compareFrom == ref {
+		m.compareFrom = ""
+		m.statusMsg = "Compare cancelled"
+		return m, nil
+	}
+	if m.compareFrom != "" {
+		return m.openCompare(m.compareFrom, ref)
+	}
+	m.modal.OpenMenu("Compare", []components.MenuItem{
+		{Key: "m", Label: "mark " + ref + " as the \"from\" side", Action: func() tea.Cmd {
+			return func() tea.Msg { return compareMarkMsg{Ref: ref} }
+		}},
+		{Key: "h", Label: ref + " against HEAD", Action: func() tea.Cmd {
+			return func() tea.Msg { return compareOpenMsg{From: ref, To: "HEAD"} }
+		}},
+		{Key: "t", Label: "enter a range (e.g. v1.0..v2.0)", Action: func() tea.Cmd {
+			m.modal.OpenInput("Compare refs", "from..to (commit, branch, tag or stash)", ref+"..HEAD", func(value string) tea.Cmd {
+				from, to, ok := git.ParseCompareRange(value)
+				if !ok {
+					return func() tea.Msg { return errMsg("Expected a range like v1.0..v2.0") }
+				}
+				return func() tea.Msg { return compareOpenMsg{From: from, To: to} }
+			})
+			return nil
+		}},
+	})
+	return m, nil
+}
+
+// openCompare mở compare view cho from..to
+func (m model) openCompare(from, to string) (tea.Model, tea.Cmd) {
+	if !m.inCompareView {
+		m.compareReturn = m.focus
+	}
+	if m.compareFrom != "" {
+		m.compareFrom = ""
+		m.statusMsg = ""
+	}
+	m.inCompareView = true
+	m.inHunkView = false
+	m.inPatchView = false
+	m.focus = ui.PaneMain
+	m.mainViewSource = 0
+	m.layout = ui.CalculateLayout(m.layout.Width, m.layout.Height, m.focus)
+	m.resizeComponents()
+	m.compareView.Reset(from, to)
+	m.refreshAllPanes()
+	return m, m.trackedCmd("diff", "compare "+from+".."+to, func(r git.Runner) tea.Cmd {
+		return loadCompareCmd(r, from, to, m.diffOpts)
+	})
+}
+
+// exitCompareView rời compare view về pane đã mở nó
+func (m model) exitCompareView() (tea.Model, tea.Cmd) {
+	m.inCompareView = false
+	m.focus = m.compareReturn
+	if m.focus == 0 || m.focus == ui.PaneMain {
+		m.focus = ui.PaneCommits
+	}
+	m.layout = ui.CalculateLayout(m.layout.Width, m.layout.Height, m.focus)
+	m.resizeComponents()
+	m.refreshAllPanes()
+	return m, m.loadDiffForCurrentPane()
+}
+
+// loadCompareFileDiff load diff file đang chọn trong compare view
+func (m model) loadCompareFileDiff() tea.Cmd {
+	file, ok := m.compareView.SelectedFile()
+	if !ok {
+		return nil
+	}
+	c := m.compareView.Comparison()
+	return m.trackedCmd("diff", "diff "+file.Path, func(r git.Runner) tea.Cmd {
+		return loadCompareFileDiffCmd(r, c.From, c.To, file, m.diffOpts)
+	})
+}
+
+func (m model) handleCompareViewKeys(key string) (tea.Model, tea.Cmd) {
+	if opts, changed := nextDiffOptions(m.diffOpts, key); changed {
+		m.setDiffOptions(opts)
+		if key == "M" {
+			// Rename detection đổi danh sách file
+			c := m.compareView.Comparison()
+			return m.openCompare(c.From, c.To)
+		}
+		return m, m.loadCompareFileDiff()
+	}
+
+	switch key {
+	case "|":
+		m.statusMsg = sideBySideStatus(m.compareView.ToggleSideBySide())
+		return m, nil
+	case "j", "down":
+		m.compareView.CursorDown()
+	case "k", "up":
+		m.compareView.CursorUp()
+	case "g":
+		m.compareView.CursorTop()
+	case "G":
+		m.compareView.CursorBottom()
+	case "d":
+		m.compareView.PageDown()
+		return m, nil
+	case "u":
+		m.compareView.PageUp()
+		return m, nil
+	case "s": // Đảo hai phía
+		c := m.compareView.Comparison()
+		return m.openCompare(c.To, c.From)
+	default:
+		return m, nil
+	}
+	if m.compareView.Select() {
+		return m, m.loadCompareFileDiff()
+	}
+	return m, nil
+}
+
+// sideBySideStatus mô tả chế độ hiển thị sau khi toggle
+func sideBySideStatus(on bool) string {
+	if on {
+		return fmt.Sprintf("Side-by-side diff (unified below %d columns)", tui.MinSideBySideWidth)
+	}
+	return "Unified diff"
+}
+
+// describeDiffOptions mô tả tuỳ chọn diff cho status bar
+func describeDiffOptions(opts git.DiffOptions) string {
+	if s := opts.String(); s != "" {
+		return s
+	}
+	return "defaults"
+}
+
 func (m model) handleHunkViewKeys(key string) (tea.Model, tea.Cmd) {
 	switch key {
 	case "j", "down":
@@ -422,9 +898,9 @@ func (m model) handleHunkViewKeys(key string) (tea.Model, tea.Cmd) {
 			path := m.hunkView.CurrentPath()
 			isStaged := m.hunkView.IsStaged()
 			if isStaged {
-				return m, unstageHunkCmd(m.git, path, hunk.Content)
+				return m, m.queuedCmd("unstage hunk", unstageHunkCmd(m.git, path, hunk.Content))
 			}
-			return m, stageHunkCmd(m.git, path, hunk.Content)
+			return m, m.queuedCmd("stage hunk", stageHunkCmd(m.git, path, hunk.Content))
 		}
 	case "tab":
 		m.splitDiffView.ToggleFocus()
@@ -437,6 +913,45 @@ func (m model) handleHunkViewKeys(key string) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// exitPatchView rời patch builder về commits pane
+func (m model) exitPatchView() (tea.Model, tea.Cmd) {
+	m.inPatchView = false
+	m.focus = ui.PaneCommits
+	m.mainViewSource = 0
+	m.layout = ui.CalculateLayout(m.layout.Width, m.layout.Height, m.focus)
+	m.resizeComponents()
+	m.refreshAllPanes()
+	return m, m.loadCommitDiff()
+}
+
+func (m model) handlePatchViewKeys(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "j", "down":
+		m.patchView.CursorDown()
+		m.patchView.Refresh()
+	case "k", "up":
+		m.patchView.CursorUp()
+		m.patchView.Refresh()
+	case "g":
+		m.patchView.CursorTop()
+		m.patchView.Refresh()
+	case "G":
+		m.patchView.CursorBottom()
+		m.patchView.Refresh()
+	case "d":
+		m.patchView.PageDown()
+	case "u":
+		m.patchView.PageUp()
+	case " ": // Chọn/bỏ dòng, hunk hoặc file dưới cursor
+		m.patchView.ToggleSelected()
+	case "a": // Chọn/bỏ cả hunk
+		m.patchView.ToggleSelectedHunk()
+	case "m":
+		m.openCustomPatchMenu()
+	}
+	return m, nil
+}
+
 // handleModalInput xử lý input khi modal đang mở
 func (m model) handleModalInput(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if key, ok := msg.(tea.KeyMsg); ok {
@@ -446,19 +961,26 @@ func (m model) handleModalInput(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "esc":
 				m.modal.Close()
 				return m, nil
-			case "enter":
-				msgVal := strings.TrimSpace(m.modal.InputValue())
-				isAmend := m.modal.IsAmendMode()
-				m.modal.Close()
-
-				if isAmend {
-					return m, commitAmendCmd(m.git, msgVal)
-				}
-				if msgVal == "" {
-					m.modal.OpenError("Commit message is empty")
-					return m, nil
+			case "tab", "shift+tab":
+				m.modal.ToggleCommitFocus()
+				return m, nil
+			case "ctrl+p":
+				m.modal.RecallPrevMessage()
+				return m, nil
+			case "ctrl+n":
+				m.modal.RecallNextMessage()
+				return m, nil
+			case "ctrl+e":
+				return m, prepareCommitEditorCmd(m.git, m.modal.CommitMessage())
+			case "ctrl+t":
+				m.modal.ToggleSkipHooks()
+				return m, nil
+			case "enter", "ctrl+s":
+				if key.String() == "enter" && m.modal.CommitBodyFocused() {
+					// enter trong body = xuống dòng
+					break
 				}
-				return m, commitCmd(m.git, msgVal)
+				return m.submitCommit()
 			}
 
 		case components.ModalCreateBranch:
@@ -473,7 +995,85 @@ func (m model) handleModalInput(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.modal.OpenError("Branch name is empty")
 					return m, nil
 				}
-				return m, createBranchCmd(m.git, name)
+				return m, m.journaled("create branch "+name, "", createBranchCmd(m.git, name))
+			}
+
+		case components.ModalInput:
+			switch key.String() {
+			case "esc":
+				m.modal.Close()
+				return m, nil
+			case "enter":
+				value := strings.TrimSpace(m.modal.InputValue())
+				submit := m.modal.InputSubmit()
+				m.modal.Close()
+				if submit != nil {
+					return m, submit(value)
+				}
+				return m, nil
+			}
+
+		case components.ModalMenu:
+			switch key.String() {
+			case "esc", "q":
+				m.modal.Close()
+				return m, nil
+			case "j", "down":
+				m.modal.MenuCursorDown()
+				return m, nil
+			case "k", "up":
+				m.modal.MenuCursorUp()
+				return m, nil
+			case "enter":
+				item, found := m.modal.SelectedMenuItem()
+				m.modal.Close()
+				if found && item.Action != nil {
+					return m, item.Action()
+				}
+				return m, nil
+			default:
+				if item, found := m.modal.MenuItemByKey(key.String()); found {
+					m.modal.Close()
+					if item.Action != nil {
+						return m, item.Action()
+					}
+				}
+				return m, nil
+			}
+
+		case components.ModalPush:
+			switch key.String() {
+			case "esc":
+				m.modal.Close()
+				return m, nil
+			case "enter":
+				opts := m.modal.PushOptions()
+				m.modal.Close()
+				if opts.ForceWithLease {
+					m.modal.OpenConfirm(forcePushPrompt(opts), func() tea.Cmd {
+						return m.queuedCmd("push", pushCmd(m.git, opts))
+					})
+					return m, nil
+				}
+				return m, m.queuedCmd("push", pushCmd(m.git, opts))
+			case "ctrl+r":
+				m.modal.CyclePushRemote()
+				return m, nil
+			case "ctrl+u":
+				m.modal.TogglePushOption("upstream")
+				return m, nil
+			case "ctrl+g":
+				m.modal.TogglePushOption("tags")
+				return m, nil
+			case "ctrl+d":
+				m.modal.TogglePushOption("dry-run")
+				return m, nil
+			case "ctrl+t":
+				m.modal.TogglePushOption("no-verify")
+				return m, nil
+			case "ctrl+f":
+				m.modal.TogglePushOption("force")
+				return m, nil
 			}
 
 		case components.ModalConfirm:
@@ -505,6 +1105,237 @@ func (m model) handleModalInput(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // --- Helper methods ---
 
+// journaledPullCmd pull theo strategy và ghi journal để có thể undo
+func (m model) journaledPullCmd(strategy string) tea.Cmd {
+	return m.journaled("pull ("+strategy+")", "", pullCmd(m.git, strategy, m.repoConfig.PullAutostash))
+}
+
+// handleRepoStateKey dispatch C/X/S tới lệnh git tương ứng với thao tác đang dở dang
+func (m model) handleRepoStateKey(key string, state git.RepoState) (tea.Model, tea.Cmd) {
+	label := strings.ToLower(state.Kind.String())
+	switch key {
+	case "C":
+		args := git.ContinueArgs(state.Kind)
+		if args == nil {
+			m.statusMsg = "Cannot continue while " + label + " (use S to skip or X to abort)"
+			return m, nil
+		}
+		return m, m.queuedCmd("continue "+label, repoStateCmd(m.git, args, "Continued "+label))
+	case "X":
+		args := git.AbortArgs(state.Kind)
+		m.modal.OpenConfirm("Abort "+label+"?\n\ngit "+strings.Join(args, " "), func() tea.Cmd {
+			return m.queuedCmd("abort "+label, repoStateCmd(m.git, args, "Aborted "+label))
+		})
+	case "S":
+		args := git.SkipArgs(state.Kind)
+		if args == nil {
+			m.statusMsg = "Cannot skip while " + label
+			return m, nil
+		}
+		m.modal.OpenConfirm("Skip the current step of "+label+"? Its changes will be dropped.\n\ngit "+strings.Join(args, " "), func() tea.Cmd {
+			return m.queuedCmd("skip "+label, repoStateCmd(m.git, args, "Skipped step of "+label))
+		})
+	}
+	return m, nil
+}
+
+// openExportPatchMenu hỏi xuất commit đang chọn hay commit..HEAD, dạng thư mục hay mbox
+func (m model) openExportPatchMenu(commit git.CommitItem) {
+	export := func(toHead, mbox bool) func() tea.Cmd {
+		return func() tea.Cmd {
+			if mbox {
+				m.modal.OpenInput("Export mbox", "file path (relative to repo)", "patches/"+commit.Hash+".mbox", func(path string) tea.Cmd {
+					if path == "" {
+						return nil
+					}
+					return exportMboxCmd(m.git, commit.Hash, toHead, path)
+				})
+				return nil
+			}
+			m.modal.OpenInput("Export patches", "directory (relative to repo)", "patches", func(dir string) tea.Cmd {
+				if dir == "" {
+					return nil
+				}
+				return exportPatchesCmd(m.git, commit.Hash, toHead, dir)
+			})
+			return nil
+		}
+	}
+	m.modal.OpenMenu("Export "+commit.Hash+" "+commit.Message, []components.MenuItem{
+		{Key: "p", Label: "this commit as .patch file", Action: export(false, false)},
+		{Key: "r", Label: "this commit..HEAD as .patch files", Action: export(true, false)},
+		{Key: "m", Label: "this commit as mbox", Action: export(false, true)},
+		{Key: "M", Label: "this commit..HEAD as a single mbox", Action: export(true, true)},
+	})
+}
+
+// openCustomPatchMenu hiển thị các thao tác với custom patch đang xây dựng
+func (m model) openCustomPatchMenu() {
+	if m.customPatch.IsEmpty() {
+		m.statusMsg = "Custom patch is empty, select lines with space"
+		return
+	}
+	patch := m.customPatch.Patch()
+	commits := m.customPatch.Commits()
+	desc := fmt.Sprintf("%d lines from %d commits", m.customPatch.LineCount(), len(commits))
+
+	items := []components.MenuItem{
+		{Key: "a", Label: "apply to working tree", Action: func() tea.Cmd {
+			return m.queuedCmd("apply custom patch", applyCustomPatchCmd(m.git, patch, false, false))
+		}},
+		{Key: "r", Label: "apply in reverse to working tree", Action: func() tea.Cmd {
+			return m.queuedCmd("revert custom patch", applyCustomPatchCmd(m.git, patch, false, true))
+		}},
+		{Key: "i", Label: "apply to index (stage)", Action: func() tea.Cmd {
+			return m.queuedCmd("stage custom patch", applyCustomPatchCmd(m.git, patch, true, false))
+		}},
+	}
+	if hash, ok := m.customPatch.SingleCommit(); ok {
+		// Commit sửa lại lịch sử chạy hook như commit thường; D/N bỏ qua hook (--no-verify)
+		remove := func(noVerify bool) func() tea.Cmd {
+			return func() tea.Cmd {
+				m.modal.OpenConfirm("Remove patch from "+hash+"? Commits after it will be rebased.", func() tea.Cmd {
+					return tea.Batch(exitPatchViewCmd(), m.patchRewrite("remove patch from "+hash, removePatchFromCommitCmd(m.git, hash, patch, noVerify)))
+				})
+				return nil
+			}
+		}
+		move := func(noVerify bool) func() tea.Cmd {
+			return func() tea.Cmd {
+				m.modal.OpenInput("New commit message", "commit message", "", func(message string) tea.Cmd {
+					if strings.TrimSpace(message) == "" {
+						return nil
+					}
+					return tea.Batch(exitPatchViewCmd(), m.patchRewrite("move patch from "+hash, movePatchToNewCommitCmd(m.git, hash, patch, message, noVerify)))
+				})
+				return nil
+			}
+		}
+		items = append(items,
+			components.MenuItem{Key: "d", Label: "remove from " + hash + " (keep changes in working tree)", Action: remove(false)},
+			components.MenuItem{Key: "D", Label: "remove from " + hash + ", skip hooks", Action: remove(true)},
+			components.MenuItem{Key: "n", Label: "move out of " + hash + " into a new commit", Action: move(false)},
+			components.MenuItem{Key: "N", Label: "move out of " + hash + " into a new commit, skip hooks", Action: move(true)},
+		)
+	}
+	items = append(items,
+		components.MenuItem{Key: "s", Label: "save to file", Action: func() tea.Cmd {
+			m.modal.OpenInput("Save patch", "file path (relative to repo)", "patches/custom.patch", func(path string) tea.Cmd {
+				if path == "" {
+					return nil
+				}
+				return saveCustomPatchCmd(m.git, patch, path)
+			})
+			return nil
+		}},
+		components.MenuItem{Key: "x", Label: "reset patch", Action: func() tea.Cmd {
+			m.customPatch.Reset()
+			m.patchView.Refresh()
+			return nil
+		}},
+	)
+	m.modal.OpenMenu("Custom patch: "+desc, items)
+}
+
+// openImportPatchMenu chọn cách áp dụng patch rồi hỏi đường dẫn file/thư mục patch
+func (m model) openImportPatchMenu() {
+	ask := func(title string, run func(path string) tea.Cmd) func() tea.Cmd {
+		return func() tea.Cmd {
+			m.modal.OpenInput(title, "patch/mbox file or directory of .patch files", "", func(path string) tea.Cmd {
+				if path == "" {
+					return nil
+				}
+				return run(path)
+			})
+			return nil
+		}
+	}
+	m.modal.OpenMenu("Import patches", []components.MenuItem{
+		{Key: "a", Label: "git am (create commits)", Action: ask("git am", func(path string) tea.Cmd {
+			return m.journaled("am "+path, "", amCmd(m.git, path, false))
+		})},
+		{Key: "3", Label: "git am --3way (leave conflicts to resolve)", Action: ask("git am --3way", func(path string) tea.Cmd {
+			return m.journaled("am "+path, "", amCmd(m.git, path, true))
+		})},
+		{Key: "p", Label: "git apply (working tree only)", Action: ask("git apply", func(path string) tea.Cmd {
+			return m.queuedCmd("apply "+path, applyPatchCmd(m.git, path, false, false))
+		})},
+		{Key: "i", Label: "git apply --index (stage changes)", Action: ask("git apply --index", func(path string) tea.Cmd {
+			return m.queuedCmd("apply "+path, applyPatchCmd(m.git, path, true, false))
+		})},
+		{Key: "t", Label: "git apply --3way (leave conflicts to resolve)", Action: ask("git apply --3way", func(path string) tea.Cmd {
+			return m.queuedCmd("apply "+path, applyPatchCmd(m.git, path, false, true))
+		})},
+	})
+}
+
+// openPullMenu hiển thị preview các commit sẽ pull và các cách pull
+func (m *model) openPullMenu(preview git.PullPreview) {
+	strategy := m.repoConfig.EffectivePullStrategy()
+	autostash := ""
+	if m.repoConfig.PullAutostash {
+		autostash = " + autostash"
+	}
+
+	header := []string{fmt.Sprintf("%s: %d incoming, %d local", preview.Upstream, preview.Behind, preview.Ahead)}
+	const maxShown = 8
+	for i, c := range preview.Incoming {
+		if i == maxShown {
+			header = append(header, fmt.Sprintf("  … %d more", preview.Behind-maxShown))
+			break
+		}
+		header = append(header, "  "+m.styles.HashStyle.Render(c.Hash)+" "+c.Message)
+	}
+	if preview.Dirty && !m.repoConfig.PullAutostash {
+		header = append(header, m.styles.WarningStyle.Render("Working tree has local changes (pull_autostash is off)"))
+	}
+
+	var items []components.MenuItem
+	if preview.Diverged() {
+		header = append(header, m.styles.WarningStyle.Render("Local and upstream have diverged, choose how to reconcile:"))
+		items = []components.MenuItem{
+			{Key: "r", Label: "rebase local commits onto " + preview.Upstream + autostash, Action: func() tea.Cmd { return m.journaledPullCmd(config.PullRebase) }},
+			{Key: "m", Label: "merge " + preview.Upstream + autostash, Action: func() tea.Cmd { return m.journaledPullCmd(config.PullMerge) }},
+		}
+	} else {
+		items = []components.MenuItem{
+			{Key: "p", Label: "pull (" + strategy + autostash + ")", Action: func() tea.Cmd { return m.journaledPullCmd(strategy) }},
+		}
+		for _, alt := range []string{config.PullFFOnly, config.PullRebase, config.PullMerge} {
+			if alt == strategy {
+				continue
+			}
+			alt := alt
+			items = append(items, components.MenuItem{Key: alt[:1], Label: "pull (" + alt + autostash + ")", Action: func() tea.Cmd { return m.journaledPullCmd(alt) }})
+		}
+	}
+	m.modal.OpenMenuWithHeader("Pull "+preview.Upstream, header, items)
+}
+
+// submitCommit commit (hoặc amend) với message từ commit modal
+func (m model) submitCommit() (tea.Model, tea.Cmd) {
+	msgVal := m.modal.CommitMessage()
+	isAmend := m.modal.IsAmendMode()
+	noVerify := m.modal.SkipHooks()
+	m.modal.Close()
+
+	if msgVal != "" {
+		// Giữ lại để recall bằng ctrl+p nếu commit thất bại (e.g. hook)
+		m.commitMessages = append([]string{msgVal}, m.commitMessages...)
+		if len(m.commitMessages) > 20 {
+			m.commitMessages = m.commitMessages[:20]
+		}
+	}
+	if isAmend {
+		return m, m.journaled("amend", "soft", commitAmendCmd(m.git, msgVal, noVerify))
+	}
+	if msgVal == "" {
+		m.modal.OpenError("Commit message is empty")
+		return m, nil
+	}
+	return m, m.journaled("commit", "soft", commitCmd(m.git, msgVal, noVerify))
+}
+
 func (m model) nextFocusablePane() ui.PaneID {
 	// Lazygit style: only sidebar panes (Files, Branches, Commits, Stash)
 	order := []ui.PaneID{ui.PaneFiles, ui.PaneBranches, ui.PaneCommits, ui.PaneStash}
@@ -528,14 +1359,32 @@ func (m model) prevFocusablePane() ui.PaneID {
 }
 
 func (m model) toggleStageCmd() tea.Cmd {
+	if m.filesPane.HasSelection() {
+		// Vùng chọn: stage các file unstaged; nếu tất cả đã staged thì unstage
+		staged, unstaged := m.filesPane.SelectedFiles()
+		m.filesPane.ClearSelection()
+		if len(unstaged) > 0 {
+			label := filesLabel(unstaged)
+			return m.queuedCmd("stage "+label, stageFilesCmd(m.git, label, filePaths(unstaged)))
+		}
+		label := filesLabel(staged)
+		return m.queuedCmd("unstage "+label, unstageFilesCmd(m.git, label, filePaths(staged)))
+	}
+	if dir, isStaged, isDir := m.filesPane.SelectedDir(); isDir {
+		// git add / restore --staged trên thư mục áp dụng cho mọi file bên dưới
+		if isStaged {
+			return m.queuedCmd("unstage "+dir.Path+"/", unstageFileCmd(m.git, dir.Path))
+		}
+		return m.queuedCmd("stage "+dir.Path+"/", stageFileCmd(m.git, dir.Path))
+	}
 	item, isStaged, found := m.filesPane.SelectedItem()
 	if !found {
 		return nil
 	}
 	if isStaged {
-		return unstageFileCmd(m.git, item.Path)
+		return m.queuedCmd("unstage "+item.Path, unstageFileCmd(m.git, item.Path))
 	}
-	return stageFileCmd(m.git, item.Path)
+	return m.queuedCmd("stage "+item.Path, stageFileCmd(m.git, item.Path))
 }
 
 func (m model) loadCommitDiff() tea.Cmd {
@@ -544,7 +1393,9 @@ func (m model) loadCommitDiff() tea.Cmd {
 	if !found {
 		return nil
 	}
-	return loadShowCommitCmd(m.git, hash)
+	return m.trackedCmd("diff", "show "+shortHash(hash), func(r git.Runner) tea.Cmd {
+		return loadShowCommitCmd(r, hash, m.diffOpts)
+	})
 }
 
 func (m model) loadBranchDiff() tea.Cmd {
@@ -552,7 +1403,9 @@ func (m model) loadBranchDiff() tea.Cmd {
 	if !found {
 		return nil
 	}
-	return loadBranchDiffCmd(m.git, branch.Name)
+	return m.trackedCmd("diff", "diff "+branch.Name, func(r git.Runner) tea.Cmd {
+		return loadBranchDiffCmd(r, branch.Name, m.diffOpts)
+	})
 }
 
 func (m model) loadStashDiff() tea.Cmd {
@@ -560,24 +1413,237 @@ func (m model) loadStashDiff() tea.Cmd {
 	if !found {
 		return nil
 	}
-	return loadStashDiffCmd(m.git, entry.Ref)
+	return m.trackedCmd("diff", "show "+entry.Ref, func(r git.Runner) tea.Cmd {
+		return loadStashDiffCmd(r, entry.Ref, m.diffOpts)
+	})
 }
 
-func (m model) discardSelectedFile() (tea.Model, tea.Cmd) {
-	if m.filesPane.IsSelectedStaged() {
-		m.modal.OpenError("Cannot discard staged file. Unstage first (space)")
-		return m, nil
+// filePaths trả về path của các file, bỏ trùng (cùng file có thể ở cả staged và unstaged)
+func filePaths(files []git.FileItem) []string {
+	seen := make(map[string]bool, len(files))
+	var paths []string
+	for _, f := range files {
+		if !seen[f.Path] {
+			seen[f.Path] = true
+			paths = append(paths, f.Path)
+		}
+	}
+	return paths
+}
+
+// filesLabel mô tả một nhóm file cho command log và status, e.g. "main.go" hoặc "3 files"
+func filesLabel(files []git.FileItem) string {
+	paths := filePaths(files)
+	if len(paths) == 1 {
+		return paths[0]
 	}
+	return fmt.Sprintf("%d files", len(paths))
+}
 
-	item, _, found := m.filesPane.SelectedItem()
-	if !found {
+// stashSelectedFiles stash các file được chọn (hoặc file/thư mục dưới cursor), hỏi message trước
+func (m model) stashSelectedFiles() (tea.Model, tea.Cmd) {
+	staged, unstaged := m.filesPane.SelectedFiles()
+	files := append(staged, unstaged...)
+	if len(files) == 0 {
 		return m, nil
 	}
+	label := filesLabel(files)
+	m.modal.OpenInput("Stash "+label, "stash message (optional)", "", func(message string) tea.Cmd {
+		m.filesPane.ClearSelection()
+		return m.queuedCmd("stash "+label, stashFilesCmd(m.git, message, label, filePaths(files)))
+	})
+	return m, nil
+}
 
-	isUntracked := item.Status == "?"
-	m.modal.OpenConfirm("Discard changes to "+item.Path+"?", func() tea.Cmd {
-		return discardFileCmd(m.git, item.Path, isUntracked)
+// ignoreSelectedFiles thêm các file được chọn (hoặc file/thư mục dưới cursor) vào .gitignore
+func (m model) ignoreSelectedFiles() (tea.Model, tea.Cmd) {
+	var label, trackedDesc string
+	var paths, tracked []string
+	if dir, _, isDir := m.filesPane.SelectedDir(); isDir && !m.filesPane.HasSelection() {
+		// Thư mục: ignore cả thư mục thay vì từng file
+		label = dir.Path + "/"
+		paths = []string{label}
+		if len(trackedPaths(dir.Files)) > 0 {
+			tracked = []string{dir.Path}
+			trackedDesc = label + " has files"
+		}
+	} else {
+		staged, unstaged := m.filesPane.SelectedFiles()
+		files := append(staged, unstaged...)
+		if len(files) == 0 {
+			return m, nil
+		}
+		m.filesPane.ClearSelection()
+		label = filesLabel(files)
+		paths = filePaths(files)
+		tracked = trackedPaths(files)
+		trackedDesc = fmt.Sprintf("%d of %d files are", len(tracked), len(paths))
+		if len(paths) == 1 {
+			trackedDesc = label + " is"
+		}
+	}
+	if len(tracked) == 0 {
+		return m, m.queuedCmd("ignore "+label, ignoreFilesCmd(m.git, label, paths, nil))
+	}
+	// .gitignore không có tác dụng với file đang được track: hỏi bỏ track trước
+	m.modal.OpenConfirm(trackedDesc+" tracked by git, so .gitignore has no effect. Stop tracking (git rm --cached, files stay on disk) and ignore?", func() tea.Cmd {
+		return m.queuedCmd("untrack and ignore "+label, ignoreFilesCmd(m.git, label, paths, tracked))
 	})
+	return m, nil
+}
+
+// trackedPaths trả về path của các file đang được track (không phải untracked)
+func trackedPaths(files []git.FileItem) []string {
+	var tracked []git.FileItem
+	for _, f := range files {
+		if f.Status != "?" {
+			tracked = append(tracked, f)
+		}
+	}
+	return filePaths(tracked)
+}
+
+// openDiscardMenu mở menu discard cho các file được chọn (hoặc file/thư mục dưới
+// cursor) và cho cả repo; mỗi lựa chọn xoá dữ liệu đều hỏi xác nhận lại
+func (m model) openDiscardMenu() (tea.Model, tea.Cmd) {
+	staged, unstaged := m.filesPane.SelectedFiles()
+	label := filesLabel(append(append([]git.FileItem{}, staged...), unstaged...))
+	if dir, _, isDir := m.filesPane.SelectedDir(); isDir && !m.filesPane.HasSelection() {
+		label = dir.Path + "/"
+	}
+
+	// Thay đổi unstaged của các file đang chọn, kể cả khi chọn dòng staged của file đó
+	onlyUnstaged := m.unstagedFor(staged, unstaged)
+	plan := git.PlanDiscard(staged, onlyUnstaged)
 
+	var items []components.MenuItem
+	if len(onlyUnstaged) > 0 {
+		unstagedLabel := filesLabel(onlyUnstaged)
+		items = append(items, components.MenuItem{Key: "u", Label: "Discard unstaged changes (" + unstagedLabel + ")", Action: func() tea.Cmd {
+			m.modal.OpenConfirm("Discard unstaged changes to "+unstagedLabel+"? Untracked files will be deleted.", func() tea.Cmd {
+				m.filesPane.ClearSelection()
+				return m.queuedCmd("discard "+unstagedLabel, discardFilesCmd(m.git, unstagedLabel, onlyUnstaged))
+			})
+			return nil
+		}})
+	}
+	if !plan.Empty() {
+		items = append(items, components.MenuItem{Key: "a", Label: "Discard staged and unstaged changes (" + label + ")", Action: func() tea.Cmd {
+			m.modal.OpenConfirm("Discard ALL changes to "+label+", staged and unstaged? New files will be deleted.", func() tea.Cmd {
+				m.filesPane.ClearSelection()
+				return m.queuedCmd("discard all "+label, discardAllChangesCmd(m.git, label, plan))
+			})
+			return nil
+		}})
+	}
+	items = append(items,
+		components.MenuItem{Key: "r", Label: "Discard all changes in the repo (reset --hard + clean -fd)", Action: func() tea.Cmd {
+			m.modal.OpenConfirm("Discard ALL changes in the repo, including untracked files? This cannot be undone.", func() tea.Cmd {
+				return m.queuedCmd("discard all changes", resetHardCmd(m.git, true))
+			})
+			return nil
+		}},
+		components.MenuItem{Key: "h", Label: "Hard reset to HEAD (keep untracked files)", Action: func() tea.Cmd {
+			m.modal.OpenConfirm("git reset --hard HEAD? Staged and unstaged changes to tracked files will be lost.", func() tea.Cmd {
+				return m.queuedCmd("reset --hard HEAD", resetHardCmd(m.git, false))
+			})
+			return nil
+		}},
+		components.MenuItem{Key: "c", Label: "Clean untracked files… (preview first)", Action: func() tea.Cmd {
+			return cleanPreviewCmd(m.git)
+		}},
+	)
+	m.modal.OpenMenu("Discard", items)
 	return m, nil
 }
+
+// unstagedFor bổ sung thay đổi unstaged của cùng path cho các file staged được chọn,
+// để discard cũng áp dụng cho phần chưa stage của file đó
+func (m model) unstagedFor(staged, unstaged []git.FileItem) []git.FileItem {
+	out := append([]git.FileItem{}, unstaged...)
+	for _, f := range staged {
+		for _, u := range m.filesPane.UnstagedItems() {
+			if u.Path == f.Path && !slices.Contains(out, u) {
+				out = append(out, u)
+			}
+		}
+	}
+	return out
+}
+
+// openCleanConfirm hiện danh sách git clean -n và hỏi xác nhận trước khi xoá
+func (m *model) openCleanConfirm(paths []string) {
+	if len(paths) == 0 {
+		m.statusMsg = "No untracked files to clean"
+		return
+	}
+	header := []string{fmt.Sprintf("git clean -fd will delete %d untracked files/directories:", len(paths))}
+	const maxShown = 12
+	for i, p := range paths {
+		if i == maxShown {
+			header = append(header, fmt.Sprintf("  … %d more", len(paths)-maxShown))
+			break
+		}
+		header = append(header, "  "+p)
+	}
+	m.modal.OpenMenuWithHeader("Clean untracked files", header, []components.MenuItem{
+		{Key: "y", Label: fmt.Sprintf("Delete %d untracked files/directories", len(paths)), Action: func() tea.Cmd {
+			return m.queuedCmd("clean untracked", cleanUntrackedCmd(m.git, paths))
+		}},
+		{Key: "n", Label: "Cancel"},
+	})
+}
+
+// openRenameBranch mở modal đổi tên branch, hỏi thêm có đổi tên trên remote không nếu branch có upstream
+func (m model) openRenameBranch(branch git.Branch) {
+	m.modal.OpenInput("Rename "+branch.Name, "Enter new branch name", branch.Name, func(newName string) tea.Cmd {
+		if newName == "" || newName == branch.Name {
+			return nil
+		}
+		if branch.Upstream == "" {
+			return m.queuedCmd("rename branch "+branch.Name, renameBranchCmd(m.git, branch.Name, newName, false))
+		}
+		m.modal.OpenMenu("Rename "+branch.Name+" → "+newName, []components.MenuItem{
+			{Key: "l", Label: "Rename local branch only", Action: func() tea.Cmd {
+				return m.queuedCmd("rename branch "+branch.Name, renameBranchCmd(m.git, branch.Name, newName, false))
+			}},
+			{Key: "r", Label: "Rename local branch and on remote (" + branch.Upstream + ")", Action: func() tea.Cmd {
+				return m.queuedCmd("rename branch "+branch.Name, renameBranchCmd(m.git, branch.Name, newName, true))
+			}},
+		})
+		return nil
+	})
+}
+
+// openUpstreamMenu mở menu quản lý upstream cho branch
+func (m model) openUpstreamMenu(branch git.Branch) {
+	title := "Upstream of " + branch.Name
+	if branch.Upstream != "" {
+		title += " (" + branch.Upstream + ")"
+	}
+
+	items := []components.MenuItem{
+		{Key: "s", Label: "Set upstream…", Action: func() tea.Cmd {
+			suggestion := branch.Upstream
+			if suggestion == "" {
+				suggestion = "origin/" + branch.Name
+			}
+			m.modal.OpenInput("Set upstream of "+branch.Name, "remote/branch", suggestion, func(upstream string) tea.Cmd {
+				if upstream == "" {
+					return nil
+				}
+				return m.queuedCmd("set upstream "+branch.Name, setUpstreamCmd(m.git, branch.Name, upstream))
+			})
+			return nil
+		}},
+	}
+	if branch.Upstream != "" {
+		items = append(items, components.MenuItem{Key: "u", Label: "Unset upstream", Action: func() tea.Cmd {
+			return m.queuedCmd("unset upstream "+branch.Name, unsetUpstreamCmd(m.git, branch.Name))
+		}})
+		items = append(items, components.MenuItem{Key: "f", Label: "Fast-forward to " + branch.Upstream, Action: func() tea.Cmd {
+			return m.queuedCmd("fast-forward "+branch.Name, fastForwardBranchCmd(m.git, branch))
+		}})
+	}
+	m.modal.OpenMenu(title, items)
+}
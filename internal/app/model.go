@@ -2,6 +2,8 @@ package app
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"path/filepath"
 	"strings"
 	"time"
@@ -39,6 +41,8 @@ type model struct {
 	diffView      *components.DiffView
 	splitDiffView *components.SplitDiffView
 	hunkView      *components.HunkView
+	patchView     *components.PatchView
+	compareView   *components.CompareView
 	cmdLogPane    *components.CmdLogPane
 	modal         *components.Modal
 	toastManager  *components.ToastManager
@@ -49,6 +53,33 @@ type model struct {
 	// Track hunk view mode
 	inHunkView bool
 
+	// Tuỳ chọn diff (whitespace, context, algorithm...) đổi bằng phím trong main view
+	diffOpts git.DiffOptions
+
+	// Custom patch builder: các dòng chọn từ một hoặc nhiều commit (B trong commits pane)
+	customPatch *git.CustomPatch
+	inPatchView bool
+
+	// So sánh hai ref (W): ref đã đánh dấu làm phía "from", và pane để quay về
+	compareFrom   string
+	compareReturn ui.PaneID
+	inCompareView bool
+
+	// Action journal cho undo/redo (z/Z)
+	journal *undoJournal
+
+	// Cấu hình repo (.git/gitzen-config.yml)
+	repoConfig *config.RepoConfig
+
+	// Lệnh git dạng stream đang chạy (cancel bằng ctrl+x)
+	activeStream *git.Stream
+
+	// Các lệnh git (diff, show...) đang chạy, huỷ được bằng ctrl+x hoặc khi bị lệnh mới thay thế
+	inflight *inflightTracker
+
+	// Commit messages đã submit trong phiên, mới nhất trước (recall bằng ctrl+p)
+	commitMessages []string
+
 	// UI
 	styles ui.Styles
 	layout ui.Layout
@@ -62,12 +93,15 @@ func NewModel(repoRoot string) tea.Model {
 	styles := ui.DefaultStyles
 
 	m := model{
-		repoRoot:   repoRoot,
-		repoName:   filepath.Base(repoRoot),
-		git:        git.New(repoRoot),
-		focus:      ui.PaneFiles,
-		styles:     styles,
-		inHunkView: false,
+		repoRoot:    repoRoot,
+		repoName:    filepath.Base(repoRoot),
+		git:         git.New(repoRoot),
+		focus:       ui.PaneFiles,
+		styles:      styles,
+		inHunkView:  false,
+		customPatch: git.NewCustomPatch(),
+		journal:     newUndoJournal(),
+		inflight:    newInflightTracker(),
 
 		// Initialize background operations
 		backgroundManager: background.New(git.New(repoRoot)),
@@ -81,6 +115,8 @@ func NewModel(repoRoot string) tea.Model {
 		diffView:      components.NewDiffView(styles),
 		splitDiffView: components.NewSplitDiffView(styles),
 		hunkView:      components.NewHunkView(styles),
+		patchView:     components.NewPatchView(styles),
+		compareView:   components.NewCompareView(styles),
 		cmdLogPane:    components.NewCmdLogPane(styles),
 		modal:         components.NewModal(styles),
 		toastManager:  components.NewToastManager(styles),
@@ -95,6 +131,10 @@ func NewModel(repoRoot string) tea.Model {
 		m.cmdLogPane.AddEntry("warning: failed to load config, using defaults: " + err.Error())
 		repoConfig = config.NewDefaultConfig()
 	}
+	m.repoConfig = repoConfig
+	if sec := repoConfig.NetworkIdleTimeoutSec; sec != 0 {
+		m.git = m.git.WithNetworkIdleTimeout(time.Duration(sec) * time.Second)
+	}
 
 	if err := m.backgroundManager.InitFileWatcher(repoRoot, repoConfig.FileWatch.Enabled); err != nil {
 		// Log warning but don't fail - file watching is not critical
@@ -138,10 +178,18 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case statusLoadedMsg:
 		m.filesPane.SetData(msg.Status.Staged, msg.Status.Unstaged)
+		m.statusPane.SetRepoState(msg.RepoState)
 		return m, m.loadDiffForCurrentPane()
 
 	case commitsLoadedMsg:
 		m.commitsPane.SetData(msg.Commits)
+		if len(msg.Commits) == 0 {
+			return m, nil
+		}
+		return m, loadCommitSignaturesCmd(m.git)
+
+	case commitSignaturesLoadedMsg:
+		m.commitsPane.SetSignatures(msg.Signatures)
 		return m, nil
 
 	case reflogLoadedMsg:
@@ -150,6 +198,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case branchLoadedMsg:
 		m.statusPane.SetData(m.repoName, msg.Branch)
+		m.statusPane.SetDetached(msg.DetachedHash)
 		return m, nil
 
 	case branchesLoadedMsg:
@@ -176,6 +225,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.diffView.SetDiffWithContext(msg.Diff, components.DiffContext(msg.Context), msg.Subtitle)
 		return m, nil
 
+	case diffStreamMsg:
+		m.diffView.SetBuffer(msg.Buffer, components.DiffContext(msg.Context), msg.Subtitle)
+		return m, waitDiffStreamCmd(msg.Buffer)
+
+	case diffStreamProgressMsg:
+		// Buffer đã bị thay bởi diff khác (và đã đóng) thì dừng theo dõi
+		if m.diffView.Buffer() != msg.Buffer {
+			return m, nil
+		}
+		m.diffView.BufferUpdated()
+		if done, err := msg.Buffer.Done(); done {
+			if err != nil {
+				m.statusMsg = "Diff incomplete: " + err.Error()
+			}
+			return m, nil
+		}
+		return m, waitDiffStreamCmd(msg.Buffer)
+
 	case splitDiffLoadedMsg:
 		m.splitDiffView.SetDiffs(msg.Unstaged, msg.Staged, msg.FilePath)
 		return m, nil
@@ -184,6 +251,39 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.hunkView.SetHunks(msg.Hunks, msg.Path, msg.Staged)
 		return m, nil
 
+	case compareMarkMsg:
+		m.compareFrom = msg.Ref
+		m.statusMsg = "Comparing from " + msg.Ref + ": select another ref and press W"
+		return m, nil
+
+	case compareOpenMsg:
+		return m.openCompare(msg.From, msg.To)
+
+	case compareLoadedMsg:
+		m.compareView.SetComparison(msg.Comparison)
+		return m, nil
+
+	case compareFileDiffLoadedMsg:
+		m.compareView.SetFileDiff(msg.Path, msg.Diff)
+		return m, nil
+
+	case exitPatchViewMsg:
+		if m.inPatchView {
+			return m.exitPatchView()
+		}
+		return m, nil
+
+	case commitPatchLoadedMsg:
+		m.customPatch.AddCommit(msg.Hash, msg.Age, msg.Diff)
+		m.focus = ui.PaneMain
+		m.mainViewSource = ui.PaneCommits
+		m.inPatchView = true
+		m.layout = ui.CalculateLayout(m.layout.Width, m.layout.Height, m.focus)
+		m.resizeComponents()
+		m.refreshAllPanes()
+		m.patchView.SetCommit(m.customPatch, msg.Hash, msg.Title)
+		return m, nil
+
 	case gitCmdMsg:
 		m.lastGitCmd = string(msg)
 		return m, nil
@@ -205,13 +305,19 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		)
 
 	case gitResultMsg:
-		// Log the git command
-		m.cmdLogPane.AddEntry(msg.Cmd)
-		m.lastGitCmd = msg.Cmd
+		// Log the git command (lệnh stream đã được log khi bắt đầu)
+		if msg.Cmd != "" {
+			m.cmdLogPane.AddEntry(msg.Cmd)
+			m.lastGitCmd = msg.Cmd
+		}
 
 		if msg.Err != nil {
+			if git.IsIndexLockError(msg.Err) {
+				return m, m.queuedCmd("check index.lock", checkIndexLockCmd(m.git, msg.Err))
+			}
 			m.modal.OpenError(msg.Err.Error())
-			return m, nil
+			// Lệnh có thể dừng giữa chừng (conflict khi merge/rebase), cập nhật banner
+			return m, loadStatusCmd(m.git)
 		}
 
 		// Show result as status toast
@@ -225,6 +331,158 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			loadStashCmd(m.git),
 		)
 
+	case indexLockMsg:
+		switch {
+		case !msg.Found:
+			// Lock đã được giải phóng trong lúc kiểm tra, chỉ cần thử lại
+			m.modal.OpenError(msg.Err.Error() + "\n\nindex.lock is gone now, retry the action.")
+		case !msg.Lock.Stale():
+			// Lock cũ mà process vẫn chạy có thể là process bị treo, để người dùng quyết định
+			m.modal.OpenConfirm(fmt.Sprintf("%s exists (last written %s ago) and a git process is running in this repository:\n%s\n\nRemove the lock anyway? Only do this if that process is stuck, removing a lock in use can corrupt the index.", msg.Lock.Path, msg.Lock.Age(), msg.Lock.Holder), func() tea.Cmd {
+				return m.queuedCmd("remove index.lock", removeIndexLockCmd(m.git, true))
+			})
+		default:
+			m.modal.OpenConfirm(fmt.Sprintf("%s exists (last written %s ago) but no git process is running in this repository.\n\nRemove the stale lock?", msg.Lock.Path, msg.Lock.Age()), func() tea.Cmd {
+				return m.queuedCmd("remove index.lock", removeIndexLockCmd(m.git, false))
+			})
+		}
+		return m, nil
+
+	case cleanPreviewMsg:
+		m.openCleanConfirm(msg.Paths)
+		return m, nil
+
+	case pullPreviewLoadedMsg:
+		if msg.Preview.Behind == 0 {
+			m.statusMsg = "Already up to date with " + msg.Preview.Upstream
+			return m, nil
+		}
+		m.openPullMenu(msg.Preview)
+		return m, nil
+
+	case pullDivergedMsg:
+		m.modal.OpenMenuWithHeader("Pull", []string{"Local and upstream have diverged, choose how to reconcile:"}, []components.MenuItem{
+			{Key: "r", Label: "rebase local commits onto upstream", Action: func() tea.Cmd { return m.journaledPullCmd(config.PullRebase) }},
+			{Key: "m", Label: "merge upstream", Action: func() tea.Cmd { return m.journaledPullCmd(config.PullMerge) }},
+		})
+		return m, nil
+
+	case pushDefaultsLoadedMsg:
+		if msg.Force {
+			opts := msg.Opts
+			m.modal.OpenConfirm(forcePushPrompt(opts), func() tea.Cmd {
+				return m.queuedCmd("push", pushCmd(m.git, opts))
+			})
+			return m, nil
+		}
+		m.modal.OpenPush(msg.Remotes, msg.Opts, msg.Leases)
+		return m, nil
+
+	case pushRejectedMsg:
+		// Non-fast-forward: đề nghị force-with-lease với SHA remote mong đợi
+		return m, forcePushCmdPrompt(m.git, msg.Opts)
+
+	case forcePushPromptMsg:
+		opts := msg.Opts
+		m.modal.OpenConfirm("Push rejected (non-fast-forward). "+forcePushPrompt(opts), func() tea.Cmd {
+			return m.queuedCmd("push", pushCmd(m.git, opts))
+		})
+		return m, nil
+
+	case streamStartedMsg:
+		m.activeStream = msg.Stream
+		m.cmdLogPane.AddEntry(msg.Cmd)
+		m.lastGitCmd = msg.Cmd
+		m.statusMsg = "Running… (ctrl+x: cancel)"
+		return m, tea.Batch(listenStreamCmd(msg.Stream), waitStreamCmd(msg))
+
+	case inflightTickMsg:
+		if m.inflight.continueTicking(m.backgroundManager.Queue().Busy()) {
+			return m, inflightTickCmd()
+		}
+		return m, nil
+
+	case commandCancelledMsg:
+		// Lệnh bị thay thế (e.g. cursor đã chuyển sang file khác) thì bỏ qua kết quả
+		if !msg.Superseded {
+			m.cmdLogPane.AddEntry("cancelled: " + msg.Desc)
+			m.statusMsg = "Cancelled " + msg.Desc
+		}
+		return m, nil
+
+	case streamLineMsg:
+		// Dòng progress (\r) chỉ cập nhật progress bar, log dòng cuối cùng của mỗi phase
+		if progress, ok := git.ParseProgress(msg.Line); ok {
+			m.statusPane.SetProgress(progress)
+			if !progress.Done {
+				return m, listenStreamCmd(msg.Stream)
+			}
+		}
+		m.cmdLogPane.AddEntry("  │ " + msg.Line)
+		return m, listenStreamCmd(msg.Stream)
+
+	case streamFinishedMsg:
+		if m.activeStream == msg.Stream {
+			m.activeStream = nil
+			m.statusMsg = ""
+			m.statusPane.ClearProgress()
+		}
+		return m.Update(msg.Msg)
+
+	case commitDraftLoadedMsg:
+		m.modal.SetCommitHistory(msg.History)
+		m.modal.SetCommitSigning(msg.Signing)
+		m.modal.SetCommitDraft(msg.Subject, msg.Body)
+		return m, nil
+
+	case commitEditorReadyMsg:
+		return m, runCommitEditorCmd(msg.Editor, msg.Path)
+
+	case commitEditorDoneMsg:
+		if msg.Err != nil {
+			m.modal.OpenError(msg.Err.Error())
+			return m, nil
+		}
+		if m.modal.Type() == components.ModalCommit {
+			m.modal.SetCommitMessage(msg.Message)
+		}
+		return m, nil
+
+	case undoRecordedMsg:
+		m.journal.record(msg.Entry)
+		return m.Update(msg.Result)
+
+	case patchRewrittenMsg:
+		res, ok := msg.Msg.(gitResultMsg)
+		if recorded, isRecorded := msg.Msg.(undoRecordedMsg); isRecorded {
+			res, ok = recorded.Result, true
+		}
+		// patch đã được gỡ khỏi commit gốc (kể cả khi commit mới thất bại) thì vùng
+		// chọn không còn khớp với lịch sử
+		var moved *git.PatchMovedError
+		if ok && (res.Err == nil || errors.As(res.Err, &moved)) {
+			m.customPatch.Reset()
+		}
+		return m.Update(msg.Msg)
+
+	case undoPlannedMsg:
+		plan := msg
+		m.modal.OpenConfirm(plan.Preview, func() tea.Cmd {
+			return m.queuedCmd("undo", applyUndoCmd(m.git, plan))
+		})
+		return m, nil
+
+	case undoAppliedMsg:
+		// Chỉ đánh dấu journal khi entry đến từ journal (không phải reflog fallback)
+		if msg.Result.Err == nil && !msg.FromReflog {
+			if msg.Redo {
+				m.journal.markRedone()
+			} else {
+				m.journal.markUndone()
+			}
+		}
+		return m.Update(msg.Result)
+
 	case startupFetchMsg:
 		// Handle startup fetch trigger
 		return m, tea.Batch(
@@ -373,6 +631,10 @@ func (m model) View() string {
 	var mainBox string
 	if m.inHunkView {
 		mainBox = m.hunkView.RenderBox(true, m.styles)
+	} else if m.inPatchView {
+		mainBox = m.patchView.RenderBox(m.focus == ui.PaneMain, m.styles)
+	} else if m.inCompareView {
+		mainBox = m.compareView.View(m.focus == ui.PaneMain)
 	} else if m.focus == ui.PaneMain && m.mainViewSource == ui.PaneFiles {
 		// Split view for Files: Unstaged + Staged
 		mainBox = m.renderSplitMainBox()
@@ -480,6 +742,8 @@ func (m *model) resizeComponents() {
 	m.diffView.SetSize(m.layout.MainWidth, m.layout.MainHeight)
 	m.splitDiffView.SetSize(m.layout.MainWidth, m.layout.MainHeight)
 	m.hunkView.SetSize(m.layout.MainWidth, m.layout.MainHeight)
+	m.patchView.SetSize(m.layout.MainWidth, m.layout.MainHeight)
+	m.compareView.SetSize(m.layout.MainWidth, m.layout.MainHeight)
 	m.cmdLogPane.SetSize(m.layout.MainWidth, m.layout.CmdLogHeight)
 }
 
@@ -492,6 +756,8 @@ func (m *model) refreshAllPanes() {
 	m.stashPane.SetFocus(m.focus == ui.PaneStash)
 	m.diffView.SetFocus(m.focus == ui.PaneMain)
 	m.hunkView.SetFocus(m.inHunkView)
+	m.patchView.SetFocus(m.inPatchView && m.focus == ui.PaneMain)
+	m.compareView.SetFocus(m.inCompareView && m.focus == ui.PaneMain)
 	m.cmdLogPane.SetFocus(m.focus == ui.PaneCmdLog)
 
 	// Refresh content
@@ -501,6 +767,8 @@ func (m *model) refreshAllPanes() {
 	m.stashPane.Refresh()
 	m.statusPane.Refresh()
 	m.hunkView.Refresh()
+	m.patchView.Refresh()
+	m.compareView.Refresh()
 	m.cmdLogPane.Refresh()
 }
 
@@ -514,25 +782,49 @@ func (m model) renderInfoBar() string {
 	var opts string
 	switch m.focus {
 	case ui.PaneFiles:
-		opts = "space: stage | a: all | c: commit | A: amend | d: discard"
+		opts = "space: stage | a: all | c: commit | A: amend | d: discard | s: stash | i: ignore | v: select | H: hunks | `: tree view"
+		if _, _, isDir := m.filesPane.SelectedDir(); isDir {
+			opts = "enter: fold | space: stage dir | d: discard dir | s: stash | i: ignore | v: select | c: commit | `: flat view"
+		}
+		if m.filesPane.HasSelection() {
+			opts = "space: stage/unstage | d: discard | s: stash | i: ignore | v: stop range | esc: clear selection"
+		}
 	case ui.PaneBranches:
-		opts = "space: checkout | n: new | d: delete | D: force delete"
+		opts = "space: checkout | n: new | R: rename | u: upstream | f: fast-forward | d/D: delete | W: compare"
 	case ui.PaneCommits:
-		opts = "[/]: commits/reflog | enter: view | r/R: undo"
+		opts = "[/]: commits/reflog | enter: view | space: checkout | r/R: reset | F: fixup | A: amend | S: autosquash | e/I: export/import patch | B: build patch | W: compare | z/Z: undo/redo"
 	case ui.PaneStash:
-		opts = "space: apply | p: pop | d: drop"
+		opts = "space: apply | p: pop | d: drop | W: compare"
 	case ui.PaneCmdLog:
 		opts = "j/k: scroll | g/G: top/bottom"
 	case ui.PaneMain:
 		if m.inHunkView {
 			opts = "space: stage/unstage | j/k: navigate | esc: exit"
+		} else if m.inPatchView {
+			opts = "space: toggle line/hunk/file | a: toggle hunk | m: patch options | j/k: navigate | esc: back to commits"
+		} else if m.inCompareView {
+			opts = "j/k: select file | d/u: page diff | s: swap sides | w/{/}/a/M/W/L: diff options | |: side-by-side | esc: back"
 		} else if m.mainViewSource == ui.PaneFiles {
-			opts = "tab: switch pane | j/k: scroll | d/u: page | g/G: top/bottom"
+			opts = "tab: switch pane | j/k: scroll | d/u: page | g/G: top/bottom | w: whitespace | {/}: context | a: algorithm | M: renames | W: word diff | L: LFS smudge | |: side-by-side"
 		} else {
-			opts = "j/k: scroll | d/u: page | g/G: top/bottom"
+			opts = "j/k: scroll | d/u: page | g/G: top/bottom | n/N: hunk | ]/[: file | l: files | w: whitespace | {/}: context | a: algorithm | M: renames | W: word diff | L: LFS smudge | |: side-by-side"
 		}
 	default:
-		opts = "tab: switch | p: pull | P: push | f: fetch | q: quit"
+		opts = "tab: switch | p: pull | P: push | F: force push | f: fetch | z/Z: undo/redo | q: quit"
+	}
+
+	if m.statusPane.IsDetached() {
+		opts = "b: branch here | -: back to branch | " + opts
+	}
+	if state := m.statusPane.RepoState(); state.InProgress() {
+		keys := "X: abort | "
+		if state.CanSkip() {
+			keys = "S: skip | " + keys
+		}
+		if state.CanContinue() {
+			keys = "C: continue | " + keys
+		}
+		opts = keys + opts
 	}
 
 	left := optStyle.Render(opts)
@@ -541,6 +833,14 @@ func (m model) renderInfoBar() string {
 	var right string
 	if m.statusMsg != "" {
 		right = infoStyle.Render(m.statusMsg)
+	} else if queued := m.queueSummary(); queued != "" {
+		right = infoStyle.Render(queued)
+	} else if running := m.inflight.summary(); running != "" {
+		right = infoStyle.Render(running)
+	} else if m.compareFrom != "" && !m.inCompareView {
+		right = infoStyle.Render("compare from " + m.compareFrom + " (W on another ref)")
+	} else if !m.customPatch.IsEmpty() {
+		right = infoStyle.Render(fmt.Sprintf("patch: %d lines from %d commits (B to edit)", m.customPatch.LineCount(), len(m.customPatch.Commits())))
 	} else if m.lastGitCmd != "" {
 		right = dimStyle.Render(m.lastGitCmd)
 	} else {
@@ -562,36 +862,71 @@ func (m model) renderSplitMainBox() string {
 	return m.splitDiffView.View()
 }
 
+// loadMainDiff load lại diff đang hiển thị trong main view (sau khi đổi tuỳ chọn diff)
+func (m model) loadMainDiff() tea.Cmd {
+	switch m.mainViewSource {
+	case ui.PaneFiles:
+		item, _, found := m.filesPane.SelectedItem()
+		if !found {
+			return nil
+		}
+		return m.trackedCmd("diff", "diff "+item.Path, func(r git.Runner) tea.Cmd {
+			return loadSplitDiffCmd(r, item.Path, m.diffOpts)
+		})
+	case ui.PaneCommits:
+		return m.loadCommitDiff()
+	case ui.PaneBranches:
+		return m.loadBranchDiff()
+	case ui.PaneStash:
+		return m.loadStashDiff()
+	}
+	return nil
+}
+
 // loadDiffForCurrentPane loads diff based on current focus and selection
 func (m model) loadDiffForCurrentPane() tea.Cmd {
 	switch m.focus {
 	case ui.PaneFiles:
+		if dir, staged, isDir := m.filesPane.SelectedDir(); isDir {
+			// git diff -- <dir> cho mọi file tracked bên dưới
+			return m.trackedCmd("diff", "diff "+dir.Path, func(r git.Runner) tea.Cmd {
+				return loadDiffCmd(r, dir.Path, staged, m.diffOpts)
+			})
+		}
 		item, staged, found := m.filesPane.SelectedItem()
 		if !found {
 			return func() tea.Msg { return diffLoadedMsg{Diff: "(no file selected)"} }
 		}
-		return loadDiffCmd(m.git, item.Path, staged)
+		return m.trackedCmd("diff", "diff "+item.Path, func(r git.Runner) tea.Cmd {
+			return loadDiffCmd(r, item.Path, staged, m.diffOpts)
+		})
 
 	case ui.PaneCommits:
 		commit, found := m.commitsPane.SelectedCommit()
 		if !found {
 			return nil
 		}
-		return loadShowCommitCmd(m.git, commit.Hash)
+		return m.trackedCmd("diff", "show "+shortHash(commit.Hash), func(r git.Runner) tea.Cmd {
+			return loadShowCommitCmd(r, commit.Hash, m.diffOpts)
+		})
 
 	case ui.PaneBranches:
 		branch, found := m.branchesPane.SelectedBranch()
 		if !found {
 			return nil
 		}
-		return loadBranchDiffCmd(m.git, branch.Name)
+		return m.trackedCmd("diff", "diff "+branch.Name, func(r git.Runner) tea.Cmd {
+			return loadBranchDiffCmd(r, branch.Name, m.diffOpts)
+		})
 
 	case ui.PaneStash:
 		entry, found := m.stashPane.SelectedEntry()
 		if !found {
 			return nil
 		}
-		return loadStashDiffCmd(m.git, entry.Ref)
+		return m.trackedCmd("diff", "show "+entry.Ref, func(r git.Runner) tea.Cmd {
+			return loadStashDiffCmd(r, entry.Ref, m.diffOpts)
+		})
 
 	default:
 		return nil
@@ -604,7 +939,9 @@ func (m model) loadHunksForCurrentFile() tea.Cmd {
 	if !found {
 		return nil
 	}
-	return loadHunksCmd(m.git, item.Path, staged)
+	return m.trackedCmd("hunks", "diff "+item.Path, func(r git.Runner) tea.Cmd {
+		return loadHunksCmd(r, item.Path, staged)
+	})
 }
 
 // executeAutoFetchCmd executes background auto fetch using the background manager
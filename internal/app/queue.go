@@ -0,0 +1,95 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"gitzen/internal/git"
+)
+
+// queuedCmd chạy cmd qua operation queue của background manager để các thao tác
+// thay đổi repository (stage, commit, checkout...) không chạy chồng lên nhau.
+// Lệnh stream giữ chỗ trong queue tới khi process git kết thúc.
+func (m model) queuedCmd(desc string, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	queue := m.backgroundManager.Queue()
+	run := func() tea.Msg {
+		release := queue.Acquire(desc)
+		return afterStream(cmd(), func(msg tea.Msg) tea.Msg {
+			release()
+			return msg
+		})
+	}
+	if m.inflight.startTicking() {
+		return tea.Batch(run, inflightTickCmd())
+	}
+	return run
+}
+
+// journaled ghi undo journal cho thao tác di chuyển HEAD và chạy nó qua queue
+func (m model) journaled(desc, resetMode string, cmd tea.Cmd) tea.Cmd {
+	return m.queuedCmd(desc, journaledCmd(m.git, desc, resetMode, cmd))
+}
+
+// patchRewrite chạy lệnh sửa lại commit theo custom patch như journaled; custom patch
+// được xoá khi có kết quả thành công (patchRewrittenMsg)
+func (m model) patchRewrite(desc string, cmd tea.Cmd) tea.Cmd {
+	return m.queuedCmd(desc, patchRewriteCmd(journaledCmd(m.git, desc, "soft", cmd)))
+}
+
+// queueSummary mô tả thao tác đang chạy/đang chờ cho info bar, rỗng nếu queue rảnh
+func (m model) queueSummary() string {
+	running, pending := m.backgroundManager.Queue().Snapshot()
+	if running == nil && len(pending) == 0 {
+		return ""
+	}
+	s := ""
+	if running != nil {
+		if time.Since(running.Started) < inflightShowAfter && len(pending) == 0 {
+			return ""
+		}
+		s = fmt.Sprintf("▶ %s (%.1fs)", running.Desc, time.Since(running.Started).Seconds())
+	}
+	if len(pending) > 0 {
+		if s != "" {
+			s += " · "
+		}
+		s += fmt.Sprintf("%d queued: %s", len(pending), pending[0].Desc)
+		if len(pending) > 1 {
+			s += ", …"
+		}
+	}
+	return s
+}
+
+// indexLockMsg báo lệnh thất bại vì index.lock tồn tại
+type indexLockMsg struct {
+	Lock  git.IndexLock
+	Found bool
+	Err   error // lỗi gốc của lệnh git
+}
+
+// checkIndexLockCmd kiểm tra index.lock sau khi lệnh thất bại với "index.lock: File exists"
+func checkIndexLockCmd(r git.Runner, cause error) tea.Cmd {
+	return func() tea.Msg {
+		lock, found, err := r.CheckIndexLock()
+		if err != nil {
+			return gitResultMsg{Err: fmt.Errorf("%v\n\n%w", cause, err)}
+		}
+		return indexLockMsg{Lock: lock, Found: found, Err: cause}
+	}
+}
+
+// removeIndexLockCmd xoá index.lock stale
+func removeIndexLockCmd(r git.Runner, force bool) tea.Cmd {
+	return func() tea.Msg {
+		if err := r.RemoveIndexLock(force); err != nil {
+			return gitResultMsg{Err: err}
+		}
+		return gitResultMsg{Cmd: "rm .git/index.lock", Result: "Removed stale index.lock, retry the last action"}
+	}
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"gitzen/internal/git"
+)
+
+// streamStartedMsg báo một lệnh git dạng stream đã khởi chạy.
+// Finish block cho tới khi lệnh kết thúc và trả về msg kết quả.
+type streamStartedMsg struct {
+	Stream *git.Stream
+	Cmd    string
+	Finish func() tea.Msg
+}
+
+// streamLineMsg là một dòng output (stdout/stderr) của lệnh đang chạy
+type streamLineMsg struct {
+	Stream *git.Stream
+	Line   string
+}
+
+// streamFinishedMsg báo lệnh stream đã kết thúc, Msg là kết quả cần xử lý tiếp
+type streamFinishedMsg struct {
+	Stream *git.Stream
+	Msg    tea.Msg
+}
+
+// streamCmd khởi chạy lệnh stream; finish chuyển stdout/err thành msg kết quả (thường là gitResultMsg)
+func streamCmd(cmd string, start func() (*git.Stream, error), finish func(out string, err error) tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		s, err := start()
+		if err != nil {
+			return gitResultMsg{Cmd: cmd, Err: err}
+		}
+		return streamStartedMsg{
+			Stream: s,
+			Cmd:    cmd,
+			Finish: func() tea.Msg {
+				return finish(s.Wait())
+			},
+		}
+	}
+}
+
+// afterStream áp dụng then lên msg kết quả của lệnh; với lệnh stream thì áp dụng khi
+// stream kết thúc, kể cả khi Finish bắt đầu stream nối tiếp (e.g. commit rồi autosquash)
+func afterStream(msg tea.Msg, then func(tea.Msg) tea.Msg) tea.Msg {
+	started, ok := msg.(streamStartedMsg)
+	if !ok {
+		return then(msg)
+	}
+	finish := started.Finish
+	started.Finish = func() tea.Msg {
+		return afterStream(finish(), then)
+	}
+	return started
+}
+
+// listenStreamCmd đọc dòng output tiếp theo, trả về nil khi stream đóng
+func listenStreamCmd(s *git.Stream) tea.Cmd {
+	return func() tea.Msg {
+		line, ok := <-s.Lines()
+		if !ok {
+			return nil
+		}
+		return streamLineMsg{Stream: s, Line: line}
+	}
+}
+
+// waitStreamCmd chờ lệnh stream kết thúc
+func waitStreamCmd(msg streamStartedMsg) tea.Cmd {
+	return func() tea.Msg {
+		return streamFinishedMsg{Stream: msg.Stream, Msg: msg.Finish()}
+	}
+}
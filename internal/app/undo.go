@@ -0,0 +1,321 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"gitzen/internal/git"
+)
+
+// undoKind phân loại entry trong action journal
+type undoKind int
+
+const (
+	undoHeadMove     undoKind = iota // commit, amend, reset, checkout, merge, rebase...
+	undoBranchDelete                 // branch delete (không nằm trong HEAD reflog)
+)
+
+// undoEntry ghi lại một thao tác gitzen đã thực hiện, đủ để undo và redo
+type undoEntry struct {
+	kind        undoKind
+	description string
+
+	// undoHeadMove
+	before    git.HeadState
+	after     git.HeadState
+	resetMode string // soft/mixed cho các thao tác reset, rỗng = keep
+	// reflogAction đánh dấu reflog của lệnh undo dựng từ reflog, để lần undo sau đi tiếp về trước
+	reflogAction string
+
+	// undoBranchDelete
+	branch     string
+	branchHash string
+}
+
+// undoJournal là action journal của gitzen, undone entries ở cuối slice tạo thành redo stack
+type undoJournal struct {
+	entries []undoEntry
+	undone  int
+}
+
+func newUndoJournal() *undoJournal {
+	return &undoJournal{}
+}
+
+// record thêm entry mới, xoá redo stack
+func (j *undoJournal) record(e undoEntry) {
+	j.entries = append(j.entries[:len(j.entries)-j.undone], e)
+	j.undone = 0
+}
+
+func (j *undoJournal) isEmpty() bool {
+	return len(j.entries) == 0
+}
+
+func (j *undoJournal) nextUndo() (undoEntry, bool) {
+	idx := len(j.entries) - j.undone - 1
+	if idx < 0 {
+		return undoEntry{}, false
+	}
+	return j.entries[idx], true
+}
+
+func (j *undoJournal) nextRedo() (undoEntry, bool) {
+	if j.undone == 0 {
+		return undoEntry{}, false
+	}
+	return j.entries[len(j.entries)-j.undone], true
+}
+
+func (j *undoJournal) markUndone() {
+	if j.undone < len(j.entries) {
+		j.undone++
+	}
+}
+
+func (j *undoJournal) markRedone() {
+	if j.undone > 0 {
+		j.undone--
+	}
+}
+
+// undoRecordedMsg mang entry journal cùng kết quả của thao tác gốc
+type undoRecordedMsg struct {
+	Entry  undoEntry
+	Result gitResultMsg
+}
+
+// undoPlannedMsg chứa kế hoạch undo/redo để preview trước khi chạy
+type undoPlannedMsg struct {
+	Entry      undoEntry
+	Redo       bool
+	FromReflog bool
+	Preview    string
+}
+
+// undoAppliedMsg báo undo/redo đã chạy xong
+type undoAppliedMsg struct {
+	Entry      undoEntry
+	Redo       bool
+	FromReflog bool
+	Result     gitResultMsg
+}
+
+// journaledCmd chạy cmd và ghi lại vị trí HEAD trước/sau vào journal nếu thành công
+func journaledCmd(r git.Runner, description, resetMode string, cmd tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		before, beforeErr := r.CurrentHeadState()
+		msg := cmd()
+		if beforeErr != nil {
+			return msg
+		}
+		// Lệnh stream (e.g. commit có hook): ghi journal sau khi lệnh kết thúc
+		return afterStream(msg, func(msg tea.Msg) tea.Msg {
+			return recordHeadMove(r, before, description, resetMode, msg)
+		})
+	}
+}
+
+// recordHeadMove tạo undoRecordedMsg nếu thao tác thành công và HEAD đã thay đổi
+func recordHeadMove(r git.Runner, before git.HeadState, description, resetMode string, msg tea.Msg) tea.Msg {
+	res, ok := msg.(gitResultMsg)
+	if !ok || res.Err != nil {
+		return msg
+	}
+	after, err := r.CurrentHeadState()
+	if err != nil || after == before {
+		return msg
+	}
+	return undoRecordedMsg{
+		Entry: undoEntry{
+			kind:        undoHeadMove,
+			description: description,
+			before:      before,
+			after:       after,
+			resetMode:   resetMode,
+		},
+		Result: res,
+	}
+}
+
+// journaledDeleteBranchCmd xoá branch và ghi lại hash để có thể khôi phục
+func journaledDeleteBranchCmd(r git.Runner, name string, force bool) tea.Cmd {
+	return func() tea.Msg {
+		hash, hashErr := r.BranchHash(name)
+		msg := deleteBranchCmd(r, name, force)()
+		res, ok := msg.(gitResultMsg)
+		if hashErr != nil || !ok || res.Err != nil {
+			return msg
+		}
+		return undoRecordedMsg{
+			Entry: undoEntry{
+				kind:        undoBranchDelete,
+				description: "delete branch " + name,
+				branch:      name,
+				branchHash:  hash,
+			},
+			Result: res,
+		}
+	}
+}
+
+// planUndoCmd chuẩn bị undo (redo=false) hoặc redo, kiểm tra working tree không bị ghi đè
+func planUndoCmd(r git.Runner, entry undoEntry, found, redo bool) tea.Cmd {
+	return func() tea.Msg {
+		fromReflog := false
+		if !found {
+			if redo {
+				return errMsg("Nothing to redo")
+			}
+			var err error
+			entry, err = undoEntryFromReflog(r)
+			if err != nil {
+				return errMsg(err.Error())
+			}
+			fromReflog = true
+		}
+
+		if entry.kind == undoHeadMove {
+			target := entry.before
+			if redo {
+				target = entry.after
+			}
+			// soft/mixed reset không đụng tới working tree
+			if entry.resetMode == "" {
+				clobbered, err := r.WouldClobber(target.Hash)
+				if err != nil {
+					return errMsg(err.Error())
+				}
+				if len(clobbered) > 0 {
+					return errMsg("Undo refused: local changes would be overwritten in " + strings.Join(clobbered, ", ") + ". Commit or stash them first.")
+				}
+			}
+		}
+
+		return undoPlannedMsg{Entry: entry, Redo: redo, FromReflog: fromReflog, Preview: describeUndo(entry, redo)}
+	}
+}
+
+// undoEntryFromReflog dựng entry từ reflog khi journal không có gì để undo
+func undoEntryFromReflog(r git.Runner) (undoEntry, error) {
+	out, err := r.Reflog()
+	if err != nil {
+		return undoEntry{}, err
+	}
+	action, ok := git.InterpretReflog(git.ParseReflog(out))
+	if !ok {
+		return undoEntry{}, fmt.Errorf("Nothing to undo")
+	}
+	current, err := r.CurrentHeadState()
+	if err != nil {
+		return undoEntry{}, err
+	}
+
+	before := git.HeadState{Branch: current.Branch, Hash: action.FromHash}
+	if action.Kind == "checkout" {
+		before.Branch = action.FromBranch
+	}
+	return undoEntry{
+		kind:         undoHeadMove,
+		description:  action.Description,
+		before:       before,
+		after:        current,
+		reflogAction: action.UndoReflogAction,
+	}, nil
+}
+
+// describeUndo mô tả những gì undo/redo sẽ làm
+func describeUndo(entry undoEntry, redo bool) string {
+	verb := "Undo"
+	if redo {
+		verb = "Redo"
+	}
+
+	if entry.kind == undoBranchDelete {
+		if redo {
+			return fmt.Sprintf("%s %s: delete branch %s again?", verb, entry.description, entry.branch)
+		}
+		return fmt.Sprintf("%s %s: recreate branch %s at %s?", verb, entry.description, entry.branch, shortHash(entry.branchHash))
+	}
+
+	from, to := entry.after, entry.before
+	if redo {
+		from, to = entry.before, entry.after
+	}
+	var steps []string
+	if to.Branch != from.Branch {
+		if to.Branch == "" {
+			steps = append(steps, "checkout "+shortHash(to.Hash)+" (detached)")
+		} else {
+			steps = append(steps, "checkout "+to.Branch)
+		}
+	}
+	if to.Branch != "" && !strings.HasPrefix(from.Hash, to.Hash) && !strings.HasPrefix(to.Hash, from.Hash) {
+		mode := entry.resetMode
+		if mode == "" {
+			mode = "keep"
+		}
+		steps = append(steps, fmt.Sprintf("reset --%s %s to %s", mode, to.Branch, shortHash(to.Hash)))
+	}
+	if len(steps) == 0 {
+		steps = append(steps, "nothing changes")
+	}
+	return fmt.Sprintf("%s %s: %s?", verb, entry.description, strings.Join(steps, ", then "))
+}
+
+// applyUndoCmd thực hiện undo/redo đã được xác nhận
+func applyUndoCmd(r git.Runner, plan undoPlannedMsg) tea.Cmd {
+	return func() tea.Msg {
+		entry := plan.Entry
+		verb := "undo"
+		if plan.Redo {
+			verb = "redo"
+		}
+
+		var cmd string
+		var err error
+		switch entry.kind {
+		case undoBranchDelete:
+			if plan.Redo {
+				cmd = "git branch -D " + entry.branch
+				err = r.DeleteBranchForce(entry.branch)
+			} else {
+				cmd = fmt.Sprintf("git branch %s %s", entry.branch, shortHash(entry.branchHash))
+				err = r.CreateBranchAt(entry.branch, entry.branchHash)
+			}
+		default:
+			target := entry.before
+			if plan.Redo {
+				target = entry.after
+			}
+			cmd = fmt.Sprintf("%s: restore %s", verb, describeHeadState(target))
+			restorer := r
+			if !plan.Redo && entry.reflogAction != "" {
+				restorer = r.WithReflogAction(entry.reflogAction)
+			}
+			err = restorer.RestoreHeadState(target, entry.resetMode)
+		}
+
+		result := gitResultMsg{Cmd: cmd, Err: err}
+		if err == nil {
+			result.Result = strings.ToUpper(verb[:1]) + verb[1:] + "ne " + entry.description
+		}
+		return undoAppliedMsg{Entry: entry, Redo: plan.Redo, FromReflog: plan.FromReflog, Result: result}
+	}
+}
+
+func describeHeadState(s git.HeadState) string {
+	if s.Branch == "" {
+		return shortHash(s.Hash) + " (detached)"
+	}
+	return s.Branch + " at " + shortHash(s.Hash)
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -16,8 +16,8 @@ type backgroundTickMsg time.Time
 // Manager quản lý các hoạt động background với timer và serialization
 type Manager struct {
 	mu          sync.Mutex
-	running     bool
 	gitRunner   git.Runner
+	queue       *OpQueue     // Serialize mọi thao tác thay đổi repository
 	fileWatcher *FileWatcher // New: File system watcher
 }
 
@@ -25,9 +25,15 @@ type Manager struct {
 func New(gitRunner git.Runner) *Manager {
 	return &Manager{
 		gitRunner: gitRunner,
+		queue:     NewOpQueue(),
 	}
 }
 
+// Queue trả về hàng đợi thao tác dùng chung giữa UI và background
+func (m *Manager) Queue() *OpQueue {
+	return m.queue
+}
+
 // Start khởi tạo background timer với context để có thể hủy bỏ
 func (m *Manager) Start(ctx context.Context) tea.Cmd {
 	return m.backgroundTickCmd(ctx)
@@ -38,9 +44,12 @@ func (m *Manager) ExecuteIfSafe(fn func() error) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if m.running {
-		return nil // Skip if already running
+	// Skip nếu queue đang bận (thao tác của người dùng được ưu tiên)
+	release, ok := m.queue.TryAcquire("background fetch")
+	if !ok {
+		return nil
 	}
+	defer release()
 
 	// Check if working directory is clean before proceeding
 	clean, err := m.gitRunner.IsWorkingDirectoryClean()
@@ -51,9 +60,6 @@ func (m *Manager) ExecuteIfSafe(fn func() error) error {
 		return nil // Skip if working directory has changes
 	}
 
-	m.running = true
-	defer func() { m.running = false }()
-
 	return fn()
 }
 
@@ -0,0 +1,108 @@
+package background
+
+import (
+	"sync"
+	"time"
+)
+
+// Operation là một thao tác thay đổi repository (stage, commit, checkout, fetch...)
+type Operation struct {
+	ID      int
+	Desc    string
+	Queued  time.Time
+	Started time.Time // zero khi còn đang chờ
+}
+
+// OpQueue chạy tuần tự các thao tác thay đổi repository theo thứ tự FIFO,
+// tránh hai lệnh git cùng ghi index/refs (lỗi "index.lock exists").
+type OpQueue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	nextID  int
+	pending []*Operation
+	running *Operation
+}
+
+// NewOpQueue tạo OpQueue rỗng
+func NewOpQueue() *OpQueue {
+	q := &OpQueue{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// Acquire chờ tới lượt thao tác desc rồi đánh dấu là đang chạy.
+// Caller phải gọi release (gọi nhiều lần cũng an toàn) khi thao tác kết thúc.
+func (q *OpQueue) Acquire(desc string) (release func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.nextID++
+	op := &Operation{ID: q.nextID, Desc: desc, Queued: time.Now()}
+	q.pending = append(q.pending, op)
+
+	for q.running != nil || q.pending[0] != op {
+		q.cond.Wait()
+	}
+	q.start(op)
+	return q.releaseFunc(op)
+}
+
+// TryAcquire chỉ chạy khi queue đang rảnh, dùng cho thao tác nền có thể bỏ qua (auto fetch)
+func (q *OpQueue) TryAcquire(desc string) (release func(), ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.running != nil || len(q.pending) > 0 {
+		return nil, false
+	}
+	q.nextID++
+	op := &Operation{ID: q.nextID, Desc: desc, Queued: time.Now()}
+	q.pending = append(q.pending, op)
+	q.start(op)
+	return q.releaseFunc(op), true
+}
+
+// start chuyển op đầu hàng đợi sang running, q.mu phải đang được giữ
+func (q *OpQueue) start(op *Operation) {
+	q.pending = q.pending[1:]
+	op.Started = time.Now()
+	q.running = op
+}
+
+func (q *OpQueue) releaseFunc(op *Operation) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			q.mu.Lock()
+			if q.running == op {
+				q.running = nil
+			}
+			q.mu.Unlock()
+			q.cond.Broadcast()
+		})
+	}
+}
+
+// Snapshot trả về thao tác đang chạy (nil nếu rảnh) và các thao tác đang chờ
+func (q *OpQueue) Snapshot() (*Operation, []Operation) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var running *Operation
+	if q.running != nil {
+		op := *q.running
+		running = &op
+	}
+	pending := make([]Operation, len(q.pending))
+	for i, op := range q.pending {
+		pending[i] = *op
+	}
+	return running, pending
+}
+
+// Busy kiểm tra có thao tác nào đang chạy hoặc đang chờ không
+func (q *OpQueue) Busy() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.running != nil || len(q.pending) > 0
+}
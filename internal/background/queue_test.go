@@ -0,0 +1,77 @@
+package background
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOpQueueSerializesInOrder(t *testing.T) {
+	q := NewOpQueue()
+	release := q.Acquire("first")
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	for _, desc := range []string{"second", "third"} {
+		wg.Add(1)
+		go func(desc string) {
+			defer wg.Done()
+			r := q.Acquire(desc)
+			mu.Lock()
+			order = append(order, desc)
+			mu.Unlock()
+			r()
+		}(desc)
+		// Đợi goroutine vào hàng đợi để thứ tự FIFO xác định
+		waitFor(t, func() bool {
+			_, pending := q.Snapshot()
+			return len(pending) > 0 && pending[len(pending)-1].Desc == desc
+		})
+	}
+
+	running, pending := q.Snapshot()
+	if running == nil || running.Desc != "first" {
+		t.Fatalf("running = %+v, want first", running)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("pending = %d, want 2", len(pending))
+	}
+
+	release()
+	release() // gọi lại không được ảnh hưởng thao tác khác
+	wg.Wait()
+
+	if len(order) != 2 || order[0] != "second" || order[1] != "third" {
+		t.Errorf("order = %v, want [second third]", order)
+	}
+	if q.Busy() {
+		t.Error("queue should be idle after all releases")
+	}
+}
+
+func TestOpQueueTryAcquire(t *testing.T) {
+	q := NewOpQueue()
+	release, ok := q.TryAcquire("auto fetch")
+	if !ok {
+		t.Fatal("TryAcquire on idle queue should succeed")
+	}
+	if _, ok := q.TryAcquire("another"); ok {
+		t.Error("TryAcquire on busy queue should fail")
+	}
+	release()
+	if q.Busy() {
+		t.Error("queue should be idle after release")
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
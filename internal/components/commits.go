@@ -21,10 +21,11 @@ const (
 type CommitsPane struct {
 	BasePane
 
-	mode    CommitsMode
-	commits []git.CommitItem
-	reflog  []git.ReflogEntry
-	styles  ui.Styles
+	mode       CommitsMode
+	commits    []git.CommitItem
+	reflog     []git.ReflogEntry
+	signatures map[string]git.SignatureStatus
+	styles     ui.Styles
 }
 
 // NewCommitsPane tạo CommitsPane mới
@@ -77,6 +78,38 @@ func (p *CommitsPane) SetReflogData(entries []git.ReflogEntry) {
 	p.refreshContent()
 }
 
+// SetSignatures cập nhật trạng thái chữ ký (%G?) theo short hash
+func (p *CommitsPane) SetSignatures(sigs map[string]git.SignatureStatus) {
+	p.signatures = sigs
+	p.refreshContent()
+}
+
+// hasSignedCommits kiểm tra có commit nào được ký (để chừa cột badge)
+func (p *CommitsPane) hasSignedCommits() bool {
+	for _, status := range p.signatures {
+		if status != git.SignatureNone {
+			return true
+		}
+	}
+	return false
+}
+
+// signatureBadge render badge chữ ký cho commit, khoảng trắng nếu không ký
+func (p *CommitsPane) signatureBadge(hash string) string {
+	status := p.signatures[hash]
+	icon := p.styles.Icons.GetSignatureIcon(string(status))
+	switch status {
+	case git.SignatureGood:
+		return p.styles.StagedStyle.Render(icon) + " "
+	case git.SignatureBad:
+		return p.styles.ErrorStyle.Render(icon) + " "
+	case git.SignatureUnknown:
+		return p.styles.WarningStyle.Render(icon) + " "
+	default:
+		return "  "
+	}
+}
+
 // Commits returns commits list
 func (p *CommitsPane) Commits() []git.CommitItem {
 	return p.commits
@@ -173,19 +206,25 @@ func (p *CommitsPane) refreshCommits() {
 		return
 	}
 
+	showBadges := p.hasSignedCommits()
+
 	var lines []string
 	for i, c := range p.commits {
 		selected := p.IsFocused() && i == p.SelectedIndex()
 
-		// Format: hash message
+		// Format: [badge] hash message
+		badge := ""
+		if showBadges {
+			badge = p.signatureBadge(c.Hash)
+		}
 		hashPart := c.Hash
 		msgPart := c.Message
 
 		if selected {
-			line := p.styles.SelectedStyle.Render(hashPart + " " + msgPart)
+			line := badge + p.styles.SelectedStyle.Render(hashPart+" "+msgPart)
 			lines = append(lines, line)
 		} else {
-			line := p.styles.HashStyle.Render(hashPart) + " " + msgPart
+			line := badge + p.styles.HashStyle.Render(hashPart) + " " + msgPart
 			lines = append(lines, line)
 		}
 	}
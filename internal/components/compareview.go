@@ -0,0 +1,229 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"gitzen/internal/git"
+	"gitzen/internal/tui"
+	"gitzen/internal/ui"
+)
+
+// CompareView hiển thị so sánh hai ref: danh sách (dòng commits + các file) ở trên,
+// diff của file đang chọn (hoặc danh sách commit) ở dưới
+type CompareView struct {
+	list BasePane
+	diff BasePane
+
+	comparison git.Comparison
+	loaded     bool
+	diffPath   string // file của diff đang hiển thị
+	diffText   string
+	options    string // tuỳ chọn diff đang bật
+	sideBySide bool
+
+	width, height int
+	diffStyler    tui.DiffStyler
+	styles        ui.Styles
+}
+
+func NewCompareView(styles ui.Styles) *CompareView {
+	return &CompareView{
+		list:       NewBasePane(ui.PaneMain),
+		diff:       NewBasePane(ui.PaneMain),
+		diffStyler: tui.DefaultDiffStyler(),
+		styles:     styles,
+	}
+}
+
+// SetSize chia main area: 1/3 cho danh sách, còn lại cho diff
+func (v *CompareView) SetSize(width, height int) {
+	v.width = width
+	v.height = height
+	listH := max(3, height/3)
+	v.list.SetSize(width, listH)
+	v.diff.SetSize(width, max(0, height-listH))
+	if v.diffPath != "" {
+		v.renderDiff()
+	}
+}
+
+// ToggleSideBySide bật/tắt hiển thị hai cột cho diff file, trả về trạng thái mới
+func (v *CompareView) ToggleSideBySide() bool {
+	v.sideBySide = !v.sideBySide
+	if v.diffPath != "" {
+		v.renderDiff()
+	}
+	return v.sideBySide
+}
+
+func (v *CompareView) renderDiff() {
+	if v.sideBySide {
+		v.diff.SetContent(v.diffStyler.RenderSideBySide(v.diffText, v.diff.ContentWidth()))
+		return
+	}
+	v.diff.SetContent(v.diffStyler.Colorize(v.diffText))
+}
+
+func (v *CompareView) SetFocus(focused bool) {
+	v.list.SetFocus(focused)
+}
+
+// SetComparison hiển thị kết quả so sánh, chọn dòng commits
+func (v *CompareView) SetComparison(c git.Comparison) {
+	v.comparison = c
+	v.loaded = true
+	v.list.SetItemCount(len(c.Files) + 1)
+	v.list.SetCursor(0)
+	v.list.GotoTop()
+	v.Refresh()
+	v.showCommits()
+}
+
+// Comparison trả về kết quả so sánh đang hiển thị
+func (v *CompareView) Comparison() git.Comparison {
+	return v.comparison
+}
+
+// SetOptions đặt mô tả tuỳ chọn diff đang bật
+func (v *CompareView) SetOptions(options string) {
+	v.options = options
+}
+
+// SelectedFile trả về file đang chọn, false khi đang chọn dòng commits
+func (v *CompareView) SelectedFile() (git.CompareFile, bool) {
+	idx := v.list.SelectedIndex() - 1
+	if idx < 0 || idx >= len(v.comparison.Files) {
+		return git.CompareFile{}, false
+	}
+	return v.comparison.Files[idx], true
+}
+
+// SetFileDiff hiển thị diff của path nếu path vẫn đang được chọn
+func (v *CompareView) SetFileDiff(path, diff string) {
+	if f, ok := v.SelectedFile(); !ok || f.Path != path {
+		return
+	}
+	v.diffPath = path
+	if strings.TrimSpace(diff) == "" {
+		diff = "(no diff with current options)"
+	}
+	v.diffText = diff
+	v.renderDiff()
+	v.diff.GotoTop()
+}
+
+// Select cập nhật phần diff sau khi cursor đổi; trả về true khi cần load diff file
+func (v *CompareView) Select() bool {
+	v.Refresh()
+	if _, ok := v.SelectedFile(); ok {
+		return true
+	}
+	v.showCommits()
+	return false
+}
+
+func (v *CompareView) showCommits() {
+	v.diffPath = ""
+	c := v.comparison
+	if len(c.Commits) == 0 {
+		v.diff.SetContent(v.styles.DimStyle.Render(fmt.Sprintf("(no commits in %s that are not in %s)", c.To, c.From)))
+		return
+	}
+	lines := make([]string, len(c.Commits))
+	for i, commit := range c.Commits {
+		lines[i] = v.styles.HashStyle.Render(commit.Hash) + " " + commit.Subject
+	}
+	v.diff.SetContent(strings.Join(lines, "\n"))
+	v.diff.GotoTop()
+}
+
+func (v *CompareView) CursorUp()     { v.list.CursorUp() }
+func (v *CompareView) CursorDown()   { v.list.CursorDown() }
+func (v *CompareView) CursorTop()    { v.list.CursorTop() }
+func (v *CompareView) CursorBottom() { v.list.CursorBottom() }
+func (v *CompareView) PageUp()       { v.diff.PageUp() }
+func (v *CompareView) PageDown()     { v.diff.PageDown() }
+func (v *CompareView) ScrollUp(n int) {
+	v.diff.ScrollUp(n)
+}
+func (v *CompareView) ScrollDown(n int) {
+	v.diff.ScrollDown(n)
+}
+
+// Refresh render lại danh sách
+func (v *CompareView) Refresh() {
+	if !v.loaded {
+		v.list.SetContent(v.styles.DimStyle.Render("(loading comparison...)"))
+		return
+	}
+	c := v.comparison
+	cursor := v.list.SelectedIndex()
+	focused := v.list.IsFocused()
+
+	summary := fmt.Sprintf("%d commits in %s", len(c.Commits), c.To)
+	if c.Behind > 0 {
+		summary += fmt.Sprintf(" · %d only in %s", c.Behind, c.From)
+	}
+	lines := []string{v.renderRow(summary, focused && cursor == 0)}
+	for i, f := range c.Files {
+		name := f.Path
+		if f.OldPath != "" {
+			name = f.OldPath + " → " + f.Path
+		}
+		lines = append(lines, v.renderRow(v.statusStyle(f.Status).Render(f.Status)+" "+name, focused && cursor == i+1))
+	}
+	v.list.SetContent(strings.Join(lines, "\n"))
+}
+
+func (v *CompareView) renderRow(text string, selected bool) string {
+	if selected {
+		return v.styles.SelectedStyle.Render("> ") + text
+	}
+	return "  " + text
+}
+
+func (v *CompareView) statusStyle(status string) lipgloss.Style {
+	switch status {
+	case "A":
+		return v.styles.StagedStyle
+	case "D":
+		return v.styles.DeletedStyle
+	case "R", "C":
+		return v.styles.RenamedStyle
+	default:
+		return v.styles.ModifiedStyle
+	}
+}
+
+// View render hai box xếp dọc
+func (v *CompareView) View(focused bool) string {
+	c := v.comparison
+	title := "Compare " + c.From + ".." + c.To
+	if v.loaded {
+		title += fmt.Sprintf(" (%d files)", len(c.Files))
+	}
+	diffTitle := "Commits"
+	if v.diffPath != "" {
+		diffTitle = "Diff - " + v.diffPath
+	}
+	if label := joinLabels(v.options, sideBySideLabel(v.sideBySide, v.diff.ContentWidth())); label != "" {
+		diffTitle += " [" + label + "]"
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		v.list.RenderBox(title, v.list.ViewportView(), focused, v.styles),
+		v.diff.RenderBox(diffTitle, v.diff.ViewportView(), false, v.styles),
+	)
+}
+
+// Reset xoá kết quả so sánh (trước khi load so sánh mới)
+func (v *CompareView) Reset(from, to string) {
+	v.comparison = git.Comparison{From: from, To: to}
+	v.loaded = false
+	v.diffPath = ""
+	v.list.SetItemCount(0)
+	v.diff.SetContent("")
+	v.Refresh()
+}
@@ -1,10 +1,18 @@
 package components
 
 import (
+	"fmt"
+	"strings"
+
+	"gitzen/internal/git"
 	"gitzen/internal/tui"
 	"gitzen/internal/ui"
 )
 
+// virtualMargin là số dòng đọc thêm quanh cửa sổ khi tô màu ở virtual mode,
+// để ghép cặp dòng -/+ (intra-line) ở mép cửa sổ vẫn đúng
+const virtualMargin = 32
+
 // DiffContext represents what's being shown in the diff view
 type DiffContext int
 
@@ -28,6 +36,16 @@ type DiffView struct {
 	context  DiffContext
 	title    string // Dynamic title based on context
 	subtitle string // File path, commit hash, etc.
+	options  string // Tuỳ chọn diff đang bật (e.g. "-w U5"), hiện trong title
+
+	sideBySide bool // hiển thị hai cột old/new (fallback unified khi hẹp)
+
+	index git.DiffIndex // vị trí file/hunk của content, để nhảy
+
+	// Virtual mode: diff lớn được stream vào buffer, chỉ các dòng đang hiện được
+	// đọc và tô màu. top là dòng đầu cửa sổ.
+	buffer *git.DiffBuffer
+	top    int
 }
 
 // NewDiffView tạo DiffView mới
@@ -62,11 +80,248 @@ func (p *DiffView) SetContext(ctx DiffContext, subtitle string) {
 
 // SetDiff cập nhật nội dung diff với syntax highlighting
 func (p *DiffView) SetDiff(diff string) {
+	p.closeBuffer()
 	p.content = diff
-	p.SetContent(p.diffStyler.Colorize(diff))
+	p.index = git.IndexDiff(strings.Split(diff, "\n"))
+	p.render()
 	p.GotoTop()
 }
 
+// SetBuffer chuyển sang virtual mode với diff đang được stream; buffer cũ bị đóng
+func (p *DiffView) SetBuffer(buf *git.DiffBuffer, ctx DiffContext, subtitle string) {
+	p.SetContext(ctx, subtitle)
+	if p.buffer != buf {
+		p.closeBuffer()
+	}
+	p.content = ""
+	p.index = git.DiffIndex{}
+	p.buffer = buf
+	p.top = 0
+	p.render()
+}
+
+// Buffer trả về buffer của virtual mode, nil khi diff nằm hết trong bộ nhớ
+func (p *DiffView) Buffer() *git.DiffBuffer {
+	return p.buffer
+}
+
+// BufferUpdated render lại cửa sổ khi buffer nhận thêm dòng
+func (p *DiffView) BufferUpdated() {
+	if p.buffer != nil {
+		p.render()
+	}
+}
+
+func (p *DiffView) closeBuffer() {
+	if p.buffer != nil {
+		p.buffer.Close()
+		p.buffer = nil
+	}
+}
+
+// Close giải phóng buffer (process git, file tạm) khi thoát
+func (p *DiffView) Close() {
+	p.closeBuffer()
+}
+
+// SetSize cập nhật kích thước và render lại (side-by-side phụ thuộc độ rộng)
+func (p *DiffView) SetSize(width, height int) {
+	p.BasePane.SetSize(width, height)
+	p.render()
+}
+
+// ToggleSideBySide bật/tắt hiển thị hai cột, trả về trạng thái mới
+func (p *DiffView) ToggleSideBySide() bool {
+	p.sideBySide = !p.sideBySide
+	p.render()
+	return p.sideBySide
+}
+
+func (p *DiffView) render() {
+	if p.buffer != nil {
+		p.renderWindow()
+		return
+	}
+	if p.sideBySide {
+		p.SetContent(p.diffStyler.RenderSideBySide(p.content, p.ContentWidth()))
+		return
+	}
+	p.SetContent(p.diffStyler.Colorize(p.content))
+}
+
+// renderWindow đọc và tô màu các dòng trong cửa sổ hiện tại của buffer
+func (p *DiffView) renderWindow() {
+	h := p.ContentHeight()
+	p.top = min(p.top, max(0, p.buffer.Len()-h))
+	p.top = max(0, p.top)
+
+	start := max(0, p.top-virtualMargin)
+	lines := p.buffer.Lines(start, p.top-start+h+virtualMargin)
+	path := ""
+	if f, ok := p.buffer.FileAt(start); ok {
+		path = f.Path
+	}
+	colored := p.diffStyler.ColorizeLines(path, p.buffer.InHunk(start), lines)
+	from := min(p.top-start, len(colored))
+	to := min(from+h, len(colored))
+	p.SetContent(strings.Join(colored[from:to], "\n"))
+	p.viewport.GotoTop()
+}
+
+// scrollTo đặt dòng đầu (virtual mode) hoặc offset viewport tới line
+func (p *DiffView) scrollTo(line int) {
+	if p.buffer != nil {
+		p.top = line
+		p.renderWindow()
+		return
+	}
+	p.viewport.SetYOffset(line)
+}
+
+func (p *DiffView) currentLine() int {
+	if p.buffer != nil {
+		return p.top
+	}
+	return p.viewport.YOffset
+}
+
+// ScrollUp cuộn lên lines dòng
+func (p *DiffView) ScrollUp(lines int) {
+	if p.buffer == nil {
+		p.BasePane.ScrollUp(lines)
+		return
+	}
+	p.scrollTo(p.top - lines)
+}
+
+// ScrollDown cuộn xuống lines dòng
+func (p *DiffView) ScrollDown(lines int) {
+	if p.buffer == nil {
+		p.BasePane.ScrollDown(lines)
+		return
+	}
+	p.scrollTo(p.top + lines)
+}
+
+// PageUp cuộn lên một trang
+func (p *DiffView) PageUp() {
+	if p.buffer == nil {
+		p.BasePane.PageUp()
+		return
+	}
+	p.scrollTo(p.top - p.ContentHeight())
+}
+
+// PageDown cuộn xuống một trang
+func (p *DiffView) PageDown() {
+	if p.buffer == nil {
+		p.BasePane.PageDown()
+		return
+	}
+	p.scrollTo(p.top + p.ContentHeight())
+}
+
+// GotoTop cuộn đến đầu
+func (p *DiffView) GotoTop() {
+	if p.buffer == nil {
+		p.BasePane.GotoTop()
+		return
+	}
+	p.scrollTo(0)
+}
+
+// GotoBottom cuộn đến cuối (những gì đã nhận nếu còn đang stream)
+func (p *DiffView) GotoBottom() {
+	if p.buffer == nil {
+		p.BasePane.GotoBottom()
+		return
+	}
+	p.scrollTo(p.buffer.Len())
+}
+
+// diffNavigator là DiffIndex (content trong bộ nhớ) hoặc DiffBuffer (virtual mode)
+type diffNavigator interface {
+	NextHunk(line int) int
+	PrevHunk(line int) int
+	NextFile(line int) int
+	PrevFile(line int) int
+}
+
+func (p *DiffView) navigator() diffNavigator {
+	if p.buffer != nil {
+		return p.buffer
+	}
+	return p.index
+}
+
+// CanJump kiểm tra có nhảy theo dòng diff được không (side-by-side đổi số dòng hiển thị)
+func (p *DiffView) CanJump() bool {
+	return p.buffer != nil || !p.sideBySide || !tui.CanSideBySide(p.ContentWidth())
+}
+
+// JumpHunk nhảy tới hunk kế tiếp (delta > 0) hoặc trước đó, false khi không còn
+func (p *DiffView) JumpHunk(delta int) bool {
+	nav := p.navigator()
+	line := nav.PrevHunk(p.currentLine())
+	if delta > 0 {
+		line = nav.NextHunk(p.currentLine())
+	}
+	if line < 0 || !p.CanJump() {
+		return false
+	}
+	p.scrollTo(line)
+	return true
+}
+
+// JumpFile nhảy tới file kế tiếp (delta > 0) hoặc trước đó, false khi không còn
+func (p *DiffView) JumpFile(delta int) bool {
+	nav := p.navigator()
+	line := nav.PrevFile(p.currentLine())
+	if delta > 0 {
+		line = nav.NextFile(p.currentLine())
+	}
+	if line < 0 || !p.CanJump() {
+		return false
+	}
+	p.scrollTo(line)
+	return true
+}
+
+// Files trả về các file trong diff cùng dòng header của chúng
+func (p *DiffView) Files() []git.DiffAnchor {
+	if p.buffer != nil {
+		return p.buffer.Files()
+	}
+	return p.index.Files
+}
+
+// JumpToLine cuộn tới dòng line của diff (e.g. header file chọn từ danh sách)
+func (p *DiffView) JumpToLine(line int) bool {
+	if !p.CanJump() {
+		return false
+	}
+	p.scrollTo(line)
+	return true
+}
+
+// virtualLabel mô tả tiến độ virtual mode cho title, e.g. "120000 lines · 35%"
+func (p *DiffView) virtualLabel() string {
+	if p.buffer == nil {
+		return ""
+	}
+	n := p.buffer.Len()
+	label := fmt.Sprintf("%d lines", n)
+	if done, err := p.buffer.Done(); !done {
+		label += " · loading…"
+	} else if err != nil {
+		label += " · incomplete"
+	}
+	if n > 0 {
+		label += fmt.Sprintf(" · %d%%", min(100, (p.top+p.ContentHeight())*100/n))
+	}
+	return label
+}
+
 // SetDiffWithContext sets diff content with context info
 func (p *DiffView) SetDiffWithContext(diff string, ctx DiffContext, subtitle string) {
 	p.SetContext(ctx, subtitle)
@@ -75,6 +330,8 @@ func (p *DiffView) SetDiffWithContext(diff string, ctx DiffContext, subtitle str
 
 // Clear xóa nội dung
 func (p *DiffView) Clear() {
+	p.closeBuffer()
+	p.index = git.DiffIndex{}
 	p.content = ""
 	p.subtitle = ""
 	p.context = DiffContextNone
@@ -84,7 +341,7 @@ func (p *DiffView) Clear() {
 
 // HasContent kiểm tra có nội dung không
 func (p *DiffView) HasContent() bool {
-	return p.content != ""
+	return p.content != "" || p.buffer != nil
 }
 
 // Title returns dynamic title
@@ -97,12 +354,46 @@ func (p *DiffView) Subtitle() string {
 	return p.subtitle
 }
 
+// SetOptions đặt mô tả tuỳ chọn diff đang bật, rỗng khi dùng mặc định
+func (p *DiffView) SetOptions(options string) {
+	p.options = options
+}
+
 // FullTitle returns title with subtitle (lazygit style)
 func (p *DiffView) FullTitle() string {
+	title := p.title
 	if p.subtitle != "" {
-		return p.title + " - " + p.subtitle
+		title += " - " + p.subtitle
 	}
-	return p.title
+	sbs := sideBySideLabel(p.sideBySide, p.ContentWidth())
+	if p.sideBySide && p.buffer != nil {
+		sbs = "side-by-side: diff too large"
+	}
+	if label := joinLabels(p.options, sbs, p.virtualLabel()); label != "" {
+		title += " [" + label + "]"
+	}
+	return title
+}
+
+// sideBySideLabel mô tả chế độ hai cột cho title, rỗng khi tắt
+func sideBySideLabel(on bool, width int) string {
+	if !on {
+		return ""
+	}
+	if !tui.CanSideBySide(width) {
+		return "side-by-side: too narrow"
+	}
+	return "side-by-side"
+}
+
+func joinLabels(labels ...string) string {
+	var parts []string
+	for _, l := range labels {
+		if l != "" {
+			parts = append(parts, l)
+		}
+	}
+	return strings.Join(parts, " · ")
 }
 
 // View returns rendered content
@@ -1,26 +1,58 @@
 package components
 
 import (
+	"fmt"
 	"strings"
 
+	"github.com/charmbracelet/lipgloss"
+
 	"gitzen/internal/git"
 	"gitzen/internal/ui"
 )
 
-// FilesPane hiển thị staged và unstaged files
+// FilesPane hiển thị staged và unstaged files, dạng danh sách phẳng hoặc cây thư mục
 type FilesPane struct {
 	BasePane
 
 	stagedItems   []git.FileItem
 	unstagedItems []git.FileItem
 	styles        ui.Styles
+
+	// Tree view: cây staged rồi cây unstaged, thư mục đóng/mở được
+	treeView          bool
+	collapsedStaged   map[string]bool
+	collapsedUnstaged map[string]bool
+	rows              []fileRow
+
+	// Chọn nhiều dòng (v / shift+↑↓), lưu theo path để giữ qua các lần refresh
+	marked      map[fileRowKey]bool
+	rangeAnchor *fileRowKey // nil khi không đang kéo vùng chọn
+}
+
+// fileRowKey định danh một dòng qua các lần refresh: cùng path có thể ở cả staged và unstaged
+type fileRowKey struct {
+	path   string
+	staged bool
+}
+
+// fileRow là một dòng của pane: file, hoặc thư mục khi ở tree view
+type fileRow struct {
+	git.FileTreeRow
+	staged bool
+}
+
+func (r fileRow) key() fileRowKey {
+	return fileRowKey{path: r.Path, staged: r.staged}
 }
 
 // NewFilesPane tạo FilesPane mới
 func NewFilesPane(styles ui.Styles) *FilesPane {
 	return &FilesPane{
-		BasePane: NewBasePane(ui.PaneFiles),
-		styles:   styles,
+		BasePane:          NewBasePane(ui.PaneFiles),
+		styles:            styles,
+		collapsedStaged:   make(map[string]bool),
+		collapsedUnstaged: make(map[string]bool),
+		marked:            make(map[fileRowKey]bool),
 	}
 }
 
@@ -28,10 +60,229 @@ func NewFilesPane(styles ui.Styles) *FilesPane {
 func (p *FilesPane) SetData(staged, unstaged []git.FileItem) {
 	p.stagedItems = staged
 	p.unstagedItems = unstaged
-	p.SetItemCount(len(staged) + len(unstaged))
+	p.rebuildRows()
+	p.refreshContent()
+}
+
+// rebuildRows dựng lại các dòng theo chế độ hiển thị hiện tại
+func (p *FilesPane) rebuildRows() {
+	p.rows = p.rows[:0]
+	if !p.treeView {
+		for _, f := range p.stagedItems {
+			p.rows = append(p.rows, fileRow{FileTreeRow: git.FileTreeRow{Path: f.Path, Name: f.Path, Item: f}, staged: true})
+		}
+		for _, f := range p.unstagedItems {
+			p.rows = append(p.rows, fileRow{FileTreeRow: git.FileTreeRow{Path: f.Path, Name: f.Path, Item: f}})
+		}
+	} else {
+		for _, r := range git.BuildFileTree(p.stagedItems, p.collapsedStaged) {
+			p.rows = append(p.rows, fileRow{FileTreeRow: r, staged: true})
+		}
+		for _, r := range git.BuildFileTree(p.unstagedItems, p.collapsedUnstaged) {
+			p.rows = append(p.rows, fileRow{FileTreeRow: r})
+		}
+	}
+	p.SetItemCount(len(p.rows))
+
+	// Bỏ các dòng đã chọn không còn hiển thị (file đã commit/discard, thư mục bị đóng)
+	present := make(map[fileRowKey]bool, len(p.rows))
+	for _, r := range p.rows {
+		present[r.key()] = true
+	}
+	for k := range p.marked {
+		if !present[k] {
+			delete(p.marked, k)
+		}
+	}
+	if p.rangeAnchor != nil && !present[*p.rangeAnchor] {
+		p.rangeAnchor = nil
+	}
+}
+
+// ToggleRangeSelect bắt đầu chọn vùng từ dòng hiện tại, hoặc dừng kéo vùng (giữ các dòng đã chọn)
+func (p *FilesPane) ToggleRangeSelect() {
+	if p.rangeAnchor != nil {
+		p.rangeAnchor = nil
+		p.refreshContent()
+		return
+	}
+	p.StartRangeSelect()
+}
+
+// StartRangeSelect bắt đầu chọn vùng nếu chưa (shift+↑↓)
+func (p *FilesPane) StartRangeSelect() {
+	if p.rangeAnchor != nil {
+		return
+	}
+	row, ok := p.selectedRow()
+	if !ok {
+		return
+	}
+	key := row.key()
+	p.rangeAnchor = &key
+	p.extendRange()
+	p.refreshContent()
+}
+
+// IsRangeSelecting cho biết đang kéo vùng chọn
+func (p *FilesPane) IsRangeSelecting() bool {
+	return p.rangeAnchor != nil
+}
+
+// HasSelection cho biết có dòng nào được chọn
+func (p *FilesPane) HasSelection() bool {
+	return len(p.marked) > 0
+}
+
+// ClearSelection bỏ vùng chọn, false khi không có gì để bỏ
+func (p *FilesPane) ClearSelection() bool {
+	if len(p.marked) == 0 && p.rangeAnchor == nil {
+		return false
+	}
+	clear(p.marked)
+	p.rangeAnchor = nil
+	p.refreshContent()
+	return true
+}
+
+// extendRange chọn các dòng từ anchor tới cursor
+func (p *FilesPane) extendRange() {
+	if p.rangeAnchor == nil {
+		return
+	}
+	anchor := -1
+	for i, r := range p.rows {
+		if r.key() == *p.rangeAnchor {
+			anchor = i
+			break
+		}
+	}
+	if anchor < 0 {
+		p.rangeAnchor = nil
+		return
+	}
+	from, to := min(anchor, p.SelectedIndex()), max(anchor, p.SelectedIndex())
+	clear(p.marked)
+	for i := from; i <= to && i < len(p.rows); i++ {
+		p.marked[p.rows[i].key()] = true
+	}
+}
+
+// CursorUp di chuyển cursor lên, kéo vùng chọn theo nếu đang chọn
+func (p *FilesPane) CursorUp() {
+	p.BasePane.CursorUp()
+	p.extendRange()
+}
+
+// CursorDown di chuyển cursor xuống, kéo vùng chọn theo nếu đang chọn
+func (p *FilesPane) CursorDown() {
+	p.BasePane.CursorDown()
+	p.extendRange()
+}
+
+// CursorTop di chuyển đến đầu, kéo vùng chọn theo nếu đang chọn
+func (p *FilesPane) CursorTop() {
+	p.BasePane.CursorTop()
+	p.extendRange()
+}
+
+// CursorBottom di chuyển đến cuối, kéo vùng chọn theo nếu đang chọn
+func (p *FilesPane) CursorBottom() {
+	p.BasePane.CursorBottom()
+	p.extendRange()
+}
+
+// SelectedFiles trả về các file của vùng chọn (thư mục được mở rộng thành mọi file
+// bên dưới), hoặc của dòng dưới cursor khi không chọn gì
+func (p *FilesPane) SelectedFiles() (staged, unstaged []git.FileItem) {
+	seen := make(map[fileRowKey]bool)
+	add := func(r fileRow) {
+		files := []git.FileItem{r.Item}
+		if r.IsDir {
+			files = r.Files
+		}
+		for _, f := range files {
+			k := fileRowKey{path: f.Path, staged: r.staged}
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			if r.staged {
+				staged = append(staged, f)
+			} else {
+				unstaged = append(unstaged, f)
+			}
+		}
+	}
+	if len(p.marked) == 0 {
+		if row, ok := p.selectedRow(); ok {
+			add(row)
+		}
+		return staged, unstaged
+	}
+	for _, r := range p.rows {
+		if p.marked[r.key()] {
+			add(r)
+		}
+	}
+	return staged, unstaged
+}
+
+// ToggleTreeView chuyển giữa danh sách phẳng và cây thư mục, giữ file đang chọn nếu còn hiển thị
+func (p *FilesPane) ToggleTreeView() {
+	selected, hasSelected := p.selectedRow()
+	p.treeView = !p.treeView
+	p.rebuildRows()
+	if hasSelected {
+		p.selectRow(selected.Path, selected.staged)
+	}
 	p.refreshContent()
 }
 
+// IsTreeView cho biết pane đang hiển thị dạng cây
+func (p *FilesPane) IsTreeView() bool {
+	return p.treeView
+}
+
+// ToggleCollapse đóng/mở thư mục đang chọn, false khi dòng đang chọn không phải thư mục
+func (p *FilesPane) ToggleCollapse() bool {
+	row, ok := p.selectedRow()
+	if !ok || !row.IsDir {
+		return false
+	}
+	collapsed := p.collapsedUnstaged
+	if row.staged {
+		collapsed = p.collapsedStaged
+	}
+	if collapsed[row.Path] {
+		delete(collapsed, row.Path)
+	} else {
+		collapsed[row.Path] = true
+	}
+	p.rebuildRows()
+	p.selectRow(row.Path, row.staged)
+	p.refreshContent()
+	return true
+}
+
+// selectRow đặt cursor vào dòng có path (và phía staged) cho trước nếu có
+func (p *FilesPane) selectRow(path string, staged bool) {
+	for i, r := range p.rows {
+		if r.Path == path && r.staged == staged {
+			p.SetCursor(i)
+			return
+		}
+	}
+}
+
+func (p *FilesPane) selectedRow() (fileRow, bool) {
+	idx := p.SelectedIndex()
+	if idx < 0 || idx >= len(p.rows) {
+		return fileRow{}, false
+	}
+	return p.rows[idx], true
+}
+
 // StagedItems returns staged files
 func (p *FilesPane) StagedItems() []git.FileItem {
 	return p.stagedItems
@@ -42,22 +293,28 @@ func (p *FilesPane) UnstagedItems() []git.FileItem {
 	return p.unstagedItems
 }
 
-// SelectedItem trả về item đang được chọn
+// SelectedItem trả về file đang được chọn (found=false khi đang chọn thư mục)
 func (p *FilesPane) SelectedItem() (git.FileItem, bool, bool) {
-	idx := p.SelectedIndex()
-	if idx < len(p.stagedItems) {
-		return p.stagedItems[idx], true, true // item, staged, found
+	row, ok := p.selectedRow()
+	if !ok || row.IsDir {
+		return git.FileItem{}, false, false
 	}
-	unstagedIdx := idx - len(p.stagedItems)
-	if unstagedIdx < len(p.unstagedItems) {
-		return p.unstagedItems[unstagedIdx], false, true
+	return row.Item, row.staged, true // item, staged, found
+}
+
+// SelectedDir trả về thư mục đang được chọn trong tree view
+func (p *FilesPane) SelectedDir() (git.FileTreeRow, bool, bool) {
+	row, ok := p.selectedRow()
+	if !ok || !row.IsDir {
+		return git.FileTreeRow{}, false, false
 	}
-	return git.FileItem{}, false, false
+	return row.FileTreeRow, row.staged, true // dir, staged, found
 }
 
 // IsSelectedStaged kiểm tra item đang chọn có phải staged không
 func (p *FilesPane) IsSelectedStaged() bool {
-	return p.SelectedIndex() < len(p.stagedItems)
+	row, ok := p.selectedRow()
+	return ok && row.staged
 }
 
 // HasItems kiểm tra có files nào không
@@ -77,24 +334,25 @@ func (p *FilesPane) View() string {
 
 // RenderBox renders pane with border
 func (p *FilesPane) RenderBox(focused bool, styles ui.Styles) string {
-	return p.BasePane.RenderBox(p.ID().Title(), p.View(), focused, styles)
+	title := p.ID().Title()
+	if n := len(p.marked); n > 0 {
+		title += fmt.Sprintf(" (%d selected)", n)
+	}
+	return p.BasePane.RenderBox(title, p.View(), focused, styles)
 }
 
 // refreshContent cập nhật nội dung viewport
 func (p *FilesPane) refreshContent() {
 	var lines []string
 
-	// Staged files
-	for i, f := range p.stagedItems {
+	// Staged files trước, rồi unstaged files
+	for i, r := range p.rows {
 		selected := p.IsFocused() && i == p.SelectedIndex()
-		lines = append(lines, p.renderFileItem(f, true, selected))
-	}
-
-	// Unstaged files
-	for i, f := range p.unstagedItems {
-		idx := len(p.stagedItems) + i
-		selected := p.IsFocused() && idx == p.SelectedIndex()
-		lines = append(lines, p.renderFileItem(f, false, selected))
+		if p.treeView {
+			lines = append(lines, p.renderTreeRow(r, selected, p.marked[r.key()]))
+		} else {
+			lines = append(lines, p.renderFileItem(r.Item, r.staged, selected, p.marked[r.key()]))
+		}
 	}
 
 	if len(lines) == 0 {
@@ -107,43 +365,73 @@ func (p *FilesPane) refreshContent() {
 }
 
 // renderFileItem renders một file item với beautiful icons
-func (p *FilesPane) renderFileItem(f git.FileItem, staged bool, selected bool) string {
+func (p *FilesPane) renderFileItem(f git.FileItem, staged, selected, marked bool) string {
 	// Lấy icon phù hợp từ icon system
 	icon := p.styles.Icons.GetFileStatusIcon(f.Status, staged)
+	statusStyle := p.statusStyle(f.Status, staged)
+
+	line := statusStyle.Render(icon) + " " + f.Path
+
+	if selected {
+		line = p.styles.SelectedStyle.Render(icon + " " + f.Path)
+	} else if marked {
+		line = p.styles.InactiveSelectedStyle.Render(icon + " " + f.Path)
+	}
 
-	var statusStyle = p.styles.DimStyle
+	return line
+}
+
+// renderTreeRow renders một dòng của tree view: thư mục có icon đóng/mở, status
+// gộp và số file bên dưới
+func (p *FilesPane) renderTreeRow(r fileRow, selected, marked bool) string {
+	indent := strings.Repeat("  ", r.Depth)
+	status := r.Status()
+	icon := p.styles.Icons.GetFileStatusIcon(status, r.staged)
+	statusStyle := p.statusStyle(status, r.staged)
+
+	highlight := p.styles.SelectedStyle
+	if !selected {
+		highlight = p.styles.InactiveSelectedStyle
+	}
+
+	if !r.IsDir {
+		if selected || marked {
+			return indent + highlight.Render(icon+" "+r.Name)
+		}
+		return indent + statusStyle.Render(icon) + " " + r.Name
+	}
 
+	folder := p.styles.Icons.ExpandedFolder
+	if r.Collapsed {
+		folder = p.styles.Icons.CollapsedFolder
+	}
+	count := fmt.Sprintf("(%d)", len(r.Files))
+	if selected || marked {
+		return indent + highlight.Render(folder+" "+icon+" "+r.Name+"/ "+count)
+	}
+	return indent + p.styles.DimStyle.Render(folder) + " " + statusStyle.Render(icon) + " " + r.Name + "/ " + p.styles.DimStyle.Render(count)
+}
+
+// statusStyle chọn màu cho icon status của file
+func (p *FilesPane) statusStyle(status string, staged bool) lipgloss.Style {
 	if staged {
-		switch f.Status {
-		case "M":
-			statusStyle = p.styles.StagedStyle
+		switch status {
 		case "D":
-			statusStyle = p.styles.DeletedStyle
+			return p.styles.DeletedStyle
 		case "R":
-			statusStyle = p.styles.RenamedStyle
-		default:
-			statusStyle = p.styles.StagedStyle
-		}
-	} else {
-		switch f.Status {
-		case "?":
-			statusStyle = p.styles.UntrackedStyle
-		case "M":
-			statusStyle = p.styles.ModifiedStyle
-		case "D":
-			statusStyle = p.styles.DeletedStyle
+			return p.styles.RenamedStyle
 		default:
-			statusStyle = p.styles.ModifiedStyle
+			return p.styles.StagedStyle
 		}
 	}
-
-	line := statusStyle.Render(icon) + " " + f.Path
-
-	if selected {
-		line = p.styles.SelectedStyle.Render(icon + " " + f.Path)
+	switch status {
+	case "?":
+		return p.styles.UntrackedStyle
+	case "D":
+		return p.styles.DeletedStyle
+	default:
+		return p.styles.ModifiedStyle
 	}
-
-	return line
 }
 
 // Refresh re-renders content (call after cursor move or focus change)
@@ -91,7 +91,7 @@ func (p *HunkView) refreshContent() {
 		hunkLine := p.formatHunkHeader(h, selected)
 		lines = append(lines, hunkLine)
 
-		diffLines := strings.Split(p.diffStyler.Colorize(h.Content), "\n")
+		diffLines := strings.Split(p.diffStyler.ColorizeFile(p.currentPath, h.Content), "\n")
 		for _, line := range diffLines {
 			if selected {
 				lines = append(lines, p.styles.SelectedStyle.Render(line))
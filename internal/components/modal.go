@@ -1,13 +1,16 @@
 package components
 
 import (
+	"fmt"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
 
+	"gitzen/internal/git"
 	"gitzen/internal/ui"
 )
 
@@ -20,8 +23,21 @@ const (
 	ModalCreateBranch
 	ModalConfirm
 	ModalError
+	ModalInput
+	ModalMenu
+	ModalPush
 )
 
+// menuMaxRows là số item menu hiển thị cùng lúc, menu dài hơn được cuộn theo cursor
+const menuMaxRows = 15
+
+// MenuItem là một lựa chọn trong menu modal
+type MenuItem struct {
+	Key    string // phím tắt (e.g. "l")
+	Label  string
+	Action func() tea.Cmd
+}
+
 // Modal component cho các dialog
 type Modal struct {
 	modalType ModalType
@@ -38,8 +54,32 @@ type Modal struct {
 	// Error dialog
 	errorMsg string
 
-	// Commit modal
-	amendMode bool
+	// Commit modal: subject (input) + body nhiều dòng
+	amendMode     bool
+	body          textarea.Model
+	bodyFocused   bool
+	commitHistory []string
+	historyIdx    int // -1 = đang soạn draft
+	draftSubject  string
+	draftBody     string
+	commitSigning string // e.g. "signed: ssh", rỗng nếu không ký
+	skipHooks     bool   // commit với --no-verify
+
+	// Generic input modal
+	inputTitle  string
+	inputSubmit func(string) tea.Cmd
+
+	// Push modal
+	pushRemotes   []string
+	pushRemoteIdx int
+	pushOpts      git.PushOptions
+	pushLeases    map[string]string
+
+	// Menu modal
+	menuTitle  string
+	menuHeader []string // dòng mô tả phía trên các lựa chọn (e.g. preview)
+	menuItems  []MenuItem
+	menuCursor int
 }
 
 // NewModal tạo Modal mới
@@ -49,8 +89,10 @@ func NewModal(styles ui.Styles) *Modal {
 	input.Prompt = ""
 
 	return &Modal{
-		styles: styles,
-		input:  input,
+		styles:     styles,
+		input:      input,
+		body:       newCommitBody(),
+		historyIdx: -1,
 	}
 }
 
@@ -66,19 +108,6 @@ func (m *Modal) Type() ModalType {
 
 // --- Open Modals ---
 
-// OpenCommit mở commit modal
-func (m *Modal) OpenCommit(amend bool) {
-	m.modalType = ModalCommit
-	m.amendMode = amend
-	m.input.Reset()
-	if amend {
-		m.input.Placeholder = "Leave empty to keep old message"
-	} else {
-		m.input.Placeholder = "Enter commit message"
-	}
-	m.input.Focus()
-}
-
 // OpenCreateBranch mở create branch modal
 func (m *Modal) OpenCreateBranch() {
 	m.modalType = ModalCreateBranch
@@ -94,6 +123,33 @@ func (m *Modal) OpenConfirm(title string, action func() tea.Cmd) {
 	m.confirmAction = action
 }
 
+// OpenInput mở input modal chung, onSubmit được gọi với giá trị khi nhấn enter
+func (m *Modal) OpenInput(title, placeholder, value string, onSubmit func(string) tea.Cmd) {
+	m.modalType = ModalInput
+	m.inputTitle = title
+	m.inputSubmit = onSubmit
+	m.input.Reset()
+	m.input.Placeholder = placeholder
+	m.input.SetValue(value)
+	m.input.CursorEnd()
+	m.input.Focus()
+}
+
+// OpenMenu mở menu modal với danh sách lựa chọn
+func (m *Modal) OpenMenu(title string, items []MenuItem) {
+	m.modalType = ModalMenu
+	m.menuTitle = title
+	m.menuHeader = nil
+	m.menuItems = items
+	m.menuCursor = 0
+}
+
+// OpenMenuWithHeader mở menu modal kèm các dòng mô tả phía trên lựa chọn
+func (m *Modal) OpenMenuWithHeader(title string, header []string, items []MenuItem) {
+	m.OpenMenu(title, items)
+	m.menuHeader = header
+}
+
 // OpenError mở error dialog
 func (m *Modal) OpenError(msg string) {
 	m.modalType = ModalError
@@ -104,6 +160,7 @@ func (m *Modal) OpenError(msg string) {
 func (m *Modal) Close() {
 	m.modalType = ModalNone
 	m.input.Blur()
+	m.body.Blur()
 }
 
 // --- Input Access ---
@@ -123,11 +180,53 @@ func (m *Modal) ConfirmAction() func() tea.Cmd {
 	return m.confirmAction
 }
 
+// InputSubmit returns the submit callback of the generic input modal
+func (m *Modal) InputSubmit() func(string) tea.Cmd {
+	return m.inputSubmit
+}
+
+// MenuCursorUp di chuyển cursor menu lên
+func (m *Modal) MenuCursorUp() {
+	if m.menuCursor > 0 {
+		m.menuCursor--
+	}
+}
+
+// MenuCursorDown di chuyển cursor menu xuống
+func (m *Modal) MenuCursorDown() {
+	if m.menuCursor < len(m.menuItems)-1 {
+		m.menuCursor++
+	}
+}
+
+// SelectedMenuItem returns the menu item under the cursor
+func (m *Modal) SelectedMenuItem() (MenuItem, bool) {
+	if m.menuCursor < len(m.menuItems) {
+		return m.menuItems[m.menuCursor], true
+	}
+	return MenuItem{}, false
+}
+
+// MenuItemByKey tìm menu item theo phím tắt
+func (m *Modal) MenuItemByKey(key string) (MenuItem, bool) {
+	for _, item := range m.menuItems {
+		if item.Key != "" && item.Key == key {
+			return item, true
+		}
+	}
+	return MenuItem{}, false
+}
+
 // --- Update & View ---
 
 // Update xử lý input cho modal
 func (m *Modal) Update(msg tea.Msg) tea.Cmd {
-	if m.modalType == ModalCommit || m.modalType == ModalCreateBranch {
+	if m.modalType == ModalCommit && m.bodyFocused {
+		var cmd tea.Cmd
+		m.body, cmd = m.body.Update(msg)
+		return cmd
+	}
+	if m.modalType == ModalCommit || m.modalType == ModalCreateBranch || m.modalType == ModalInput || m.modalType == ModalPush {
 		var cmd tea.Cmd
 		m.input, cmd = m.input.Update(msg)
 		return cmd
@@ -146,6 +245,12 @@ func (m *Modal) View() string {
 		return m.renderConfirmModal()
 	case ModalError:
 		return m.renderErrorModal()
+	case ModalInput:
+		return m.renderInputModal()
+	case ModalMenu:
+		return m.renderMenuModal()
+	case ModalPush:
+		return m.renderPushModal()
 	default:
 		return ""
 	}
@@ -196,27 +301,18 @@ func renderBox(title, content string, width int, borderColor lipgloss.Color, tit
 	return topBorder + "\n" + strings.Join(bodyLines, "\n") + "\n" + bottomBorder
 }
 
-func (m *Modal) renderCommitModal() string {
-	width := 60
+func (m *Modal) renderCreateBranchModal() string {
+	width := 50
 	innerWidth := width - 2
 
-	title := "Commit"
-	if m.amendMode {
-		title = "Amend Commit"
-	}
-
-	// Input line with visual prompt
 	inputLine := m.input.View()
-
-	// Pad input to full width
 	inputWidth := ansi.StringWidth(inputLine)
 	if inputWidth < innerWidth {
 		inputLine = inputLine + strings.Repeat(" ", innerWidth-inputWidth)
 	}
 
-	// Footer with keybindings
 	footer := lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(
-		"enter: confirm • esc: cancel",
+		"enter: create • esc: cancel",
 	)
 	footerWidth := ansi.StringWidth(footer)
 	if footerWidth < innerWidth {
@@ -225,11 +321,11 @@ func (m *Modal) renderCommitModal() string {
 
 	content := inputLine + "\n" + footer
 
-	return renderBox(title, content, width, lipgloss.Color("2"), lipgloss.Color("2"))
+	return renderBox("New Branch", content, width, lipgloss.Color("2"), lipgloss.Color("2"))
 }
 
-func (m *Modal) renderCreateBranchModal() string {
-	width := 50
+func (m *Modal) renderInputModal() string {
+	width := 60
 	innerWidth := width - 2
 
 	inputLine := m.input.View()
@@ -239,7 +335,7 @@ func (m *Modal) renderCreateBranchModal() string {
 	}
 
 	footer := lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(
-		"enter: create • esc: cancel",
+		"enter: confirm • esc: cancel",
 	)
 	footerWidth := ansi.StringWidth(footer)
 	if footerWidth < innerWidth {
@@ -248,19 +344,76 @@ func (m *Modal) renderCreateBranchModal() string {
 
 	content := inputLine + "\n" + footer
 
-	return renderBox("New Branch", content, width, lipgloss.Color("2"), lipgloss.Color("2"))
+	return renderBox(m.inputTitle, content, width, lipgloss.Color("2"), lipgloss.Color("2"))
+}
+
+func (m *Modal) renderMenuModal() string {
+	width := 60
+	innerWidth := width - 2
+
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("4")).Bold(true)
+	selectedStyle := m.styles.SelectedStyle
+
+	var lines []string
+	if len(m.menuHeader) > 0 {
+		lines = append(lines, m.menuHeader...)
+		lines = append(lines, "")
+	}
+	// Menu dài (e.g. danh sách file của diff lớn) chỉ hiện một cửa sổ quanh cursor
+	start, end := 0, len(m.menuItems)
+	if end > menuMaxRows {
+		start = min(max(0, m.menuCursor-menuMaxRows/2), end-menuMaxRows)
+		end = start + menuMaxRows
+	}
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	if start > 0 {
+		lines = append(lines, dim.Render(fmt.Sprintf("↑ %d more", start)))
+	}
+	for i := start; i < end; i++ {
+		item := m.menuItems[i]
+		label := item.Label
+		if item.Key != "" {
+			label = item.Key + "  " + label
+		}
+		if i == m.menuCursor {
+			line := ansi.Truncate(label, innerWidth, "…")
+			if w := ansi.StringWidth(line); w < innerWidth {
+				line += strings.Repeat(" ", innerWidth-w)
+			}
+			lines = append(lines, selectedStyle.Render(line))
+			continue
+		}
+		if item.Key != "" {
+			label = keyStyle.Render(item.Key) + "  " + item.Label
+		}
+		lines = append(lines, label)
+	}
+	if end < len(m.menuItems) {
+		lines = append(lines, dim.Render(fmt.Sprintf("↓ %d more", len(m.menuItems)-end)))
+	}
+
+	footer := lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(
+		"j/k: move • enter: select • esc: cancel",
+	)
+	lines = append(lines, footer)
+
+	return renderBox(m.menuTitle, strings.Join(lines, "\n"), width, lipgloss.Color("4"), lipgloss.Color("4"))
 }
 
 func (m *Modal) renderConfirmModal() string {
 	width := 50
 	innerWidth := width - 2
 
-	// Message
-	msg := m.confirmTitle
-	msgWidth := ansi.StringWidth(msg)
-	if msgWidth < innerWidth {
-		msg = msg + strings.Repeat(" ", innerWidth-msgWidth)
+	// Message (wrap cho các preview dài như undo/redo)
+	var msgLines []string
+	for _, line := range wrapText(m.confirmTitle, innerWidth) {
+		lineWidth := ansi.StringWidth(line)
+		if lineWidth < innerWidth {
+			line = line + strings.Repeat(" ", innerWidth-lineWidth)
+		}
+		msgLines = append(msgLines, line)
 	}
+	msg := strings.Join(msgLines, "\n")
 
 	// Footer
 	footer := lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(
@@ -316,26 +469,31 @@ func wrapText(text string, width int) []string {
 		return []string{text}
 	}
 
-	words := strings.Fields(text)
-	if len(words) == 0 {
-		return []string{""}
-	}
-
+	// Giữ nguyên xuống dòng có sẵn (e.g. stderr nhiều dòng của gpg, hook)
 	var lines []string
-	var currentLine string
-
-	for _, word := range words {
-		if currentLine == "" {
-			currentLine = word
-		} else if ansi.StringWidth(currentLine+" "+word) <= width {
-			currentLine += " " + word
-		} else {
+	for _, paragraph := range strings.Split(text, "\n") {
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			continue
+		}
+
+		var currentLine string
+		for _, word := range words {
+			if currentLine == "" {
+				currentLine = word
+			} else if ansi.StringWidth(currentLine+" "+word) <= width {
+				currentLine += " " + word
+			} else {
+				lines = append(lines, currentLine)
+				currentLine = word
+			}
+		}
+		if currentLine != "" {
 			lines = append(lines, currentLine)
-			currentLine = word
 		}
 	}
-	if currentLine != "" {
-		lines = append(lines, currentLine)
+	if len(lines) == 0 {
+		return []string{""}
 	}
 
 	return lines
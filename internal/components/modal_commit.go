@@ -0,0 +1,226 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"gitzen/internal/git"
+)
+
+// commitModalWidth đủ rộng cho body 72 cột cộng border
+const commitModalWidth = git.BodyGuide + 6
+
+// newCommitBody tạo textarea cho commit body
+func newCommitBody() textarea.Model {
+	body := textarea.New()
+	body.Prompt = ""
+	body.ShowLineNumbers = false
+	body.CharLimit = 0
+	body.Placeholder = "Body: explain what and why"
+	body.FocusedStyle.CursorLine = lipgloss.NewStyle()
+	body.SetWidth(commitModalWidth - 2)
+	body.SetHeight(8)
+	return body
+}
+
+// OpenCommit mở commit modal
+func (m *Modal) OpenCommit(amend bool) {
+	m.modalType = ModalCommit
+	m.amendMode = amend
+	m.input.Reset()
+	m.body.Reset()
+	m.body.Blur()
+	m.bodyFocused = false
+	m.commitHistory = nil
+	m.historyIdx = -1
+	m.commitSigning = ""
+	m.skipHooks = false
+	if amend {
+		m.input.Placeholder = "Leave empty to keep old message"
+	} else {
+		m.input.Placeholder = "Subject"
+	}
+	m.input.Focus()
+}
+
+// SetCommitDraft điền sẵn subject/body (template, ticket, message cũ khi amend)
+// Không ghi đè nếu người dùng đã bắt đầu gõ.
+func (m *Modal) SetCommitDraft(subject, body string) {
+	if m.modalType != ModalCommit || m.input.Value() != "" || m.body.Value() != "" {
+		return
+	}
+	m.setCommitFields(subject, body)
+}
+
+// SetCommitMessage thay toàn bộ message (e.g. sau khi sửa bằng editor ngoài)
+func (m *Modal) SetCommitMessage(msg string) {
+	subject, body := git.SplitCommitMessage(msg)
+	m.setCommitFields(subject, body)
+}
+
+// SetCommitHistory đặt danh sách message cũ để recall (mới nhất trước)
+func (m *Modal) SetCommitHistory(history []string) {
+	m.commitHistory = history
+	m.historyIdx = -1
+}
+
+// SetCommitSigning hiển thị commit sẽ được ký (commit.gpgsign) trên title
+func (m *Modal) SetCommitSigning(label string) {
+	m.commitSigning = label
+}
+
+// ToggleSkipHooks bật/tắt --no-verify cho lần commit này
+func (m *Modal) ToggleSkipHooks() {
+	m.skipHooks = !m.skipHooks
+}
+
+// SkipHooks cho biết commit có bỏ qua hook (--no-verify) không
+func (m *Modal) SkipHooks() bool {
+	return m.skipHooks
+}
+
+// CommitMessage trả về message đầy đủ (subject + dòng trống + body)
+func (m *Modal) CommitMessage() string {
+	return git.JoinCommitMessage(m.input.Value(), m.body.Value())
+}
+
+// CommitBodyFocused cho biết body đang được focus (enter = xuống dòng)
+func (m *Modal) CommitBodyFocused() bool {
+	return m.bodyFocused
+}
+
+// ToggleCommitFocus chuyển focus giữa subject và body
+func (m *Modal) ToggleCommitFocus() {
+	m.bodyFocused = !m.bodyFocused
+	if m.bodyFocused {
+		m.input.Blur()
+		m.body.Focus()
+	} else {
+		m.body.Blur()
+		m.input.Focus()
+	}
+}
+
+// RecallPrevMessage lấy message cũ hơn trong history (ctrl+p)
+func (m *Modal) RecallPrevMessage() {
+	if m.historyIdx+1 >= len(m.commitHistory) {
+		return
+	}
+	if m.historyIdx == -1 {
+		m.draftSubject, m.draftBody = m.input.Value(), m.body.Value()
+	}
+	m.historyIdx++
+	m.SetCommitMessage(m.commitHistory[m.historyIdx])
+}
+
+// RecallNextMessage quay lại message mới hơn, cuối cùng là draft đang soạn (ctrl+n)
+func (m *Modal) RecallNextMessage() {
+	if m.historyIdx < 0 {
+		return
+	}
+	m.historyIdx--
+	if m.historyIdx == -1 {
+		m.setCommitFields(m.draftSubject, m.draftBody)
+		return
+	}
+	m.SetCommitMessage(m.commitHistory[m.historyIdx])
+}
+
+func (m *Modal) setCommitFields(subject, body string) {
+	m.input.SetValue(subject)
+	m.input.CursorEnd()
+	m.body.SetValue(body)
+}
+
+func (m *Modal) renderCommitModal() string {
+	width := commitModalWidth
+	innerWidth := width - 2
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	warn := lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Bold(true)
+
+	title := "Commit"
+	if m.amendMode {
+		title = "Amend Commit"
+	}
+	if m.commitSigning != "" {
+		title += " · " + m.commitSigning
+	}
+	if m.skipHooks {
+		title += " · no-verify"
+	}
+	if m.historyIdx >= 0 {
+		title += fmt.Sprintf(" (history %d/%d)", m.historyIdx+1, len(m.commitHistory))
+	}
+
+	var lines []string
+
+	// Subject với bộ đếm 50 ký tự
+	subjectLen := ansi.StringWidth(m.input.Value())
+	counter := dim.Render(fmt.Sprintf("%d/%d", subjectLen, git.SubjectGuide))
+	if subjectLen > git.SubjectGuide {
+		counter = warn.Render(fmt.Sprintf("%d/%d", subjectLen, git.SubjectGuide))
+	}
+	label := "Subject"
+	if !m.bodyFocused {
+		label = lipgloss.NewStyle().Foreground(lipgloss.Color("2")).Bold(true).Render(label)
+	}
+	lines = append(lines, padBetween(label, counter, innerWidth))
+	lines = append(lines, m.input.View())
+
+	// Ruler cho body: mốc 50 và 72 cột
+	lines = append(lines, commitRuler(innerWidth))
+	lines = append(lines, strings.Split(m.body.View(), "\n")...)
+
+	// Cảnh báo các dòng body vượt 72 cột
+	var longLines []string
+	for i, line := range strings.Split(m.body.Value(), "\n") {
+		if ansi.StringWidth(line) > git.BodyGuide {
+			longLines = append(longLines, fmt.Sprintf("%d", i+1))
+		}
+	}
+	if len(longLines) > 0 {
+		lines = append(lines, warn.Render(fmt.Sprintf("body line %s over %d cols", strings.Join(longLines, ", "), git.BodyGuide)))
+	}
+
+	// Footer with keybindings
+	submitHint := "enter: commit"
+	if m.bodyFocused {
+		submitHint = "ctrl+s: commit"
+	}
+	hooks := "^t: skip hooks"
+	if m.skipHooks {
+		hooks = "^t: run hooks"
+	}
+	lines = append(lines, dim.Render(submitHint+" • tab: body • "+hooks+" • ^e: editor • ^p/^n: history • esc"))
+
+	return renderBox(title, strings.Join(lines, "\n"), width, lipgloss.Color("2"), lipgloss.Color("2"))
+}
+
+// commitRuler vẽ thước cột với mốc ở 50 (subject) và 72 (body)
+func commitRuler(width int) string {
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	mark := lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+	var b strings.Builder
+	for col := 1; col <= width && col <= git.BodyGuide; col++ {
+		switch col {
+		case git.SubjectGuide, git.BodyGuide:
+			b.WriteString(mark.Render("┆"))
+		default:
+			b.WriteString(dim.Render("·"))
+		}
+	}
+	return b.String()
+}
+
+// padBetween đặt left ở đầu và right ở cuối dòng rộng width
+func padBetween(left, right string, width int) string {
+	gap := width - ansi.StringWidth(left) - ansi.StringWidth(right)
+	if gap < 1 {
+		gap = 1
+	}
+	return left + strings.Repeat(" ", gap) + right
+}
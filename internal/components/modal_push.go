@@ -0,0 +1,123 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"gitzen/internal/git"
+)
+
+// OpenPush mở push modal: chọn remote, tên branch trên remote và các option.
+// leases chứa SHA của remote-tracking ref theo "remote/branch" để hiển thị cho force-with-lease.
+func (m *Modal) OpenPush(remotes []string, opts git.PushOptions, leases map[string]string) {
+	m.modalType = ModalPush
+	m.pushRemotes = remotes
+	m.pushRemoteIdx = 0
+	for i, r := range remotes {
+		if r == opts.Remote {
+			m.pushRemoteIdx = i
+		}
+	}
+	m.pushOpts = opts
+	m.pushLeases = leases
+	m.input.Reset()
+	m.input.Placeholder = opts.LocalBranch
+	m.input.SetValue(opts.RemoteBranch)
+	m.input.CursorEnd()
+	m.input.Focus()
+}
+
+// PushOptions trả về options hiện tại của push modal
+func (m *Modal) PushOptions() git.PushOptions {
+	opts := m.pushOpts
+	if len(m.pushRemotes) > 0 {
+		opts.Remote = m.pushRemotes[m.pushRemoteIdx]
+	}
+	opts.RemoteBranch = strings.TrimSpace(m.input.Value())
+	if opts.RemoteBranch == "" {
+		opts.RemoteBranch = opts.LocalBranch
+	}
+	if opts.ForceWithLease {
+		opts.LeaseHash = m.pushLeases[opts.Remote+"/"+opts.RemoteBranch]
+	}
+	return opts
+}
+
+// CyclePushRemote chuyển sang remote tiếp theo
+func (m *Modal) CyclePushRemote() {
+	if len(m.pushRemotes) > 0 {
+		m.pushRemoteIdx = (m.pushRemoteIdx + 1) % len(m.pushRemotes)
+	}
+}
+
+// TogglePushOption bật/tắt một option của push modal theo tên
+func (m *Modal) TogglePushOption(name string) {
+	switch name {
+	case "upstream":
+		m.pushOpts.SetUpstream = !m.pushOpts.SetUpstream
+	case "tags":
+		m.pushOpts.Tags = !m.pushOpts.Tags
+	case "dry-run":
+		m.pushOpts.DryRun = !m.pushOpts.DryRun
+	case "no-verify":
+		m.pushOpts.NoVerify = !m.pushOpts.NoVerify
+	case "force":
+		m.pushOpts.ForceWithLease = !m.pushOpts.ForceWithLease
+	}
+}
+
+func (m *Modal) renderPushModal() string {
+	width := 60
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	warn := lipgloss.NewStyle().Foreground(lipgloss.Color("3")).Bold(true)
+	opts := m.PushOptions()
+
+	checkbox := func(on bool, key, label string) string {
+		box := "[ ]"
+		if on {
+			box = "[x]"
+		}
+		return fmt.Sprintf("%s %s %s", box, label, dim.Render("("+key+")"))
+	}
+
+	remote := "(none)"
+	if opts.Remote != "" {
+		remote = opts.Remote
+	}
+
+	lines := []string{
+		fmt.Sprintf("Local branch:  %s", opts.LocalBranch),
+		fmt.Sprintf("Remote:        %s %s", remote, dim.Render("(^r: next)")),
+		"Remote branch:",
+		m.input.View(),
+		"",
+		checkbox(opts.SetUpstream, "^u", "set upstream"),
+		checkbox(opts.Tags, "^g", "push tags (--follow-tags)"),
+		checkbox(opts.DryRun, "^d", "dry run"),
+		checkbox(opts.NoVerify, "^t", "skip hooks (--no-verify)"),
+		checkbox(opts.ForceWithLease, "^f", "force with lease"),
+	}
+	if opts.ForceWithLease {
+		if opts.LeaseHash != "" {
+			lines = append(lines, warn.Render(fmt.Sprintf("  expects %s/%s at %s", opts.Remote, opts.RemoteBranch, shortSHA(opts.LeaseHash))))
+		} else {
+			lines = append(lines, warn.Render(fmt.Sprintf("  no tracking ref for %s/%s", opts.Remote, opts.RemoteBranch)))
+		}
+	}
+	lines = append(lines, dim.Render("enter: push • esc: cancel"))
+
+	color := lipgloss.Color("4")
+	if opts.ForceWithLease {
+		color = lipgloss.Color("3")
+	}
+	return renderBox("Push", strings.Join(lines, "\n"), width, color, color)
+}
+
+func shortSHA(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
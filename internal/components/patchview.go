@@ -0,0 +1,216 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"gitzen/internal/git"
+	"gitzen/internal/tui"
+	"gitzen/internal/ui"
+)
+
+// patchRowKind là loại dòng trong PatchView
+type patchRowKind int
+
+const (
+	patchRowFile patchRowKind = iota
+	patchRowHunk
+	patchRowLine
+)
+
+type patchRow struct {
+	kind patchRowKind
+	ref  git.PatchLineRef
+	text string
+}
+
+// PatchView hiển thị diff của một commit theo từng dòng để chọn file/hunk/dòng
+// vào custom patch (lazygit-style patch building)
+type PatchView struct {
+	BasePane
+
+	patch      *git.CustomPatch
+	hash       string
+	title      string
+	rows       []patchRow
+	diffStyler tui.DiffStyler
+	styles     ui.Styles
+}
+
+func NewPatchView(styles ui.Styles) *PatchView {
+	return &PatchView{
+		BasePane:   NewBasePane(ui.PaneMain),
+		diffStyler: tui.DefaultDiffStyler(),
+		styles:     styles,
+	}
+}
+
+// SetCommit hiển thị diff của commit hash (đã nạp vào patch)
+func (p *PatchView) SetCommit(patch *git.CustomPatch, hash, title string) {
+	if p.hash != hash {
+		p.SetCursor(0)
+		p.GotoTop()
+	}
+	p.patch = patch
+	p.hash = hash
+	p.title = title
+	p.rows = nil
+	for fi, f := range patch.Files(hash) {
+		p.rows = append(p.rows, patchRow{kind: patchRowFile, ref: git.PatchLineRef{File: fi, Hunk: -1, Line: -1}, text: f.Path})
+		for hi, h := range f.Hunks {
+			for li, line := range strings.Split(h.Content, "\n") {
+				kind := patchRowLine
+				if li == 0 {
+					kind = patchRowHunk
+				}
+				p.rows = append(p.rows, patchRow{kind: kind, ref: git.PatchLineRef{File: fi, Hunk: hi, Line: li}, text: line})
+			}
+		}
+	}
+	p.SetItemCount(len(p.rows))
+	p.refreshContent()
+}
+
+// Hash trả về commit đang hiển thị
+func (p *PatchView) Hash() string {
+	return p.hash
+}
+
+// ToggleSelected chọn/bỏ file, hunk hoặc dòng dưới cursor
+func (p *PatchView) ToggleSelected() {
+	row, ok := p.selectedRow()
+	if !ok {
+		return
+	}
+	switch row.kind {
+	case patchRowFile:
+		p.patch.ToggleFile(p.hash, row.ref.File)
+	case patchRowHunk:
+		p.patch.ToggleHunk(p.hash, row.ref.File, row.ref.Hunk)
+	default:
+		p.patch.ToggleLine(p.hash, row.ref)
+	}
+	p.refreshContent()
+}
+
+// ToggleSelectedHunk chọn/bỏ cả hunk chứa cursor (cả file nếu cursor ở dòng file)
+func (p *PatchView) ToggleSelectedHunk() {
+	row, ok := p.selectedRow()
+	if !ok {
+		return
+	}
+	if row.kind == patchRowFile {
+		p.patch.ToggleFile(p.hash, row.ref.File)
+	} else {
+		p.patch.ToggleHunk(p.hash, row.ref.File, row.ref.Hunk)
+	}
+	p.refreshContent()
+}
+
+func (p *PatchView) selectedRow() (patchRow, bool) {
+	idx := p.SelectedIndex()
+	if p.patch == nil || idx < 0 || idx >= len(p.rows) {
+		return patchRow{}, false
+	}
+	return p.rows[idx], true
+}
+
+func (p *PatchView) Clear() {
+	p.patch = nil
+	p.hash = ""
+	p.rows = nil
+	p.SetItemCount(0)
+	p.SetContent("")
+}
+
+func (p *PatchView) View() string {
+	return p.ViewportView()
+}
+
+func (p *PatchView) RenderBox(focused bool, styles ui.Styles) string {
+	title := "Patch"
+	if p.title != "" {
+		title += " - " + p.title
+	}
+	if p.patch != nil && !p.patch.IsEmpty() {
+		title += fmt.Sprintf(" [%d lines from %d commits]", p.patch.LineCount(), len(p.patch.Commits()))
+	}
+	return p.BasePane.RenderBox(title, p.View(), focused, styles)
+}
+
+func (p *PatchView) Refresh() {
+	p.refreshContent()
+}
+
+func (p *PatchView) refreshContent() {
+	if len(p.rows) == 0 {
+		p.SetContent(p.styles.DimStyle.Render("(no changes)"))
+		return
+	}
+
+	// Đếm dòng thay đổi (tổng/đã chọn) theo file và hunk trong một lượt
+	type count struct{ total, selected int }
+	files := map[int]*count{}
+	hunks := map[[2]int]*count{}
+	for _, row := range p.rows {
+		if row.kind != patchRowLine || !git.IsChangeLine(row.text) {
+			continue
+		}
+		fc := files[row.ref.File]
+		if fc == nil {
+			fc = &count{}
+			files[row.ref.File] = fc
+		}
+		key := [2]int{row.ref.File, row.ref.Hunk}
+		hc := hunks[key]
+		if hc == nil {
+			hc = &count{}
+			hunks[key] = hc
+		}
+		fc.total++
+		hc.total++
+		if p.patch.IsSelected(p.hash, row.ref) {
+			fc.selected++
+			hc.selected++
+		}
+	}
+	marker := func(c *count) string {
+		switch {
+		case c == nil || c.selected == 0:
+			return "○"
+		case c.selected == c.total:
+			return "●"
+		default:
+			return "◐"
+		}
+	}
+
+	lines := make([]string, len(p.rows))
+	path := ""
+	for i, row := range p.rows {
+		var line string
+		switch row.kind {
+		case patchRowFile:
+			path = row.text
+			line = marker(files[row.ref.File]) + " " + p.styles.ActiveTitleStyle.Render(row.text)
+		case patchRowHunk:
+			line = marker(hunks[[2]int{row.ref.File, row.ref.Hunk}]) + " " + p.diffStyler.Colorize(row.text)
+		default:
+			m := " "
+			if git.IsChangeLine(row.text) {
+				m = "○"
+				if p.patch.IsSelected(p.hash, row.ref) {
+					m = "●"
+				}
+			}
+			line = m + " " + p.diffStyler.ColorizeFile(path, row.text)
+		}
+		if p.IsFocused() && i == p.SelectedIndex() {
+			line = p.styles.SelectedStyle.Render("> ") + line
+		} else {
+			line = "  " + line
+		}
+		lines[i] = line
+	}
+	p.SetContent(strings.Join(lines, "\n"))
+}
@@ -35,6 +35,11 @@ type SplitDiffView struct {
 	// Which pane is focused
 	focusedPane SplitPane
 
+	// Tuỳ chọn diff đang bật, hiện trong title
+	options string
+
+	sideBySide bool
+
 	// Styling
 	diffStyler tui.DiffStyler
 	styles     ui.Styles
@@ -66,31 +71,53 @@ func (s *SplitDiffView) SetSize(width, height int) {
 	s.unstagedVP.Height = innerH
 	s.stagedVP.Width = innerW
 	s.stagedVP.Height = innerH
+	s.render()
 }
 
-// SetDiffs sets both unstaged and staged diffs
-func (s *SplitDiffView) SetDiffs(unstaged, staged, filePath string) {
-	s.unstagedDiff = unstaged
-	s.stagedDiff = staged
-	s.filePath = filePath
+// ToggleSideBySide bật/tắt hiển thị hai cột cho cả hai pane, trả về trạng thái mới
+func (s *SplitDiffView) ToggleSideBySide() bool {
+	s.sideBySide = !s.sideBySide
+	s.render()
+	return s.sideBySide
+}
+
+func (s *SplitDiffView) renderDiff(diff string) string {
+	if s.sideBySide {
+		return s.diffStyler.RenderSideBySide(diff, s.unstagedVP.Width)
+	}
+	return s.diffStyler.Colorize(diff)
+}
 
-	// Apply colorization
-	if strings.TrimSpace(unstaged) == "" {
+func (s *SplitDiffView) render() {
+	if strings.TrimSpace(s.unstagedDiff) == "" {
 		s.unstagedVP.SetContent("(no unstaged changes)")
 	} else {
-		s.unstagedVP.SetContent(s.diffStyler.Colorize(unstaged))
+		s.unstagedVP.SetContent(s.renderDiff(s.unstagedDiff))
 	}
 
-	if strings.TrimSpace(staged) == "" {
+	if strings.TrimSpace(s.stagedDiff) == "" {
 		s.stagedVP.SetContent("(no staged changes)")
 	} else {
-		s.stagedVP.SetContent(s.diffStyler.Colorize(staged))
+		s.stagedVP.SetContent(s.renderDiff(s.stagedDiff))
 	}
+}
+
+// SetDiffs sets both unstaged and staged diffs
+func (s *SplitDiffView) SetDiffs(unstaged, staged, filePath string) {
+	s.unstagedDiff = unstaged
+	s.stagedDiff = staged
+	s.filePath = filePath
+	s.render()
 
 	s.unstagedVP.GotoTop()
 	s.stagedVP.GotoTop()
 }
 
+// SetOptions đặt mô tả tuỳ chọn diff đang bật, rỗng khi dùng mặc định
+func (s *SplitDiffView) SetOptions(options string) {
+	s.options = options
+}
+
 // Clear clears the view
 func (s *SplitDiffView) Clear() {
 	s.unstagedDiff = ""
@@ -193,9 +220,15 @@ func (s *SplitDiffView) View() string {
 	halfH := s.height / 2
 	bottomH := s.height - halfH
 
+	unstagedTitle, stagedTitle := "Unstaged Changes", "Staged Changes"
+	if label := joinLabels(s.options, sideBySideLabel(s.sideBySide, s.unstagedVP.Width)); label != "" {
+		unstagedTitle += " [" + label + "]"
+		stagedTitle += " [" + label + "]"
+	}
+
 	// Render both panes
-	unstagedBox := s.renderPane("Unstaged Changes", s.unstagedVP.View(), halfH, s.focusedPane == SplitPaneUnstaged)
-	stagedBox := s.renderPane("Staged Changes", s.stagedVP.View(), bottomH, s.focusedPane == SplitPaneStaged)
+	unstagedBox := s.renderPane(unstagedTitle, s.unstagedVP.View(), halfH, s.focusedPane == SplitPaneUnstaged)
+	stagedBox := s.renderPane(stagedTitle, s.stagedVP.View(), bottomH, s.focusedPane == SplitPaneStaged)
 
 	return lipgloss.JoinVertical(lipgloss.Left, unstagedBox, stagedBox)
 }
@@ -2,7 +2,9 @@ package components
 
 import (
 	"fmt"
+	"gitzen/internal/git"
 	"gitzen/internal/ui"
+	"strings"
 	"time"
 )
 
@@ -22,9 +24,12 @@ type StatusPane struct {
 
 	repoName        string
 	branchName      string
+	detachedHash    string // short hash khi HEAD đang detached
 	fetchStatus     FetchStatus
 	lastFetchTime   time.Time
 	newCommitsCount int
+	progress        *git.Progress // progress của fetch/push/pull đang chạy
+	repoState       git.RepoState // merge/rebase/cherry-pick... đang dở dang
 	styles          ui.Styles
 }
 
@@ -55,6 +60,22 @@ func (p *StatusPane) BranchName() string {
 	return p.branchName
 }
 
+// SetDetached đánh dấu HEAD đang detached tại hash, rỗng nếu đang ở trên branch
+func (p *StatusPane) SetDetached(hash string) {
+	p.detachedHash = hash
+	p.refreshContent()
+}
+
+// IsDetached kiểm tra HEAD có đang detached không
+func (p *StatusPane) IsDetached() bool {
+	return p.detachedHash != ""
+}
+
+// DetachedHash returns the short hash of a detached HEAD
+func (p *StatusPane) DetachedHash() string {
+	return p.detachedHash
+}
+
 // SetFetchStatus cập nhật trạng thái fetch
 func (p *StatusPane) SetFetchStatus(status FetchStatus) {
 	p.fetchStatus = status
@@ -78,6 +99,32 @@ func (p *StatusPane) SetNewCommitsAvailable(count int) {
 	p.refreshContent()
 }
 
+// SetRepoState cập nhật trạng thái merge/rebase/... của repository
+func (p *StatusPane) SetRepoState(state git.RepoState) {
+	p.repoState = state
+	p.refreshContent()
+}
+
+// RepoState trả về trạng thái merge/rebase/... hiện tại
+func (p *StatusPane) RepoState() git.RepoState {
+	return p.repoState
+}
+
+// SetProgress hiển thị progress mới nhất của lệnh mạng đang chạy
+func (p *StatusPane) SetProgress(progress git.Progress) {
+	p.progress = &progress
+	p.refreshContent()
+}
+
+// ClearProgress ẩn progress bar khi lệnh kết thúc
+func (p *StatusPane) ClearProgress() {
+	if p.progress == nil {
+		return
+	}
+	p.progress = nil
+	p.refreshContent()
+}
+
 // View returns rendered content
 func (p *StatusPane) View() string {
 	return p.ViewportView()
@@ -100,6 +147,24 @@ func (p *StatusPane) refreshContent() {
 	branchStyle := p.styles.BranchLocalStyle
 
 	content := repoStyle.Render(p.repoName) + " → " + branchStyle.Render(branch)
+	if p.detachedHash != "" {
+		banner := p.styles.WarningBannerStyle.Render(" " + p.styles.Icons.FetchError + " DETACHED HEAD " + p.detachedHash + " ")
+		content = repoStyle.Render(p.repoName) + " " + banner
+	}
+
+	// Banner cho merge/rebase/cherry-pick... đang dở dang, kèm phím tắt
+	if p.repoState.InProgress() {
+		keys := []string{}
+		if p.repoState.CanContinue() {
+			keys = append(keys, "C: continue")
+		}
+		keys = append(keys, "X: abort")
+		if p.repoState.CanSkip() {
+			keys = append(keys, "S: skip")
+		}
+		content += " " + p.styles.WarningBannerStyle.Render(" "+p.repoState.String()+" ") +
+			" " + p.styles.DimStyle.Render(strings.Join(keys, " "))
+	}
 
 	// Add fetch status indicator with beautiful icons
 	switch p.fetchStatus {
@@ -136,6 +201,12 @@ func (p *StatusPane) refreshContent() {
 		}
 	}
 
+	// Progress bar của fetch/push/pull đang chạy
+	if p.progress != nil {
+		content += " " + p.styles.FetchingStyle.Render(ui.ProgressBar(p.progress.Percent, 20)) +
+			" " + p.styles.DimStyle.Render(p.progress.String())
+	}
+
 	// Add new commits indicator if available
 	if p.newCommitsCount > 0 {
 		newCommitsIndicator := p.styles.InfoStyle.Render(fmt.Sprintf(" [%d new]", p.newCommitsCount))
@@ -74,5 +74,6 @@ func NewDefaultConfig() *RepoConfig {
 			DebounceMs:  300,
 			IgnoredDirs: []string{"node_modules", "vendor", ".next", "dist", "build", ".cache", ".tmp", "__pycache__", ".opencode"},
 		},
+		PullStrategy: defaultPull,
 	}
 }
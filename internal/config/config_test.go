@@ -95,3 +95,49 @@ func TestConfigValidation(t *testing.T) {
 		t.Error("Config with negative interval should be invalid")
 	}
 }
+
+func TestPullStrategyConfig(t *testing.T) {
+	config := NewDefaultConfig()
+	if config.EffectivePullStrategy() != PullFFOnly {
+		t.Errorf("Default pull strategy should be ff-only, got: %s", config.EffectivePullStrategy())
+	}
+
+	// Config cũ không có pull_strategy vẫn hợp lệ
+	config.PullStrategy = ""
+	if !config.IsValid() || config.EffectivePullStrategy() != PullFFOnly {
+		t.Error("Empty pull strategy should be valid and default to ff-only")
+	}
+
+	config.PullStrategy = PullRebase
+	if !config.IsValid() || config.EffectivePullStrategy() != PullRebase {
+		t.Error("Rebase pull strategy should be valid")
+	}
+
+	config.PullStrategy = "octopus"
+	if config.IsValid() {
+		t.Error("Unknown pull strategy should be invalid")
+	}
+}
+
+func TestNetworkIdleTimeoutConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if NewDefaultConfig().NetworkIdleTimeoutSec != 0 {
+		t.Error("Default config should use the built-in network idle timeout")
+	}
+
+	config := NewDefaultConfig()
+	config.NetworkIdleTimeoutSec = -1
+	if err := SaveRepoConfig(tmpDir, config); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadRepoConfig(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.NetworkIdleTimeoutSec != -1 {
+		t.Errorf("network_idle_timeout_sec = %d, want -1", loaded.NetworkIdleTimeoutSec)
+	}
+}
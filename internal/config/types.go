@@ -4,8 +4,25 @@ package config
 type RepoConfig struct {
 	AutoFetch AutoFetchConfig `yaml:"auto_fetch"`
 	FileWatch FileWatchConfig `yaml:"file_watch"`
+
+	// PullStrategy quyết định cách pull: merge, rebase hoặc ff-only (rỗng = ff-only)
+	PullStrategy string `yaml:"pull_strategy"`
+	// PullAutostash stash thay đổi local trước khi pull và áp dụng lại sau đó
+	PullAutostash bool `yaml:"pull_autostash"`
+	// NetworkIdleTimeoutSec huỷ fetch/push/pull khi không có output nào (progress
+	// hoặc output của hook) trong khoảng này. 0 = mặc định; số âm = không giới hạn,
+	// cho hook pre-push chạy lâu mà không in gì
+	NetworkIdleTimeoutSec int `yaml:"network_idle_timeout_sec"`
 }
 
+// Các giá trị hợp lệ cho PullStrategy
+const (
+	PullMerge   = "merge"
+	PullRebase  = "rebase"
+	PullFFOnly  = "ff-only"
+	defaultPull = PullFFOnly
+)
+
 // AutoFetchConfig chứa các cài đặt cho tính năng auto fetch
 type AutoFetchConfig struct {
 	Enabled         bool     `yaml:"enabled"`
@@ -33,5 +50,20 @@ func (c *RepoConfig) IsValid() bool {
 		return false
 	}
 
+	// PullStrategy phải là một trong các giá trị hỗ trợ
+	switch c.PullStrategy {
+	case "", PullMerge, PullRebase, PullFFOnly:
+	default:
+		return false
+	}
+
 	return true
 }
+
+// EffectivePullStrategy trả về pull strategy, mặc định ff-only khi chưa cấu hình
+func (c *RepoConfig) EffectivePullStrategy() string {
+	if c.PullStrategy == "" {
+		return defaultPull
+	}
+	return c.PullStrategy
+}
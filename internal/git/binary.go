@@ -0,0 +1,438 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // đăng ký decoder cho DecodeConfig
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// blobHeadBytes là số byte đầu của blob được đọc để đoán mime type và kích thước ảnh
+const blobHeadBytes = 64 << 10
+
+// lfsPointerVersion là dòng đầu của file pointer Git LFS
+const lfsPointerVersion = "version https://git-lfs.github.com/spec/v1"
+
+// BlobInfo mô tả một phía (old/new) của file binary
+type BlobInfo struct {
+	Exists        bool
+	Size          int64
+	MIME          string
+	Width, Height int // > 0 khi là ảnh PNG/JPEG/GIF đọc được header
+}
+
+// DescribeBlob đoán mime type và kích thước ảnh từ phần đầu nội dung
+func DescribeBlob(head []byte, size int64) BlobInfo {
+	info := BlobInfo{Exists: true, Size: size, MIME: http.DetectContentType(head)}
+	if i := strings.IndexByte(info.MIME, ';'); i >= 0 {
+		info.MIME = info.MIME[:i]
+	}
+	if cfg, format, err := image.DecodeConfig(bytes.NewReader(head)); err == nil {
+		info.Width, info.Height = cfg.Width, cfg.Height
+		info.MIME = "image/" + format
+	}
+	return info
+}
+
+// String mô tả blob trên một dòng, e.g. "12.3 KB · image/png · 640×480"
+func (b BlobInfo) String() string {
+	if !b.Exists {
+		return "(none)"
+	}
+	parts := []string{FormatSize(b.Size)}
+	if b.MIME != "" {
+		parts = append(parts, b.MIME)
+	}
+	if b.Width > 0 && b.Height > 0 {
+		parts = append(parts, fmt.Sprintf("%d×%d", b.Width, b.Height))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// FormatSize định dạng số byte dễ đọc (B, KB, MB, GB; cơ số 1024)
+func FormatSize(n int64) string {
+	if n < 1024 {
+		return strconv.FormatInt(n, 10) + " B"
+	}
+	units := []string{"KB", "MB", "GB", "TB"}
+	v := float64(n) / 1024
+	i := 0
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", v, units[i])
+}
+
+// LFSPointer là nội dung của file pointer Git LFS
+type LFSPointer struct {
+	OID  string // "sha256:<hex>"
+	Size int64
+}
+
+// ParseLFSPointer parse nội dung file pointer LFS, false nếu không phải pointer
+func ParseLFSPointer(text string) (LFSPointer, bool) {
+	var p LFSPointer
+	version := false
+	for _, line := range strings.Split(text, "\n") {
+		key, value, _ := strings.Cut(strings.TrimSpace(line), " ")
+		switch key {
+		case "version":
+			version = key+" "+value == lfsPointerVersion
+		case "oid":
+			p.OID = value
+		case "size":
+			p.Size, _ = strconv.ParseInt(value, 10, 64)
+		}
+	}
+	return p, version && p.OID != ""
+}
+
+// ShortOID rút gọn oid để hiển thị, e.g. "sha256:1a2b3c4d5e6f"
+func (p LFSPointer) ShortOID() string {
+	algo, hex, ok := strings.Cut(p.OID, ":")
+	if !ok {
+		algo, hex = "", p.OID
+	}
+	if len(hex) > 12 {
+		hex = hex[:12]
+	}
+	if algo == "" {
+		return hex
+	}
+	return algo + ":" + hex
+}
+
+// binarySource cung cấp nội dung cho bản tóm tắt; tách khỏi Runner để test
+type binarySource interface {
+	// Blob mô tả blob hash của path; newSide cho biết đây là phía new của diff
+	Blob(hash, path string, newSide bool) BlobInfo
+	// SmudgedDiff diff nội dung thật của hai LFS object, false khi không có sẵn local
+	SmudgedDiff(path string, old, new LFSPointer) (string, bool)
+}
+
+// SummarizeBinaryDiff thay "Binary files ... differ" bằng kích thước, mime type và
+// kích thước ảnh của hai phía, và thay diff của LFS pointer bằng thay đổi oid/size.
+// worktree: phía new là working tree (git diff chưa --staged). smudgeLFS: diff nội
+// dung thật của LFS object khi có sẵn trong .git/lfs/objects.
+func (r Runner) SummarizeBinaryDiff(diff string, worktree, smudgeLFS bool) string {
+	if !strings.Contains(diff, "Binary files ") && !strings.Contains(diff, lfsPointerVersion) {
+		return diff
+	}
+	return summarizeBinaryDiff(diff, runnerBinarySource{r: r, worktree: worktree}, smudgeLFS)
+}
+
+// binarySummarizer trả về hàm tóm tắt từng file section cho DiffBuffer
+func (r Runner) binarySummarizer(worktree, smudgeLFS bool) func(section []string) []string {
+	src := runnerBinarySource{r: r, worktree: worktree}
+	return func(section []string) []string {
+		// diff smudged của LFS object là một phần tử nhiều dòng
+		return strings.Split(strings.Join(summarizeFileSection(section, src, smudgeLFS), "\n"), "\n")
+	}
+}
+
+// maxSummarySectionLines: section dài hơn chắc chắn không phải binary diff hay diff LFS pointer
+const maxSummarySectionLines = 24
+
+// summaryCandidate cho biết file section đang nhận dở vẫn có thể là binary diff
+// hoặc diff LFS pointer: ngắn và mọi dòng trong hunk là dòng của pointer
+func summaryCandidate(section []string) bool {
+	if len(section) > maxSummarySectionLines {
+		return false
+	}
+	inHunk := false
+	for _, line := range section[1:] {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case inHunk && !lfsPointerLine(line):
+			return false
+		}
+	}
+	return true
+}
+
+// lfsPointerLine kiểm tra dòng hunk (" ", "-", "+") có thuộc file LFS pointer không
+func lfsPointerLine(line string) bool {
+	if line == "" || !strings.ContainsRune(" +-", rune(line[0])) {
+		return false
+	}
+	for _, key := range []string{"version ", "oid ", "size ", "ext-"} {
+		if strings.HasPrefix(line[1:], key) {
+			return true
+		}
+	}
+	return false
+}
+
+func summarizeBinaryDiff(diff string, src binarySource, smudgeLFS bool) string {
+	lines := strings.Split(diff, "\n")
+	var out []string
+	start := 0
+	for i := 1; i <= len(lines); i++ {
+		if i == len(lines) || strings.HasPrefix(lines[i], "diff --git ") {
+			out = append(out, summarizeFileSection(lines[start:i], src, smudgeLFS)...)
+			start = i
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// summarizeFileSection xử lý phần diff của một file (bắt đầu bằng "diff --git")
+func summarizeFileSection(section []string, src binarySource, smudgeLFS bool) []string {
+	if len(section) == 0 || !strings.HasPrefix(section[0], "diff --git ") {
+		return section
+	}
+	path := diffGitPath(section[0])
+	oldHash, newHash := "", ""
+	for _, line := range section {
+		if rest, ok := strings.CutPrefix(line, "index "); ok {
+			rest, _, _ = strings.Cut(rest, " ") // bỏ mode
+			oldHash, newHash, _ = strings.Cut(rest, "..")
+			break
+		}
+	}
+
+	for i, line := range section {
+		if strings.HasPrefix(line, "Binary files ") && strings.HasSuffix(line, " differ") {
+			oldInfo := src.Blob(oldHash, path, false)
+			newInfo := src.Blob(newHash, path, true)
+			summary := []string{
+				"Binary file changed: " + path,
+				"  old: " + oldInfo.String(),
+				"  new: " + newInfo.String(),
+			}
+			return append(append(append([]string{}, section[:i]...), summary...), section[i+1:]...)
+		}
+	}
+
+	hunk := -1
+	var oldText, newText []string
+	for i, line := range section {
+		if strings.HasPrefix(line, "@@") {
+			if hunk < 0 {
+				hunk = i
+			}
+			continue
+		}
+		if hunk < 0 || line == "" {
+			continue
+		}
+		switch line[0] {
+		case '-':
+			oldText = append(oldText, line[1:])
+		case '+':
+			newText = append(newText, line[1:])
+		case ' ':
+			oldText = append(oldText, line[1:])
+			newText = append(newText, line[1:])
+		}
+	}
+	if hunk < 0 {
+		return section
+	}
+	oldPtr, oldOK := ParseLFSPointer(strings.Join(oldText, "\n"))
+	newPtr, newOK := ParseLFSPointer(strings.Join(newText, "\n"))
+	if !oldOK && !newOK {
+		return section
+	}
+
+	summary := []string{"LFS object " + lfsChangeKind(oldOK, newOK) + ": " + path}
+	if oldOK && newOK {
+		summary = append(summary,
+			"  oid:  "+oldPtr.ShortOID()+" → "+newPtr.ShortOID(),
+			"  size: "+FormatSize(oldPtr.Size)+" → "+FormatSize(newPtr.Size))
+	} else {
+		p := newPtr
+		if oldOK {
+			p = oldPtr
+		}
+		summary = append(summary, "  oid:  "+p.ShortOID(), "  size: "+FormatSize(p.Size))
+	}
+
+	if oldOK && newOK && smudgeLFS {
+		if smudged, ok := src.SmudgedDiff(path, oldPtr, newPtr); ok {
+			summary = append(summary, smudged)
+		} else {
+			summary = append(summary, "  (smudged content not available locally)")
+		}
+	} else if oldOK && newOK {
+		summary = append(summary, "  (L: diff smudged content when available locally)")
+	}
+	return append(append([]string{}, section[:hunk]...), summary...)
+}
+
+func lfsChangeKind(oldOK, newOK bool) string {
+	switch {
+	case oldOK && newOK:
+		return "oid/size changed"
+	case newOK:
+		return "added"
+	default:
+		return "removed"
+	}
+}
+
+// runnerBinarySource đọc blob bằng git cat-file, phía new của diff chưa stage đọc từ working tree
+type runnerBinarySource struct {
+	r        Runner
+	worktree bool
+}
+
+func (s runnerBinarySource) Blob(hash, path string, newSide bool) BlobInfo {
+	if strings.Trim(hash, "0") == "" {
+		if newSide && s.worktree {
+			return s.worktreeFile(path)
+		}
+		return BlobInfo{}
+	}
+	if info, ok := s.catFile(hash); ok {
+		return info
+	}
+	if newSide && s.worktree {
+		return s.worktreeFile(path)
+	}
+	return BlobInfo{}
+}
+
+func (s runnerBinarySource) catFile(hash string) (BlobInfo, bool) {
+	out, err := s.r.run(DefaultCmdTimeout, "cat-file", "-s", hash)
+	if err != nil {
+		return BlobInfo{}, false
+	}
+	size, _ := strconv.ParseInt(strings.TrimSpace(out), 10, 64)
+	head, err := s.r.readHead(blobHeadBytes, "cat-file", "blob", hash)
+	if err != nil {
+		return BlobInfo{}, false
+	}
+	return DescribeBlob(head, size), true
+}
+
+func (s runnerBinarySource) worktreeFile(path string) BlobInfo {
+	return describeFile(filepath.Join(s.r.RepoRoot, path))
+}
+
+func describeFile(path string) BlobInfo {
+	f, err := os.Open(path)
+	if err != nil {
+		return BlobInfo{}
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return BlobInfo{}
+	}
+	head, _ := io.ReadAll(io.LimitReader(f, blobHeadBytes))
+	return DescribeBlob(head, st.Size())
+}
+
+func (s runnerBinarySource) SmudgedDiff(path string, old, new LFSPointer) (string, bool) {
+	oldFile, ok1 := s.r.lfsObjectPath(old)
+	newFile, ok2 := s.r.lfsObjectPath(new)
+	if !ok1 || !ok2 {
+		return "", false
+	}
+	oldInfo, newInfo := describeFile(oldFile), describeFile(newFile)
+	if !strings.HasPrefix(oldInfo.MIME, "text/") || !strings.HasPrefix(newInfo.MIME, "text/") {
+		return "  smudged old: " + oldInfo.String() + "\n  smudged new: " + newInfo.String(), true
+	}
+	out, err := s.r.diffNoIndex(oldFile, newFile)
+	if err != nil {
+		return "", false
+	}
+	return relabelNoIndexDiff(out, path), true
+}
+
+// lfsObjectPath trả về file object LFS trong .git/lfs/objects nếu có sẵn local
+func (r Runner) lfsObjectPath(p LFSPointer) (string, bool) {
+	_, hex, ok := strings.Cut(p.OID, ":")
+	if !ok || len(hex) < 5 {
+		return "", false
+	}
+	out, err := r.run(DefaultCmdTimeout, "rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err != nil {
+		return "", false
+	}
+	path := filepath.Join(strings.TrimSpace(out), "lfs", "objects", hex[:2], hex[2:4], hex)
+	if !fileExists(path) {
+		return "", false
+	}
+	return path, true
+}
+
+// relabelNoIndexDiff thay đường dẫn object trong header của diff --no-index bằng path của file
+func relabelNoIndexDiff(diff, path string) string {
+	lines := strings.Split(strings.TrimRight(diff, "\n"), "\n")
+	var out []string
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			continue
+		case strings.HasPrefix(line, "--- "):
+			line = "--- a/" + path + " (smudged)"
+		case strings.HasPrefix(line, "+++ "):
+			line = "+++ b/" + path + " (smudged)"
+		case strings.HasPrefix(line, "index "):
+			continue
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
+
+// diffNoIndex chạy git diff --no-index; exit 1 nghĩa là hai file khác nhau, không phải lỗi
+func (r Runner) diffNoIndex(oldFile, newFile string) (string, error) {
+	ctx, cancel := context.WithTimeout(r.Context(), DefaultDiffTimeout)
+	defer cancel()
+	args := []string{"diff", "--no-index", "--no-color", oldFile, newFile}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	if r.RepoRoot != "" {
+		cmd.Dir = r.RepoRoot
+	}
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		err = nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return stdout.String(), nil
+}
+
+// readHead chạy lệnh git và chỉ đọc n byte đầu của stdout, rồi dừng process
+func (r Runner) readHead(n int64, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(r.Context(), DefaultCmdTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", args...)
+	if r.RepoRoot != "" {
+		cmd.Dir = r.RepoRoot
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	head, readErr := io.ReadAll(io.LimitReader(stdout, n))
+	cancel()
+	cmd.Wait()
+	if readErr != nil {
+		return nil, readErr
+	}
+	return head, nil
+}
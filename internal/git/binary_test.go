@@ -0,0 +1,143 @@
+package git
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+type fakeBinarySource struct {
+	blobs   map[string]BlobInfo
+	smudged string
+}
+
+func (f fakeBinarySource) Blob(hash, path string, newSide bool) BlobInfo {
+	return f.blobs[hash]
+}
+
+func (f fakeBinarySource) SmudgedDiff(path string, old, new LFSPointer) (string, bool) {
+	return f.smudged, f.smudged != ""
+}
+
+func TestDescribeBlob(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 64, 32))); err != nil {
+		t.Fatal(err)
+	}
+	info := DescribeBlob(buf.Bytes(), 2048)
+	if info.MIME != "image/png" || info.Width != 64 || info.Height != 32 {
+		t.Fatalf("DescribeBlob(png) = %+v", info)
+	}
+	if got, want := info.String(), "2.0 KB · image/png · 64×32"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	info = DescribeBlob([]byte{0, 1, 2, 3}, 4)
+	if info.MIME != "application/octet-stream" || info.Width != 0 {
+		t.Errorf("DescribeBlob(bytes) = %+v", info)
+	}
+	if got := (BlobInfo{}).String(); got != "(none)" {
+		t.Errorf("missing blob String() = %q", got)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{
+		0:              "0 B",
+		1023:           "1023 B",
+		1536:           "1.5 KB",
+		5 << 20:        "5.0 MB",
+		3 << 30:        "3.0 GB",
+		int64(2) << 40: "2.0 TB",
+		int64(2) << 50: "2048.0 TB",
+	}
+	for n, want := range tests {
+		if got := FormatSize(n); got != want {
+			t.Errorf("FormatSize(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestParseLFSPointer(t *testing.T) {
+	text := "version https://git-lfs.github.com/spec/v1\noid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\nsize 12345\n"
+	p, ok := ParseLFSPointer(text)
+	if !ok || p.Size != 12345 || !strings.HasPrefix(p.OID, "sha256:4d7a") {
+		t.Fatalf("ParseLFSPointer = %+v, %v", p, ok)
+	}
+	if got, want := p.ShortOID(), "sha256:4d7a214614ab"; got != want {
+		t.Errorf("ShortOID() = %q, want %q", got, want)
+	}
+	if _, ok := ParseLFSPointer("oid sha256:abc\nsize 1"); ok {
+		t.Error("text without version line should not be a pointer")
+	}
+}
+
+func TestSummarizeBinaryDiff(t *testing.T) {
+	diff := `diff --git a/logo.png b/logo.png
+index 1111111..2222222 100644
+Binary files a/logo.png and b/logo.png differ
+diff --git a/new.bin b/new.bin
+new file mode 100644
+index 0000000..3333333
+Binary files /dev/null and b/new.bin differ
+diff --git a/main.go b/main.go
+index 4444444..5555555 100644
+--- a/main.go
++++ b/main.go
+@@ -1 +1 @@
+-a
++b`
+	src := fakeBinarySource{blobs: map[string]BlobInfo{
+		"1111111": {Exists: true, Size: 100, MIME: "image/png", Width: 10, Height: 10},
+		"2222222": {Exists: true, Size: 200, MIME: "image/png", Width: 20, Height: 20},
+		"3333333": {Exists: true, Size: 10, MIME: "application/octet-stream"},
+	}}
+	got := summarizeBinaryDiff(diff, src, false)
+	for _, want := range []string{
+		"Binary file changed: logo.png\n  old: 100 B · image/png · 10×10\n  new: 200 B · image/png · 20×20",
+		"Binary file changed: new.bin\n  old: (none)\n  new: 10 B · application/octet-stream",
+		"@@ -1 +1 @@\n-a\n+b",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("summary missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, " differ") {
+		t.Errorf("Binary files lines should be replaced:\n%s", got)
+	}
+}
+
+func TestSummarizeLFSPointerDiff(t *testing.T) {
+	diff := `diff --git a/model.bin b/model.bin
+index 1111111..2222222 100644
+--- a/model.bin
++++ b/model.bin
+@@ -1,3 +1,3 @@
+ version https://git-lfs.github.com/spec/v1
+-oid sha256:aaaaaaaaaaaaaaaaaaaa
+-size 1024
++oid sha256:bbbbbbbbbbbbbbbbbbbb
++size 2048`
+	got := summarizeBinaryDiff(diff, fakeBinarySource{}, false)
+	want := `+++ b/model.bin
+LFS object oid/size changed: model.bin
+  oid:  sha256:aaaaaaaaaaaa → sha256:bbbbbbbbbbbb
+  size: 1.0 KB → 2.0 KB`
+	if !strings.Contains(got, want) {
+		t.Fatalf("summary =\n%s\nwant to contain\n%s", got, want)
+	}
+	if strings.Contains(got, "@@") {
+		t.Errorf("pointer hunk should be replaced:\n%s", got)
+	}
+
+	got = summarizeBinaryDiff(diff, fakeBinarySource{}, true)
+	if !strings.Contains(got, "smudged content not available locally") {
+		t.Errorf("missing smudge note:\n%s", got)
+	}
+	got = summarizeBinaryDiff(diff, fakeBinarySource{smudged: "@@ -1 +1 @@\n-old\n+new"}, true)
+	if !strings.HasSuffix(got, "@@ -1 +1 @@\n-old\n+new") {
+		t.Errorf("smudged diff not appended:\n%s", got)
+	}
+}
@@ -0,0 +1,90 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ParseBranches parse output của `git branch --format=%(HEAD)%(refname:short)%09%(upstream:short)`
+func ParseBranches(out string) []Branch {
+	var branches []Branch
+	for _, line := range strings.Split(strings.ReplaceAll(out, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		isCurrent := false
+		if line[0] == '*' {
+			isCurrent = true
+			line = line[1:]
+		}
+		name, upstream, _ := strings.Cut(line, "\t")
+		branches = append(branches, Branch{
+			Name:      strings.TrimSpace(name),
+			IsCurrent: isCurrent,
+			IsRemote:  false,
+			Upstream:  strings.TrimSpace(upstream),
+		})
+	}
+	return branches
+}
+
+// RenameBranch đổi tên branch local
+func (r Runner) RenameBranch(oldName, newName string) error {
+	_, err := r.run(DefaultCmdTimeout, "branch", "-m", oldName, newName)
+	return err
+}
+
+// BranchUpstreamRef trả về remote và ref (refs/heads/...) mà branch đang track
+func (r Runner) BranchUpstreamRef(branch string) (string, string, error) {
+	remote, err := r.run(DefaultCmdTimeout, "config", "--get", "branch."+branch+".remote")
+	if err != nil {
+		return "", "", fmt.Errorf("branch %s has no upstream", branch)
+	}
+	merge, err := r.run(DefaultCmdTimeout, "config", "--get", "branch."+branch+".merge")
+	if err != nil {
+		return "", "", fmt.Errorf("branch %s has no upstream", branch)
+	}
+	return strings.TrimSpace(remote), strings.TrimSpace(merge), nil
+}
+
+// RenameRemoteBranch đẩy branch đã đổi tên lên remote, set upstream mới và xoá branch cũ trên remote.
+// Gọi sau RenameBranch, khi newName đã tồn tại ở local.
+func (r Runner) RenameRemoteBranch(remote, oldRemoteBranch, newName string) error {
+	if _, err := r.runNetwork("push", "-u", remote, newName); err != nil {
+		return err
+	}
+	_, err := r.runNetwork("push", remote, "--delete", oldRemoteBranch)
+	return err
+}
+
+// SetUpstream đặt upstream (e.g. origin/main) cho branch
+func (r Runner) SetUpstream(branch, upstream string) error {
+	_, err := r.run(DefaultCmdTimeout, "branch", "--set-upstream-to="+upstream, branch)
+	return err
+}
+
+// UnsetUpstream xoá upstream của branch
+func (r Runner) UnsetUpstream(branch string) error {
+	_, err := r.run(DefaultCmdTimeout, "branch", "--unset-upstream", branch)
+	return err
+}
+
+// FastForwardBranch cập nhật một branch local (không checkout) tới upstream của nó,
+// tương đương `git fetch origin main:main`. Chỉ fast-forward, git sẽ từ chối nếu diverged.
+func (r Runner) FastForwardBranch(branch string) (string, error) {
+	remote, mergeRef, err := r.BranchUpstreamRef(branch)
+	if err != nil {
+		return "", err
+	}
+	if remote == "." {
+		return "", errors.New("upstream is a local branch, nothing to fetch")
+	}
+	refspec := mergeRef + ":refs/heads/" + branch
+	return r.runNetwork("fetch", remote, refspec)
+}
+
+// PullFastForwardOnly fast-forward branch hiện tại tới upstream
+func (r Runner) PullFastForwardOnly() (string, error) {
+	return r.runNetwork("pull", "--ff-only")
+}
@@ -0,0 +1,45 @@
+package git
+
+import "testing"
+
+func TestParseBranches_Empty(t *testing.T) {
+	result := ParseBranches("")
+	if len(result) != 0 {
+		t.Errorf("expected 0 branches, got %d", len(result))
+	}
+}
+
+func TestParseBranches_WithUpstream(t *testing.T) {
+	out := "*main\torigin/main\nfeature/login\t\nfix\torigin/fix-remote\n"
+	result := ParseBranches(out)
+
+	if len(result) != 3 {
+		t.Fatalf("expected 3 branches, got %d", len(result))
+	}
+	if result[0].Name != "main" || !result[0].IsCurrent {
+		t.Errorf("expected current branch 'main', got %+v", result[0])
+	}
+	if result[0].Upstream != "origin/main" {
+		t.Errorf("expected upstream 'origin/main', got '%s'", result[0].Upstream)
+	}
+	if result[1].Name != "feature/login" || result[1].IsCurrent {
+		t.Errorf("expected non-current branch 'feature/login', got %+v", result[1])
+	}
+	if result[1].Upstream != "" {
+		t.Errorf("expected no upstream, got '%s'", result[1].Upstream)
+	}
+	if result[2].Upstream != "origin/fix-remote" {
+		t.Errorf("expected upstream 'origin/fix-remote', got '%s'", result[2].Upstream)
+	}
+}
+
+func TestParseBranches_WithoutTab(t *testing.T) {
+	// Output cũ không có upstream column vẫn parse được
+	result := ParseBranches("*main\ndev")
+	if len(result) != 2 {
+		t.Fatalf("expected 2 branches, got %d", len(result))
+	}
+	if result[1].Name != "dev" || result[1].Upstream != "" {
+		t.Errorf("unexpected branch: %+v", result[1])
+	}
+}
@@ -0,0 +1,143 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+const (
+	// SubjectGuide là độ dài khuyến nghị cho subject line của commit message
+	SubjectGuide = 50
+	// BodyGuide là độ dài khuyến nghị cho mỗi dòng trong body
+	BodyGuide = 72
+)
+
+// ticketPatterns nhận diện ticket ID trong tên branch, ưu tiên kiểu JIRA (ABC-123)
+var ticketPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\b([A-Z][A-Z0-9]+-[0-9]+)\b`),
+	regexp.MustCompile(`(?i)(?:^|/)(?:(?:issues?|gh|bug)[-_]?#?([0-9]+)|#?([0-9]+)[-_])`),
+}
+
+// TicketFromBranch trích ticket ID từ tên branch.
+// feature/ABC-123-login → "ABC-123", fix/42-crash → "#42", main → "".
+func TicketFromBranch(branch string) string {
+	if m := ticketPatterns[0].FindStringSubmatch(branch); m != nil {
+		return m[1]
+	}
+	if m := ticketPatterns[1].FindStringSubmatch(branch); m != nil {
+		if m[1] != "" {
+			return "#" + m[1]
+		}
+		return "#" + m[2]
+	}
+	return ""
+}
+
+// SplitCommitMessage tách commit message thành subject và body
+func SplitCommitMessage(msg string) (string, string) {
+	msg = strings.Trim(strings.ReplaceAll(msg, "\r\n", "\n"), "\n")
+	subject, body, _ := strings.Cut(msg, "\n")
+	return strings.TrimSpace(subject), strings.Trim(body, "\n")
+}
+
+// JoinCommitMessage ghép subject và body với một dòng trống ở giữa
+func JoinCommitMessage(subject, body string) string {
+	subject = strings.TrimSpace(subject)
+	body = strings.Trim(body, "\n")
+	if strings.TrimSpace(body) == "" {
+		return subject
+	}
+	return subject + "\n\n" + body
+}
+
+// StripCommentLines bỏ các dòng comment (#) như git commit --cleanup=strip
+func StripCommentLines(msg string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(msg, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// CommitTemplate đọc file commit.template (nếu được cấu hình), đã bỏ comment
+func (r Runner) CommitTemplate() (string, error) {
+	path, err := r.run(DefaultCmdTimeout, "config", "--path", "--get", "commit.template")
+	if err != nil {
+		// Không cấu hình template
+		return "", nil
+	}
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return "", nil
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.RepoRoot, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return StripCommentLines(string(data)), nil
+}
+
+// RecentCommitMessages trả về n commit message gần nhất (đầy đủ subject + body)
+func (r Runner) RecentCommitMessages(n int) ([]string, error) {
+	out, err := r.run(DefaultCmdTimeout, "log", "-n", fmt.Sprintf("%d", n), "--format=%B%x00")
+	if err != nil {
+		return nil, err
+	}
+	var msgs []string
+	for _, m := range strings.Split(out, "\x00") {
+		if m = strings.Trim(m, "\n"); m != "" {
+			msgs = append(msgs, m)
+		}
+	}
+	return msgs, nil
+}
+
+// HeadCommitMessage trả về message đầy đủ của HEAD
+func (r Runner) HeadCommitMessage() (string, error) {
+	out, err := r.run(DefaultCmdTimeout, "log", "-1", "--format=%B")
+	if err != nil {
+		return "", err
+	}
+	return strings.Trim(out, "\n"), nil
+}
+
+// Editor trả về editor mà git sẽ dùng (GIT_EDITOR, core.editor, VISUAL, EDITOR)
+func (r Runner) Editor() (string, error) {
+	out, err := r.run(DefaultCmdTimeout, "var", "GIT_EDITOR")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// CommitMessageFile trả về đường dẫn file tạm để soạn commit message bằng editor ngoài
+func (r Runner) CommitMessageFile() (string, error) {
+	out, err := r.run(DefaultCmdTimeout, "rev-parse", "--git-path", "GITZEN_EDITMSG")
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimSpace(out)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.RepoRoot, path)
+	}
+	return path, nil
+}
+
+// EditorCommand tạo lệnh mở editor với file, editor có thể chứa args (e.g. "code --wait")
+func EditorCommand(editor, path string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", editor+" \""+path+"\"")
+	}
+	return exec.Command("sh", "-c", editor+` "$@"`, editor, path)
+}
@@ -0,0 +1,47 @@
+package git
+
+import "testing"
+
+func TestTicketFromBranch(t *testing.T) {
+	tests := []struct {
+		branch string
+		want   string
+	}{
+		{"feature/ABC-123-login", "ABC-123"},
+		{"PROJ-7", "PROJ-7"},
+		{"fix/42-crash", "#42"},
+		{"issue-99", "#99"},
+		{"feature/gh#12", "#12"},
+		{"main", ""},
+		{"release/2024", ""},
+		{"feature/utf-8-support", ""},
+	}
+	for _, tt := range tests {
+		if got := TicketFromBranch(tt.branch); got != tt.want {
+			t.Errorf("TicketFromBranch(%q) = %q, want %q", tt.branch, got, tt.want)
+		}
+	}
+}
+
+func TestSplitJoinCommitMessage(t *testing.T) {
+	subject, body := SplitCommitMessage("Add parser\n\nHandles quoted paths.\nRefs: #12\n")
+	if subject != "Add parser" {
+		t.Errorf("subject = %q", subject)
+	}
+	if body != "Handles quoted paths.\nRefs: #12" {
+		t.Errorf("body = %q", body)
+	}
+	if got := JoinCommitMessage(subject, body); got != "Add parser\n\nHandles quoted paths.\nRefs: #12" {
+		t.Errorf("JoinCommitMessage = %q", got)
+	}
+	if got := JoinCommitMessage(" Fix typo ", "\n  \n"); got != "Fix typo" {
+		t.Errorf("JoinCommitMessage without body = %q", got)
+	}
+}
+
+func TestStripCommentLines(t *testing.T) {
+	in := "\n# Subject (50 chars)\nfeat: \n\n# Body\nWhy:   \n"
+	if got := StripCommentLines(in); got != "feat:\n\nWhy:" {
+		t.Errorf("StripCommentLines = %q", got)
+	}
+}
@@ -0,0 +1,121 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gitzen/internal/limits"
+)
+
+// CompareFile là một file thay đổi giữa hai ref
+type CompareFile struct {
+	Status  string // A, M, D, R, C, T (bỏ phần trăm similarity)
+	Path    string
+	OldPath string // path cũ khi rename/copy
+}
+
+// CompareCommit là một commit nằm giữa hai ref
+type CompareCommit struct {
+	Hash    string
+	Subject string
+}
+
+// Comparison là kết quả so sánh from → to: các file khác nhau giữa hai tree và
+// các commit chỉ có ở to (Commits) hoặc chỉ có ở from (Behind)
+type Comparison struct {
+	From, To string
+	Files    []CompareFile
+	Commits  []CompareCommit
+	Behind   int
+}
+
+// ParseCompareRange tách "a..b" hoặc "a...b" thành hai ref
+func ParseCompareRange(s string) (from, to string, ok bool) {
+	s = strings.TrimSpace(s)
+	sep := ".."
+	if strings.Contains(s, "...") {
+		sep = "..."
+	}
+	parts := strings.SplitN(s, sep, 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	from, to = strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+	if from == "" || to == "" {
+		return "", "", false
+	}
+	return from, to, true
+}
+
+// ParseNameStatus parse output của git diff --name-status
+func ParseNameStatus(out string) []CompareFile {
+	var files []CompareFile
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(strings.TrimRight(line, "\r"), "\t")
+		if len(fields) < 2 || fields[0] == "" {
+			continue
+		}
+		f := CompareFile{Status: fields[0][:1], Path: fields[1]}
+		if (f.Status == "R" || f.Status == "C") && len(fields) >= 3 {
+			f.OldPath = fields[1]
+			f.Path = fields[2]
+		}
+		files = append(files, f)
+	}
+	return files
+}
+
+// ParseCompareLog parse output của git log --format=%h%x09%s
+func ParseCompareLog(out string) []CompareCommit {
+	var commits []CompareCommit
+	for _, line := range strings.Split(out, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		hash, subject, _ := strings.Cut(line, "\t")
+		commits = append(commits, CompareCommit{Hash: hash, Subject: subject})
+	}
+	return commits
+}
+
+// CompareRefs so sánh hai ref (commit, branch, tag, stash)
+func (r Runner) CompareRefs(from, to string, opts DiffOptions) (Comparison, error) {
+	c := Comparison{From: from, To: to}
+
+	args := []string{"diff", "--name-status"}
+	switch opts.Renames {
+	case RenamesAndCopies:
+		args = append(args, "-M", "-C")
+	case RenamesOff:
+		args = append(args, "--no-renames")
+	}
+	out, err := r.run(DefaultDiffTimeout, append(args, from, to, "--")...)
+	if err != nil {
+		return c, fmt.Errorf("cannot compare %s and %s: %w", from, to, err)
+	}
+	c.Files = ParseNameStatus(out)
+
+	out, err = r.run(DefaultCmdTimeout, "log", "--format=%h%x09%s", "-n", strconv.Itoa(limits.MaxCommits), from+".."+to)
+	if err != nil {
+		return c, err
+	}
+	c.Commits = ParseCompareLog(out)
+
+	out, err = r.run(DefaultCmdTimeout, "rev-list", "--count", to+".."+from)
+	if err != nil {
+		return c, err
+	}
+	c.Behind, _ = strconv.Atoi(strings.TrimSpace(out))
+	return c, nil
+}
+
+// DiffRefsFile trả về diff của một file giữa hai ref
+func (r Runner) DiffRefsFile(from, to string, file CompareFile, opts DiffOptions) (string, error) {
+	args := append([]string{"diff"}, opts.Args()...)
+	args = append(args, from, to, "--")
+	if file.OldPath != "" {
+		args = append(args, file.OldPath)
+	}
+	return r.run(DefaultDiffTimeout, append(args, file.Path)...)
+}
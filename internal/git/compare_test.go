@@ -0,0 +1,49 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCompareRange(t *testing.T) {
+	tests := []struct {
+		in       string
+		from, to string
+		ok       bool
+	}{
+		{"v1.0..v2.0", "v1.0", "v2.0", true},
+		{" main...feature ", "main", "feature", true},
+		{"main..", "", "", false},
+		{"main", "", "", false},
+	}
+	for _, tt := range tests {
+		from, to, ok := ParseCompareRange(tt.in)
+		if from != tt.from || to != tt.to || ok != tt.ok {
+			t.Errorf("ParseCompareRange(%q) = %q, %q, %v; want %q, %q, %v", tt.in, from, to, ok, tt.from, tt.to, tt.ok)
+		}
+	}
+}
+
+func TestParseNameStatus(t *testing.T) {
+	out := "M\tREADME.md\nA\tnew.go\nR087\told/name.go\tnew/name.go\nD\tgone.txt\n"
+	want := []CompareFile{
+		{Status: "M", Path: "README.md"},
+		{Status: "A", Path: "new.go"},
+		{Status: "R", Path: "new/name.go", OldPath: "old/name.go"},
+		{Status: "D", Path: "gone.txt"},
+	}
+	if got := ParseNameStatus(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseNameStatus() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseCompareLog(t *testing.T) {
+	out := "abc1234\tAdd feature\ndef5678\tFix: tabs\tin subject\n"
+	want := []CompareCommit{
+		{Hash: "abc1234", Subject: "Add feature"},
+		{Hash: "def5678", Subject: "Fix: tabs\tin subject"},
+	}
+	if got := ParseCompareLog(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseCompareLog() = %+v, want %+v", got, want)
+	}
+}
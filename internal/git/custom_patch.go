@@ -0,0 +1,471 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FileDiff là diff của một file trong commit: các dòng header (diff --git,
+// index, ---/+++) và các hunk
+type FileDiff struct {
+	Path   string
+	Header []string
+	Hunks  []Hunk
+}
+
+// ParseFileDiffs tách output của git show/diff thành từng file
+func ParseFileDiffs(diff string) []FileDiff {
+	var files []FileDiff
+	var cur *FileDiff
+	var hunkLines []string
+
+	flushHunk := func() {
+		if cur != nil && len(hunkLines) > 0 {
+			cur.Hunks = append(cur.Hunks, parseHunk(len(cur.Hunks), strings.Join(hunkLines, "\n")))
+		}
+		hunkLines = nil
+	}
+	flushFile := func() {
+		flushHunk()
+		if cur != nil {
+			files = append(files, *cur)
+		}
+		cur = nil
+	}
+
+	for _, line := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flushFile()
+			cur = &FileDiff{Path: diffGitPath(line), Header: []string{line}}
+		case cur == nil:
+			continue
+		case strings.HasPrefix(line, "@@"):
+			flushHunk()
+			hunkLines = []string{line}
+		case len(hunkLines) > 0:
+			hunkLines = append(hunkLines, line)
+		default:
+			cur.Header = append(cur.Header, line)
+			if strings.HasPrefix(line, "+++ b/") {
+				cur.Path = strings.TrimPrefix(line, "+++ b/")
+			}
+		}
+	}
+	flushFile()
+	return files
+}
+
+// diffGitPath lấy path từ "diff --git a/x b/x"
+func diffGitPath(line string) string {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if i := strings.Index(rest, " b/"); i >= 0 {
+		return rest[i+3:]
+	}
+	return rest
+}
+
+// IsChangeLine kiểm tra dòng trong hunk có phải dòng thêm/xoá (chọn được vào patch)
+func IsChangeLine(line string) bool {
+	return strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-")
+}
+
+// PatchLineRef trỏ tới một dòng trong diff của commit: file, hunk, và dòng trong
+// Hunk.Content (0 là dòng header @@)
+type PatchLineRef struct {
+	File, Hunk, Line int
+}
+
+// patchCommit là diff của một commit cùng các dòng được chọn
+type patchCommit struct {
+	hash     string
+	age      int // vị trí trong log (lớn hơn = cũ hơn), dùng để sắp thứ tự khi ghép patch
+	files    []FileDiff
+	selected map[PatchLineRef]bool
+}
+
+// CustomPatch gom các file/hunk/dòng được chọn từ một hoặc nhiều commit thành
+// một patch, có thể apply vào index, apply ngược để gỡ khỏi commit, hoặc lưu file.
+type CustomPatch struct {
+	commits []*patchCommit
+}
+
+// NewCustomPatch tạo CustomPatch rỗng
+func NewCustomPatch() *CustomPatch {
+	return &CustomPatch{}
+}
+
+// AddCommit nạp diff của commit; giữ nguyên lựa chọn nếu commit đã được nạp
+func (p *CustomPatch) AddCommit(hash string, age int, diff string) {
+	if c := p.commit(hash); c != nil {
+		c.age = age
+		return
+	}
+	p.commits = append(p.commits, &patchCommit{
+		hash:     hash,
+		age:      age,
+		files:    ParseFileDiffs(diff),
+		selected: map[PatchLineRef]bool{},
+	})
+}
+
+// HasCommit kiểm tra diff của commit đã được nạp chưa
+func (p *CustomPatch) HasCommit(hash string) bool {
+	return p.commit(hash) != nil
+}
+
+// Files trả về diff theo file của commit
+func (p *CustomPatch) Files(hash string) []FileDiff {
+	if c := p.commit(hash); c != nil {
+		return c.files
+	}
+	return nil
+}
+
+// IsSelected kiểm tra một dòng có trong patch không
+func (p *CustomPatch) IsSelected(hash string, ref PatchLineRef) bool {
+	c := p.commit(hash)
+	return c != nil && c.selected[ref]
+}
+
+// ToggleLine chọn/bỏ một dòng thêm/xoá
+func (p *CustomPatch) ToggleLine(hash string, ref PatchLineRef) {
+	p.toggle(hash, func(r PatchLineRef) bool { return r == ref })
+}
+
+// ToggleHunk chọn cả hunk, hoặc bỏ chọn nếu cả hunk đã được chọn
+func (p *CustomPatch) ToggleHunk(hash string, file, hunk int) {
+	p.toggle(hash, func(r PatchLineRef) bool { return r.File == file && r.Hunk == hunk })
+}
+
+// ToggleFile chọn cả file, hoặc bỏ chọn nếu cả file đã được chọn
+func (p *CustomPatch) ToggleFile(hash string, file int) {
+	p.toggle(hash, func(r PatchLineRef) bool { return r.File == file })
+}
+
+// toggle chọn mọi dòng thay đổi thoả match; nếu tất cả đã được chọn thì bỏ chọn
+func (p *CustomPatch) toggle(hash string, match func(PatchLineRef) bool) {
+	c := p.commit(hash)
+	if c == nil {
+		return
+	}
+	var refs []PatchLineRef
+	allSelected := true
+	for fi, f := range c.files {
+		for hi, h := range f.Hunks {
+			for li, line := range strings.Split(h.Content, "\n") {
+				ref := PatchLineRef{File: fi, Hunk: hi, Line: li}
+				if li == 0 || !IsChangeLine(line) || !match(ref) {
+					continue
+				}
+				refs = append(refs, ref)
+				allSelected = allSelected && c.selected[ref]
+			}
+		}
+	}
+	for _, ref := range refs {
+		if allSelected {
+			delete(c.selected, ref)
+		} else {
+			c.selected[ref] = true
+		}
+	}
+}
+
+// Reset bỏ mọi lựa chọn (diff đã nạp được giữ lại để tiếp tục hiển thị)
+func (p *CustomPatch) Reset() {
+	for _, c := range p.commits {
+		c.selected = map[PatchLineRef]bool{}
+	}
+}
+
+// IsEmpty kiểm tra patch chưa có dòng nào
+func (p *CustomPatch) IsEmpty() bool {
+	return p.LineCount() == 0
+}
+
+// LineCount đếm số dòng thay đổi đã chọn
+func (p *CustomPatch) LineCount() int {
+	n := 0
+	for _, c := range p.commits {
+		n += len(c.selected)
+	}
+	return n
+}
+
+// Commits trả về các commit có dòng được chọn, cũ nhất trước
+func (p *CustomPatch) Commits() []string {
+	var hashes []string
+	for _, c := range p.sortedCommits() {
+		hashes = append(hashes, c.hash)
+	}
+	return hashes
+}
+
+// SingleCommit trả về commit duy nhất của patch (cần cho thao tác gỡ khỏi commit)
+func (p *CustomPatch) SingleCommit() (string, bool) {
+	commits := p.Commits()
+	if len(commits) != 1 {
+		return "", false
+	}
+	return commits[0], true
+}
+
+// Patch render patch từ các dòng đã chọn, commit cũ nhất trước để có thể apply tuần tự
+func (p *CustomPatch) Patch() string {
+	var b strings.Builder
+	for _, c := range p.sortedCommits() {
+		for fi, f := range c.files {
+			b.WriteString(buildFilePatch(f, func(hunk, line int) bool {
+				return c.selected[PatchLineRef{File: fi, Hunk: hunk, Line: line}]
+			}))
+		}
+	}
+	return b.String()
+}
+
+func (p *CustomPatch) commit(hash string) *patchCommit {
+	for _, c := range p.commits {
+		if c.hash == hash {
+			return c
+		}
+	}
+	return nil
+}
+
+func (p *CustomPatch) sortedCommits() []*patchCommit {
+	var out []*patchCommit
+	for _, c := range p.commits {
+		if len(c.selected) > 0 {
+			out = append(out, c)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].age > out[j].age })
+	return out
+}
+
+// buildFilePatch dựng patch cho một file chỉ gồm các dòng được chọn, rỗng nếu không có dòng nào
+func buildFilePatch(f FileDiff, selected func(hunk, line int) bool) string {
+	var hunks []string
+	complete := true
+	offset := 0
+	for hi, h := range f.Hunks {
+		body, oldCount, newCount, changed, all := buildPartialHunk(h, func(line int) bool { return selected(hi, line) })
+		complete = complete && all
+		if !changed {
+			continue
+		}
+		// Khi count = 0, start là dòng ngay trước vị trí chèn/xoá
+		newStart := h.OldStart + offset
+		if oldCount == 0 {
+			newStart++
+		}
+		if newCount == 0 {
+			newStart--
+		}
+		header := fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, oldCount, newStart, newCount)
+		offset += newCount - oldCount
+		hunks = append(hunks, header+"\n"+body)
+	}
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	header := f.Header
+	if !complete {
+		header = partialFileHeader(f)
+	}
+	return strings.Join(header, "\n") + "\n" + strings.Join(hunks, "\n") + "\n"
+}
+
+// buildPartialHunk dựng lại hunk với các dòng được chọn: dòng "-" không chọn thành
+// context, dòng "+" không chọn bị bỏ. changed=false khi không có dòng thay đổi nào.
+func buildPartialHunk(h Hunk, selected func(line int) bool) (body string, oldCount, newCount int, changed, all bool) {
+	lines := strings.Split(h.Content, "\n")
+	var out []string
+	all = true
+	lastKept := false
+	for i := 1; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "+"):
+			if selected(i) {
+				out = append(out, line)
+				newCount++
+				changed = true
+				lastKept = true
+			} else {
+				all = false
+				lastKept = false
+			}
+		case strings.HasPrefix(line, "-"):
+			if selected(i) {
+				out = append(out, line)
+				oldCount++
+				changed = true
+			} else {
+				all = false
+				out = append(out, " "+line[1:])
+				oldCount++
+				newCount++
+			}
+			lastKept = true
+		case strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file" đi theo dòng ngay trước nó
+			if lastKept {
+				out = append(out, line)
+			}
+		case line == "" && i == len(lines)-1:
+			// dòng trống cuối do Split, không phải context
+		default:
+			out = append(out, line)
+			oldCount++
+			newCount++
+			lastKept = true
+		}
+	}
+	return strings.Join(out, "\n"), oldCount, newCount, changed, all
+}
+
+// partialFileHeader: khi chỉ chọn một phần của file, patch chỉ sửa nội dung file.
+// File bị xoá không còn bị xoá hẳn nên bỏ "deleted file mode" và đổi "+++ /dev/null"
+// thành "+++ b/path"; file đổi tên/copy bỏ header rename/copy và chỉ sửa file ở path
+// mới, để apply ngược không đổi tên file về path cũ.
+func partialFileHeader(f FileDiff) []string {
+	renamed := false
+	for _, line := range f.Header {
+		if strings.HasPrefix(line, "rename from ") || strings.HasPrefix(line, "copy from ") {
+			renamed = true
+		}
+	}
+	var out []string
+	for _, line := range f.Header {
+		switch {
+		case strings.HasPrefix(line, "deleted file mode"):
+			continue
+		case line == "+++ /dev/null":
+			out = append(out, "+++ b/"+f.Path)
+		case !renamed:
+			out = append(out, line)
+		case strings.HasPrefix(line, "similarity index"), strings.HasPrefix(line, "dissimilarity index"),
+			strings.HasPrefix(line, "rename "), strings.HasPrefix(line, "copy "):
+			continue
+		case strings.HasPrefix(line, "diff --git "):
+			out = append(out, "diff --git a/"+f.Path+" b/"+f.Path)
+		case strings.HasPrefix(line, "--- "):
+			out = append(out, "--- a/"+f.Path)
+		default:
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// CommitDiff trả về diff của commit so với parent đầu tiên (không có message)
+func (r Runner) CommitDiff(hash string) (string, error) {
+	return r.run(DefaultDiffTimeout, "show", "--format=", "--no-color", "--no-ext-diff", "--diff-merges=first-parent", hash)
+}
+
+// ApplyCustomPatch apply patch qua stdin vào working tree, hoặc chỉ vào index khi
+// cached. reverse apply ngược (gỡ các thay đổi trong patch).
+func (r Runner) ApplyCustomPatch(patch string, cached, reverse bool) error {
+	args := []string{"apply"}
+	if cached {
+		args = append(args, "--cached")
+	}
+	if reverse {
+		args = append(args, "-R")
+	}
+	_, err := r.runWithStdin(patch, DefaultDiffTimeout, append(args, "-")...)
+	return err
+}
+
+// HasStagedChanges kiểm tra index có khác HEAD không
+func (r Runner) HasStagedChanges() (bool, error) {
+	_, err := r.run(DefaultCmdTimeout, "diff", "--cached", "--quiet")
+	if err == nil {
+		return false, nil
+	}
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) {
+		return true, nil
+	}
+	return false, err
+}
+
+// StartRemovePatchFromCommit gỡ patch khỏi commit hash (sửa lại lịch sử): gỡ patch
+// khỏi index rồi bắt đầu commit sửa lại với output hook được stream, amend khi hash là
+// HEAD, ngược lại fixup! commit (autosquash = true) cần StartRebaseAutosquash và
+// FinishAmendCommitWithStaged sau đó. Gọi FinishRemovePatchFromCommit với lỗi của các
+// bước đó. Các thay đổi bị gỡ vẫn còn trong working tree dưới dạng unstaged để không
+// mất dữ liệu. Commit sửa lại chạy hook như commit thường, noVerify bỏ qua chúng.
+func (r Runner) StartRemovePatchFromCommit(hash, patch string, noVerify bool) (s *Stream, autosquash bool, err error) {
+	staged, err := r.HasStagedChanges()
+	if err != nil {
+		return nil, false, err
+	}
+	if staged {
+		return nil, false, fmt.Errorf("unstage your changes first: removing a patch from a commit uses the index")
+	}
+	if err := r.ApplyCustomPatch(patch, true, true); err != nil {
+		return nil, false, fmt.Errorf("patch does not apply in reverse on top of HEAD: %w", err)
+	}
+
+	head, _ := r.run(DefaultCmdTimeout, "rev-parse", "HEAD")
+	full, _ := r.run(DefaultCmdTimeout, "rev-parse", hash)
+	if strings.TrimSpace(head) == strings.TrimSpace(full) {
+		s, err = r.StreamCommit("", true, noVerify)
+	} else {
+		s, err = r.StartAmendCommitWithStaged(hash, noVerify)
+		autosquash = true
+	}
+	if err != nil {
+		return nil, false, r.FinishRemovePatchFromCommit(patch, err)
+	}
+	return s, autosquash, nil
+}
+
+// FinishRemovePatchFromCommit trả index về như cũ khi commit sửa lại hoặc autosquash
+// thất bại (kể cả bị huỷ)
+func (r Runner) FinishRemovePatchFromCommit(patch string, err error) error {
+	if err != nil {
+		_ = r.ApplyCustomPatch(patch, true, false)
+	}
+	return err
+}
+
+// StartCommitMovedPatch stage lại patch vừa được gỡ khỏi hash rồi bắt đầu commit mới
+// trên HEAD với output hook được stream. Lỗi sau khi stage là *PatchMovedError vì
+// lịch sử đã bị sửa.
+func (r Runner) StartCommitMovedPatch(hash, patch, message string, noVerify bool) (*Stream, error) {
+	if err := r.ApplyCustomPatch(patch, true, false); err != nil {
+		return nil, fmt.Errorf("patch was removed from %s but could not be staged again (changes are in the working tree): %w", hash, err)
+	}
+	s, err := r.StreamCommit(message, false, noVerify)
+	if err != nil {
+		return nil, &PatchMovedError{Hash: hash, Err: err}
+	}
+	return s, nil
+}
+
+// PatchMovedError là lỗi commit mới khi di chuyển patch: patch đã được gỡ khỏi
+// Hash và đang nằm trong index
+type PatchMovedError struct {
+	Hash string
+	Err  error
+}
+
+func (e *PatchMovedError) Error() string {
+	return fmt.Sprintf("patch was removed from %s and is left staged, commit it manually: %v", e.Hash, e.Err)
+}
+
+func (e *PatchMovedError) Unwrap() error {
+	return e.Err
+}
+
+// SaveCustomPatch ghi patch ra file (tạo thư mục nếu cần)
+func SaveCustomPatch(path, patch string) error {
+	return writePatchFile(path, patch)
+}
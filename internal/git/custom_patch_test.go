@@ -0,0 +1,289 @@
+package git
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const customPatchDiff = `diff --git a/a.txt b/a.txt
+index 1111111..2222222 100644
+--- a/a.txt
++++ b/a.txt
+@@ -1,3 +1,3 @@
+ one
+-two
++TWO
+ three
+@@ -10,2 +10,3 @@ func x
+ ten
++ten and a half
+ eleven
+diff --git a/gone.txt b/gone.txt
+deleted file mode 100644
+index 3333333..0000000
+--- a/gone.txt
++++ /dev/null
+@@ -1,2 +0,0 @@
+-first
+-second
+`
+
+func TestParseFileDiffs(t *testing.T) {
+	files := ParseFileDiffs(customPatchDiff)
+	if len(files) != 2 {
+		t.Fatalf("files = %d, want 2", len(files))
+	}
+	if files[0].Path != "a.txt" || len(files[0].Hunks) != 2 || len(files[0].Header) != 4 {
+		t.Errorf("a.txt parsed as %+v", files[0])
+	}
+	if files[1].Path != "gone.txt" || len(files[1].Hunks) != 1 {
+		t.Errorf("gone.txt parsed as %+v", files[1])
+	}
+	if files[0].Hunks[1].OldStart != 10 {
+		t.Errorf("second hunk OldStart = %d, want 10", files[0].Hunks[1].OldStart)
+	}
+}
+
+func TestCustomPatchToggle(t *testing.T) {
+	p := NewCustomPatch()
+	p.AddCommit("abc", 0, customPatchDiff)
+
+	p.ToggleFile("abc", 0)
+	if got := p.LineCount(); got != 3 {
+		t.Fatalf("LineCount after ToggleFile = %d, want 3", got)
+	}
+	p.ToggleHunk("abc", 0, 1)
+	if got := p.LineCount(); got != 2 {
+		t.Errorf("LineCount after unselecting hunk = %d, want 2", got)
+	}
+	p.ToggleHunk("abc", 0, 1)
+	p.ToggleFile("abc", 0)
+	if !p.IsEmpty() {
+		t.Errorf("toggling a fully selected file should unselect it, got %d lines", p.LineCount())
+	}
+
+	// Context và header @@ không chọn được
+	p.ToggleLine("abc", PatchLineRef{File: 0, Hunk: 0, Line: 1})
+	p.ToggleLine("abc", PatchLineRef{File: 0, Hunk: 0, Line: 0})
+	if !p.IsEmpty() {
+		t.Errorf("context lines should not be selectable")
+	}
+
+	// AddCommit lần nữa giữ lựa chọn
+	p.ToggleLine("abc", PatchLineRef{File: 0, Hunk: 0, Line: 2})
+	p.AddCommit("abc", 0, customPatchDiff)
+	if !p.IsSelected("abc", PatchLineRef{File: 0, Hunk: 0, Line: 2}) {
+		t.Error("AddCommit should keep existing selections")
+	}
+}
+
+func TestCustomPatchPartialHunk(t *testing.T) {
+	p := NewCustomPatch()
+	p.AddCommit("abc", 0, customPatchDiff)
+	// Chỉ chọn "+TWO": "-two" thành context
+	p.ToggleLine("abc", PatchLineRef{File: 0, Hunk: 0, Line: 3})
+	// Cả hunk thứ hai
+	p.ToggleHunk("abc", 0, 1)
+
+	want := `diff --git a/a.txt b/a.txt
+index 1111111..2222222 100644
+--- a/a.txt
++++ b/a.txt
+@@ -1,3 +1,4 @@
+ one
+ two
++TWO
+ three
+@@ -10,2 +11,3 @@
+ ten
++ten and a half
+ eleven
+`
+	if got := p.Patch(); got != want {
+		t.Errorf("Patch() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCustomPatchPartialDeletedFile(t *testing.T) {
+	p := NewCustomPatch()
+	p.AddCommit("abc", 0, customPatchDiff)
+	p.ToggleLine("abc", PatchLineRef{File: 1, Hunk: 0, Line: 1})
+
+	want := `diff --git a/gone.txt b/gone.txt
+index 3333333..0000000
+--- a/gone.txt
++++ b/gone.txt
+@@ -1,2 +1,1 @@
+-first
+ second
+`
+	if got := p.Patch(); got != want {
+		t.Errorf("Patch() =\n%s\nwant\n%s", got, want)
+	}
+
+	p.ToggleFile("abc", 1)
+	want = `diff --git a/gone.txt b/gone.txt
+deleted file mode 100644
+index 3333333..0000000
+--- a/gone.txt
++++ /dev/null
+@@ -1,2 +0,0 @@
+-first
+-second
+`
+	if got := p.Patch(); got != want {
+		t.Errorf("full deletion Patch() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// chọn một phần của file đổi tên: patch chỉ sửa file ở path mới, không đổi tên
+func TestCustomPatchPartialRenamedFile(t *testing.T) {
+	diff := `diff --git a/old.txt b/new.txt
+similarity index 80%
+rename from old.txt
+rename to new.txt
+index 1111111..2222222 100644
+--- a/old.txt
++++ b/new.txt
+@@ -1,2 +1,2 @@
+-one
++ONE
+ two
+@@ -5,2 +5,2 @@
+ five
+-six
++SIX
+`
+	p := NewCustomPatch()
+	p.AddCommit("abc", 0, diff)
+	p.ToggleHunk("abc", 0, 1)
+
+	want := `diff --git a/new.txt b/new.txt
+index 1111111..2222222 100644
+--- a/new.txt
++++ b/new.txt
+@@ -5,2 +5,2 @@
+ five
+-six
++SIX
+`
+	if got := p.Patch(); got != want {
+		t.Errorf("Patch() =\n%s\nwant\n%s", got, want)
+	}
+
+	// chọn cả file thì giữ nguyên rename
+	p.ToggleFile("abc", 0)
+	if got := p.Patch(); !strings.HasPrefix(got, "diff --git a/old.txt b/new.txt\nsimilarity index 80%\nrename from old.txt\nrename to new.txt\n") {
+		t.Errorf("full rename Patch() =\n%s", got)
+	}
+}
+
+func TestCustomPatchNoNewlineMarker(t *testing.T) {
+	diff := `diff --git a/n.txt b/n.txt
+index 1111111..2222222 100644
+--- a/n.txt
++++ b/n.txt
+@@ -1 +1 @@
+-old
+\ No newline at end of file
++new
+\ No newline at end of file
+`
+	p := NewCustomPatch()
+	p.AddCommit("abc", 0, diff)
+	p.ToggleLine("abc", PatchLineRef{File: 0, Hunk: 0, Line: 1})
+
+	want := `diff --git a/n.txt b/n.txt
+index 1111111..2222222 100644
+--- a/n.txt
++++ b/n.txt
+@@ -1,1 +0,0 @@
+-old
+\ No newline at end of file
+`
+	if got := p.Patch(); got != want {
+		t.Errorf("Patch() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCustomPatchCommitOrder(t *testing.T) {
+	p := NewCustomPatch()
+	p.AddCommit("new", 0, customPatchDiff)
+	p.AddCommit("old", 3, customPatchDiff)
+	p.AddCommit("unused", 5, customPatchDiff)
+	p.ToggleFile("new", 1)
+	p.ToggleFile("old", 0)
+
+	if got, want := p.Commits(), []string{"old", "new"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Commits() = %v, want %v", got, want)
+	}
+	if _, ok := p.SingleCommit(); ok {
+		t.Error("SingleCommit should fail for a patch spanning two commits")
+	}
+	p.Reset()
+	if !p.IsEmpty() || len(p.Commits()) != 0 {
+		t.Error("Reset should drop all selections")
+	}
+	if !p.HasCommit("old") {
+		t.Error("Reset should keep loaded diffs")
+	}
+}
+
+// removePatch chạy các bước gỡ patch khỏi commit như app
+func removePatch(r Runner, hash, patch string) error {
+	s, autosquash, err := r.StartRemovePatchFromCommit(hash, patch, true)
+	if err != nil {
+		return err
+	}
+	_, err = s.Drain()
+	if err == nil && autosquash {
+		_, err = drainStream(r.StartRebaseAutosquash(hash))
+		err = r.FinishAmendCommitWithStaged(r.FinishRebaseAutosquash(err))
+	}
+	return r.FinishRemovePatchFromCommit(patch, err)
+}
+
+func TestRemovePartialRenamePatchFromCommit(t *testing.T) {
+	r := initTestRepo(t, map[string]string{"old.txt": "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"})
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := r.run(DefaultCmdTimeout, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(out)
+	}
+	git("mv", "old.txt", "new.txt")
+	writeTestFile(t, filepath.Join(r.RepoRoot, "new.txt"), "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n")
+	git("add", "new.txt")
+	git("-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "-m", "rename")
+	hash := git("rev-parse", "HEAD")
+	writeTestFile(t, filepath.Join(r.RepoRoot, "c.txt"), "c\n")
+	git("add", "c.txt")
+	git("-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "-m", "add c")
+
+	diff, err := r.CommitDiff(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewCustomPatch()
+	p.AddCommit(hash, 0, diff)
+	p.ToggleHunk(hash, 0, 1)
+	if err := removePatch(r, hash, p.Patch()); err != nil {
+		t.Fatal(err)
+	}
+
+	// rename vẫn giữ, chỉ hunk được chọn bị gỡ khỏi commit
+	if files := git("ls-tree", "--name-only", "HEAD~1"); files != "new.txt" {
+		t.Errorf("files in rewritten commit = %q, want new.txt", files)
+	}
+	if got := git("show", "HEAD~1:new.txt"); got != "one\n2\n3\n4\n5\n6\n7\n8\n9\n10" {
+		t.Errorf("new.txt in rewritten commit = %q", got)
+	}
+	// phần bị gỡ còn trong working tree
+	if got := fileContent(filepath.Join(r.RepoRoot, "new.txt")); got != "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n" {
+		t.Errorf("new.txt in working tree = %q", got)
+	}
+}
@@ -0,0 +1,493 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// diffSpillBytes: diff lớn hơn ngưỡng này được ghi ra file tạm thay vì giữ trong RAM
+	diffSpillBytes = 4 << 20
+	// maxDiffLineBytes cắt các dòng cực dài (e.g. file minified) để bộ nhớ đọc dòng có giới hạn
+	maxDiffLineBytes = 64 << 10
+	// diffPublishLines: số dòng gom lại trước khi công bố cho người đọc
+	diffPublishLines = 2048
+)
+
+// DiffAnchor là vị trí header của một file trong diff
+type DiffAnchor struct {
+	Line int // index dòng "diff --git"
+	Path string
+}
+
+// DiffIndex đánh dấu vị trí các file và hunk trong diff để nhảy nhanh
+type DiffIndex struct {
+	Files []DiffAnchor
+	Hunks []int // index dòng "@@"
+}
+
+// IndexDiff lập index cho diff đã có đầy đủ trong bộ nhớ
+func IndexDiff(lines []string) DiffIndex {
+	var idx DiffIndex
+	for i, line := range lines {
+		idx.add(i, line)
+	}
+	return idx
+}
+
+func (x *DiffIndex) add(i int, line string) {
+	switch {
+	case strings.HasPrefix(line, "diff "):
+		x.Files = append(x.Files, DiffAnchor{Line: i, Path: diffGitPath(line)})
+	case strings.HasPrefix(line, "@@"):
+		x.Hunks = append(x.Hunks, i)
+	}
+}
+
+// FileAt trả về file chứa dòng line, false khi line nằm trước file đầu tiên
+func (x DiffIndex) FileAt(line int) (DiffAnchor, bool) {
+	i := sort.Search(len(x.Files), func(i int) bool { return x.Files[i].Line > line }) - 1
+	if i < 0 {
+		return DiffAnchor{}, false
+	}
+	return x.Files[i], true
+}
+
+// InHunk kiểm tra dòng line có nằm trong một hunk (sau "@@" của file hiện tại) không
+func (x DiffIndex) InHunk(line int) bool {
+	h := sort.SearchInts(x.Hunks, line+1) - 1
+	if h < 0 {
+		return false
+	}
+	f, ok := x.FileAt(line)
+	return !ok || x.Hunks[h] > f.Line
+}
+
+// NextHunk trả về dòng "@@" đầu tiên sau line, -1 khi không còn
+func (x DiffIndex) NextHunk(line int) int {
+	return nextAfter(x.Hunks, line)
+}
+
+// PrevHunk trả về dòng "@@" gần nhất trước line, -1 khi không có
+func (x DiffIndex) PrevHunk(line int) int {
+	return prevBefore(x.Hunks, line)
+}
+
+// NextFile trả về dòng header của file tiếp theo sau line, -1 khi không còn
+func (x DiffIndex) NextFile(line int) int {
+	return nextAfter(x.fileLines(), line)
+}
+
+// PrevFile trả về dòng header của file gần nhất trước line, -1 khi không có
+func (x DiffIndex) PrevFile(line int) int {
+	return prevBefore(x.fileLines(), line)
+}
+
+func (x DiffIndex) fileLines() []int {
+	lines := make([]int, len(x.Files))
+	for i, f := range x.Files {
+		lines[i] = f.Line
+	}
+	return lines
+}
+
+func nextAfter(sorted []int, line int) int {
+	i := sort.SearchInts(sorted, line+1)
+	if i >= len(sorted) {
+		return -1
+	}
+	return sorted[i]
+}
+
+func prevBefore(sorted []int, line int) int {
+	i := sort.SearchInts(sorted, line) - 1
+	if i < 0 {
+		return -1
+	}
+	return sorted[i]
+}
+
+// DiffBuffer nhận output diff theo stream và cho đọc bất kỳ đoạn dòng nào trong
+// lúc đang nhận. Nội dung nằm trong RAM tới diffSpillBytes rồi chuyển ra file tạm;
+// bộ nhớ chỉ giữ offset từng dòng và DiffIndex.
+type DiffBuffer struct {
+	mu      sync.Mutex
+	mem     []byte
+	file    *os.File
+	size    int64   // số byte đã công bố (kết thúc ở cuối một dòng)
+	offsets []int64 // offset đầu mỗi dòng đã công bố
+	index   DiffIndex
+	done    bool
+	err     error
+	changed chan struct{} // đóng mỗi lần công bố thêm dòng
+
+	spillAt int64
+	cancel  context.CancelFunc
+	closed  bool
+
+	// summarize thay phần diff của một file (binary, LFS pointer) trước khi công bố
+	// và index; nil thì giữ nguyên
+	summarize func(section []string) []string
+}
+
+func newDiffBuffer() *DiffBuffer {
+	return &DiffBuffer{changed: make(chan struct{}), spillAt: diffSpillBytes}
+}
+
+// StreamDiff chạy lệnh git (diff/show) và stream stdout vào DiffBuffer. Process
+// thuộc về buffer: Close huỷ process và xoá file tạm. Context của Runner chỉ
+// dùng tới khi process khởi chạy xong.
+func (r Runner) StreamDiff(args ...string) (*DiffBuffer, error) {
+	return r.streamDiff(nil, args...)
+}
+
+// streamSummarizedDiff như StreamDiff nhưng tóm tắt file binary và LFS pointer
+// như SummarizeBinaryDiff ngay khi nhận
+func (r Runner) streamSummarizedDiff(worktree, smudgeLFS bool, args ...string) (*DiffBuffer, error) {
+	return r.streamDiff(func(br Runner) func([]string) []string {
+		return br.binarySummarizer(worktree, smudgeLFS)
+	}, args...)
+}
+
+// streamDiff: summarizer nhận Runner gắn với vòng đời của buffer (huỷ khi Close),
+// vì các section phía sau được tóm tắt sau khi context của Runner gọi đã kết thúc
+func (r Runner) streamDiff(summarizer func(br Runner) func(section []string) []string, args ...string) (*DiffBuffer, error) {
+	if err := r.Context().Err(); err != nil {
+		return nil, fmt.Errorf("git %s: %w", strings.Join(args, " "), ErrCancelled)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := exec.CommandContext(ctx, "git", args...)
+	if r.RepoRoot != "" {
+		cmd.Dir = r.RepoRoot
+	}
+	configureProcessGroup(cmd)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+
+	b := newDiffBuffer()
+	b.cancel = cancel
+	if summarizer != nil {
+		b.summarize = summarizer(r.WithContext(ctx))
+	}
+	go func() {
+		readErr := b.readFrom(stdout)
+		if readErr != nil {
+			// không đọc nữa thì git sẽ kẹt khi ghi vào pipe
+			cancel()
+		}
+		waitErr := cmd.Wait()
+		switch {
+		case readErr != nil:
+		case ctx.Err() != nil:
+			readErr = fmt.Errorf("git %s: %w", strings.Join(args, " "), ErrCancelled)
+		case waitErr != nil:
+			text := strings.TrimSpace(stderr.String())
+			if text == "" {
+				text = waitErr.Error()
+			}
+			readErr = &CommandError{Args: args, Stderr: stderr.String(), Text: text}
+		}
+		b.finish(readErr)
+	}()
+	return b, nil
+}
+
+// readFrom đọc từng dòng từ r cho tới EOF
+func (b *DiffBuffer) readFrom(r io.Reader) error {
+	br := bufio.NewReaderSize(r, maxDiffLineBytes)
+	var pending []byte
+	var pendingLines []string
+	var pendingOffsets []int64
+	base := int64(0)
+
+	publish := func() error {
+		if len(pendingLines) == 0 {
+			return nil
+		}
+		err := b.publish(pending, pendingOffsets, pendingLines)
+		base += int64(len(pending))
+		pending, pendingLines, pendingOffsets = pending[:0], pendingLines[:0], pendingOffsets[:0]
+		return err
+	}
+
+	emit := func(line []byte) {
+		pendingOffsets = append(pendingOffsets, base+int64(len(pending)))
+		pending = append(pending, line...)
+		pending = append(pending, '\n')
+		// chỉ giữ phần đầu dòng cho index (đủ cho "diff --git"/"@@")
+		head := line
+		if len(head) > 512 {
+			head = head[:512]
+		}
+		pendingLines = append(pendingLines, string(head))
+	}
+
+	// section giữ lại phần diff của file hiện tại khi nó còn có thể là binary/LFS
+	// pointer (luôn ngắn), để tóm tắt cả section trước khi công bố
+	var section []string
+	flushSection := func(summarize bool) {
+		lines := section
+		if summarize {
+			lines = b.summarize(section)
+		}
+		for _, line := range lines {
+			emit([]byte(line))
+		}
+		section = nil
+	}
+
+	for {
+		// Sắp phải chờ git thì công bố những gì đã có để UI hiển thị ngay
+		if br.Buffered() == 0 || len(pendingLines) >= diffPublishLines {
+			if err := publish(); err != nil {
+				return err
+			}
+		}
+		line, err := readDiffLine(br)
+		if len(line) > 0 || err == nil {
+			if b.summarize != nil && bytes.HasPrefix(line, []byte("diff ")) && section != nil {
+				flushSection(true)
+			}
+			switch {
+			case b.summarize != nil && bytes.HasPrefix(line, []byte("diff --git ")):
+				section = []string{string(line)}
+			case section != nil:
+				section = append(section, string(line))
+				if !summaryCandidate(section) {
+					flushSection(false)
+				}
+			default:
+				emit(line)
+			}
+		}
+		if err == io.EOF {
+			if section != nil {
+				flushSection(true)
+			}
+			return publish()
+		}
+		if err != nil {
+			flushSection(false)
+			publish()
+			return err
+		}
+	}
+}
+
+// readDiffLine đọc một dòng (không gồm \n), phần vượt maxDiffLineBytes bị bỏ
+func readDiffLine(br *bufio.Reader) ([]byte, error) {
+	line, err := br.ReadSlice('\n')
+	out := append([]byte(nil), bytes.TrimSuffix(line, []byte("\n"))...)
+	for err == bufio.ErrBufferFull {
+		_, err = br.ReadSlice('\n')
+	}
+	return out, err
+}
+
+// publish ghi dữ liệu đã gom (lines là nội dung để index) và báo cho người đọc
+func (b *DiffBuffer) publish(data []byte, offsets []int64, lines []string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return errors.New("diff buffer closed")
+	}
+
+	if b.file == nil && b.size+int64(len(data)) > b.spillAt {
+		f, err := os.CreateTemp("", "gitzen-diff-*")
+		if err != nil {
+			return fmt.Errorf("cannot create temp file for diff: %w", err)
+		}
+		if _, err := f.Write(b.mem); err != nil {
+			f.Close()
+			os.Remove(f.Name())
+			return fmt.Errorf("cannot write diff to temp file: %w", err)
+		}
+		b.file, b.mem = f, nil
+	}
+	if b.file != nil {
+		if _, err := b.file.Write(data); err != nil {
+			return fmt.Errorf("cannot write diff to temp file: %w", err)
+		}
+	} else {
+		b.mem = append(b.mem, data...)
+	}
+
+	for i, line := range lines {
+		b.index.add(len(b.offsets)+i, line)
+	}
+	b.offsets = append(b.offsets, offsets...)
+	b.size += int64(len(data))
+	close(b.changed)
+	b.changed = make(chan struct{})
+	return nil
+}
+
+func (b *DiffBuffer) finish(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.done = true
+	b.err = err
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+// Len trả về số dòng đã nhận
+func (b *DiffBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.offsets)
+}
+
+// Done cho biết git đã kết thúc chưa và lỗi của nó (nếu có)
+func (b *DiffBuffer) Done() (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.done, b.err
+}
+
+// Changed trả về channel được đóng khi có thêm dòng hoặc khi stream kết thúc
+func (b *DiffBuffer) Changed() <-chan struct{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.changed
+}
+
+// WaitLines chờ tới khi có hơn n dòng, stream kết thúc hoặc ctx bị huỷ
+func (b *DiffBuffer) WaitLines(ctx context.Context, n int) {
+	for {
+		b.mu.Lock()
+		ready := b.done || len(b.offsets) > n
+		changed := b.changed
+		b.mu.Unlock()
+		if ready {
+			return
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Lines đọc tối đa n dòng bắt đầu từ start
+func (b *DiffBuffer) Lines(start, n int) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if start < 0 {
+		start = 0
+	}
+	end := min(start+n, len(b.offsets))
+	if start >= end {
+		return nil
+	}
+	from := b.offsets[start]
+	to := b.size
+	if end < len(b.offsets) {
+		to = b.offsets[end]
+	}
+
+	var data []byte
+	if b.file != nil {
+		data = make([]byte, to-from)
+		if _, err := b.file.ReadAt(data, from); err != nil && err != io.EOF {
+			return nil
+		}
+	} else {
+		data = b.mem[from:to]
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+// String trả về toàn bộ nội dung đã nhận (dùng cho diff nhỏ)
+func (b *DiffBuffer) String() string {
+	return strings.Join(b.Lines(0, b.Len()), "\n")
+}
+
+// Files trả về bản sao danh sách file đã nhận
+func (b *DiffBuffer) Files() []DiffAnchor {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]DiffAnchor(nil), b.index.Files...)
+}
+
+// FileAt xem DiffIndex.FileAt
+func (b *DiffBuffer) FileAt(line int) (DiffAnchor, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.index.FileAt(line)
+}
+
+// InHunk xem DiffIndex.InHunk
+func (b *DiffBuffer) InHunk(line int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.index.InHunk(line)
+}
+
+// NextHunk xem DiffIndex.NextHunk
+func (b *DiffBuffer) NextHunk(line int) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.index.NextHunk(line)
+}
+
+// PrevHunk xem DiffIndex.PrevHunk
+func (b *DiffBuffer) PrevHunk(line int) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.index.PrevHunk(line)
+}
+
+// NextFile xem DiffIndex.NextFile
+func (b *DiffBuffer) NextFile(line int) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.index.NextFile(line)
+}
+
+// PrevFile xem DiffIndex.PrevFile
+func (b *DiffBuffer) PrevFile(line int) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.index.PrevFile(line)
+}
+
+// Close huỷ git nếu còn chạy và xoá file tạm. Gọi nhiều lần không sao.
+func (b *DiffBuffer) Close() {
+	if b.cancel != nil {
+		b.cancel()
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	if b.file != nil {
+		b.file.Close()
+		os.Remove(b.file.Name())
+		b.file = nil
+	}
+	b.mem = nil
+	b.offsets = nil
+	b.size = 0
+}
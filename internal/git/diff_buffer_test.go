@@ -0,0 +1,259 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const bufferTestDiff = `commit abc
+Author: a
+
+diff --git a/one.go b/one.go
+--- a/one.go
++++ b/one.go
+@@ -1,2 +1,2 @@
+-a
++b
+ c
+@@ -10 +10 @@
+-x
++y
+diff --git a/two.txt b/two.txt
+--- a/two.txt
++++ b/two.txt
+@@ -1 +1 @@
+-p
++q
+`
+
+func TestIndexDiff(t *testing.T) {
+	lines := strings.Split(strings.TrimSuffix(bufferTestDiff, "\n"), "\n")
+	idx := IndexDiff(lines)
+
+	wantFiles := []DiffAnchor{{Line: 3, Path: "one.go"}, {Line: 13, Path: "two.txt"}}
+	if !reflect.DeepEqual(idx.Files, wantFiles) {
+		t.Fatalf("Files = %+v, want %+v", idx.Files, wantFiles)
+	}
+	if want := []int{6, 10, 16}; !reflect.DeepEqual(idx.Hunks, want) {
+		t.Fatalf("Hunks = %v, want %v", idx.Hunks, want)
+	}
+
+	if idx.InHunk(1) || idx.InHunk(4) || idx.InHunk(14) {
+		t.Error("header lines should not be in a hunk")
+	}
+	if !idx.InHunk(7) || !idx.InHunk(12) || !idx.InHunk(17) {
+		t.Error("content lines should be in a hunk")
+	}
+	if f, ok := idx.FileAt(11); !ok || f.Path != "one.go" {
+		t.Errorf("FileAt(11) = %+v, %v", f, ok)
+	}
+	if _, ok := idx.FileAt(0); ok {
+		t.Error("FileAt(0) should be before the first file")
+	}
+
+	if got := idx.NextHunk(6); got != 10 {
+		t.Errorf("NextHunk(6) = %d, want 10", got)
+	}
+	if got := idx.PrevHunk(10); got != 6 {
+		t.Errorf("PrevHunk(10) = %d, want 6", got)
+	}
+	if got := idx.NextHunk(16); got != -1 {
+		t.Errorf("NextHunk(16) = %d, want -1", got)
+	}
+	if got := idx.NextFile(0); got != 3 {
+		t.Errorf("NextFile(0) = %d, want 3", got)
+	}
+	if got := idx.PrevFile(13); got != 3 {
+		t.Errorf("PrevFile(13) = %d, want 3", got)
+	}
+}
+
+func TestDiffBufferReadFrom(t *testing.T) {
+	for _, spillAt := range []int64{diffSpillBytes, 16} {
+		b := newDiffBuffer()
+		b.spillAt = spillAt
+		if err := b.readFrom(strings.NewReader(bufferTestDiff)); err != nil {
+			t.Fatal(err)
+		}
+		b.finish(nil)
+
+		if got := b.Len(); got != 19 {
+			t.Fatalf("spill %d: Len = %d, want 19", spillAt, got)
+		}
+		if got := b.String(); got != strings.TrimSuffix(bufferTestDiff, "\n") {
+			t.Errorf("spill %d: String mismatch:\n%s", spillAt, got)
+		}
+		if got, want := b.Lines(6, 3), []string{"@@ -1,2 +1,2 @@", "-a", "+b"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("spill %d: Lines(6, 3) = %q, want %q", spillAt, got, want)
+		}
+		if got := b.Lines(17, 10); !reflect.DeepEqual(got, []string{"-p", "+q"}) {
+			t.Errorf("spill %d: Lines past end = %q", spillAt, got)
+		}
+		if got := b.Lines(30, 5); got != nil {
+			t.Errorf("spill %d: Lines out of range = %q", spillAt, got)
+		}
+		if got := b.NextFile(3); got != 13 {
+			t.Errorf("spill %d: NextFile(3) = %d, want 13", spillAt, got)
+		}
+
+		var tmp string
+		if b.file != nil {
+			tmp = b.file.Name()
+		}
+		if (spillAt == 16) != (tmp != "") {
+			t.Errorf("spill %d: temp file = %q", spillAt, tmp)
+		}
+		b.Close()
+		if tmp != "" {
+			if _, err := os.Stat(tmp); !os.IsNotExist(err) {
+				t.Errorf("temp file %s not removed", tmp)
+			}
+		}
+	}
+}
+
+func TestDiffBufferLongLine(t *testing.T) {
+	long := strings.Repeat("x", maxDiffLineBytes*2)
+	b := newDiffBuffer()
+	if err := b.readFrom(strings.NewReader("+" + long + "\nnext\n")); err != nil {
+		t.Fatal(err)
+	}
+	lines := b.Lines(0, 2)
+	if len(lines) != 2 || lines[1] != "next" {
+		t.Fatalf("Lines = %d lines, second %q", len(lines), lines[len(lines)-1])
+	}
+	if len(lines[0]) > maxDiffLineBytes {
+		t.Errorf("long line kept %d bytes, want at most %d", len(lines[0]), maxDiffLineBytes)
+	}
+}
+
+func TestDiffBufferWaitLines(t *testing.T) {
+	b := newDiffBuffer()
+	go func() {
+		b.readFrom(strings.NewReader(bufferTestDiff))
+		b.finish(nil)
+	}()
+	b.WaitLines(context.Background(), 100)
+	if done, err := b.Done(); !done || err != nil {
+		t.Fatalf("Done = %v, %v after waiting past the end", done, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	pending := newDiffBuffer()
+	pending.WaitLines(ctx, 1) // không được block khi ctx đã huỷ
+}
+
+func TestDiffBufferSummarize(t *testing.T) {
+	diff := `diff --git a/logo.png b/logo.png
+index 1111111..2222222 100644
+Binary files a/logo.png and b/logo.png differ
+diff --git a/main.go b/main.go
+index 4444444..5555555 100644
+--- a/main.go
++++ b/main.go
+@@ -1 +1 @@
+-a
++b
+diff --git a/model.bin b/model.bin
+index 6666666..7777777 100644
+--- a/model.bin
++++ b/model.bin
+@@ -1,3 +1,3 @@
+ version https://git-lfs.github.com/spec/v1
+-oid sha256:aaaaaaaaaaaaaaaaaaaa
+-size 1024
++oid sha256:bbbbbbbbbbbbbbbbbbbb
++size 2048
+`
+	src := fakeBinarySource{blobs: map[string]BlobInfo{
+		"1111111": {Exists: true, Size: 100, MIME: "image/png"},
+		"2222222": {Exists: true, Size: 200, MIME: "image/png"},
+	}}
+	b := newDiffBuffer()
+	b.summarize = func(section []string) []string { return summarizeFileSection(section, src, false) }
+	if err := b.readFrom(strings.NewReader(diff)); err != nil {
+		t.Fatal(err)
+	}
+	b.finish(nil)
+
+	want := summarizeBinaryDiff(strings.TrimSuffix(diff, "\n"), src, false)
+	if got := b.String(); got != want {
+		t.Fatalf("String =\n%s\nwant\n%s", got, want)
+	}
+	if strings.Contains(want, " differ") || !strings.Contains(want, "LFS object oid/size changed: model.bin") {
+		t.Fatalf("diff was not summarized:\n%s", want)
+	}
+	// index khớp với các dòng sau khi tóm tắt
+	files := b.Files()
+	if len(files) != 3 || files[1].Line != 5 || files[2].Line != 12 {
+		t.Errorf("Files = %+v", files)
+	}
+	if got := b.NextHunk(0); got != 9 || b.NextHunk(9) != -1 {
+		t.Errorf("NextHunk(0) = %d, NextHunk(9) = %d", got, b.NextHunk(9))
+	}
+}
+
+func TestSummaryCandidate(t *testing.T) {
+	tests := []struct {
+		section []string
+		want    bool
+	}{
+		{[]string{"diff --git a/x b/x", "index 1..2 100644"}, true},
+		{[]string{"diff --git a/x b/x", "Binary files a/x and b/x differ"}, true},
+		{[]string{"diff --git a/x b/x", "@@ -1 +1 @@", "-oid sha256:aa", "+oid sha256:bb"}, true},
+		{[]string{"diff --git a/x b/x", "@@ -1 +1 @@", "-a"}, false},
+		{append([]string{"diff --git a/x b/x"}, make([]string, maxSummarySectionLines)...), false},
+	}
+	for i, tt := range tests {
+		if got := summaryCandidate(tt.section); got != tt.want {
+			t.Errorf("case %d: summaryCandidate = %v, want %v", i, got, tt.want)
+		}
+	}
+}
+
+// section binary nằm sau phần đầu của diff được tóm tắt khi context của Runner
+// gọi (e.g. lệnh đã trả kết quả đầu tiên cho UI) đã bị huỷ
+func TestStreamSummarizedDiffOutlivesCallerContext(t *testing.T) {
+	r := initTestRepo(t, nil)
+	var text strings.Builder
+	for i := range 20000 {
+		fmt.Fprintf(&text, "line %d\n", i)
+	}
+	writeTestFile(t, filepath.Join(r.RepoRoot, "a.txt"), text.String())
+	writeTestFile(t, filepath.Join(r.RepoRoot, "z.bin"), "\x00\x01binary\x00")
+	if err := r.Add("."); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b, err := r.WithContext(ctx).streamSummarizedDiff(false, false, "diff", "--cached")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	cancel()
+	for {
+		if done, err := b.Done(); done {
+			if err != nil {
+				t.Fatal(err)
+			}
+			break
+		}
+		b.WaitLines(context.Background(), b.Len())
+	}
+
+	out := b.String()
+	i := strings.Index(out, "diff --git a/z.bin")
+	if i < 0 {
+		t.Fatalf("missing z.bin section")
+	}
+	if section := out[i:]; strings.Contains(section, " differ") || !strings.Contains(section, "new: 9 B") {
+		t.Errorf("z.bin was not summarized with its size:\n%s", section)
+	}
+}
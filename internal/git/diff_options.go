@@ -0,0 +1,150 @@
+package git
+
+import (
+	"strconv"
+	"strings"
+)
+
+// WhitespaceMode là cách diff xử lý khác biệt khoảng trắng
+type WhitespaceMode int
+
+const (
+	WhitespaceShow         WhitespaceMode = iota
+	WhitespaceIgnoreAll                   // -w
+	WhitespaceIgnoreChange                // -b
+)
+
+// RenameMode là chế độ phát hiện rename/copy
+type RenameMode int
+
+const (
+	RenamesDefault   RenameMode = iota // theo diff.renames (mặc định bật)
+	RenamesAndCopies                   // -M -C
+	RenamesOff                         // --no-renames
+)
+
+// DiffAlgorithms là các thuật toán diff có thể chọn, "" là mặc định (myers hoặc diff.algorithm)
+var DiffAlgorithms = []string{"", "patience", "histogram", "myers"}
+
+const (
+	// DefaultDiffContext là số dòng context mặc định của git
+	DefaultDiffContext = 3
+	// MaxDiffContext giới hạn số dòng context khi tăng bằng phím
+	MaxDiffContext = 50
+)
+
+// DiffOptions là các tuỳ chọn hiển thị diff chỉnh được lúc chạy.
+// Zero value tương ứng với mặc định của git.
+type DiffOptions struct {
+	Whitespace WhitespaceMode
+	Context    int    // số dòng context, 0 = mặc định (3)
+	Algorithm  string // "", "myers", "patience", "histogram"
+	Renames    RenameMode
+	WordDiff   bool
+	SmudgeLFS  bool // diff nội dung thật của LFS object thay vì chỉ oid/size (không phải arg của git)
+}
+
+// ContextLines trả về số dòng context thực tế
+func (o DiffOptions) ContextLines() int {
+	if o.Context <= 0 {
+		return DefaultDiffContext
+	}
+	return o.Context
+}
+
+// WithContext trả về options với số dòng context cộng thêm delta (tối thiểu 1)
+func (o DiffOptions) WithContext(delta int) DiffOptions {
+	n := o.ContextLines() + delta
+	if n < 1 {
+		n = 1
+	}
+	if n > MaxDiffContext {
+		n = MaxDiffContext
+	}
+	o.Context = n
+	if n == DefaultDiffContext {
+		o.Context = 0
+	}
+	return o
+}
+
+// NextWhitespace chuyển vòng: hiện khoảng trắng → -w → -b
+func (o DiffOptions) NextWhitespace() DiffOptions {
+	o.Whitespace = (o.Whitespace + 1) % 3
+	return o
+}
+
+// NextAlgorithm chuyển sang thuật toán tiếp theo trong DiffAlgorithms
+func (o DiffOptions) NextAlgorithm() DiffOptions {
+	for i, a := range DiffAlgorithms {
+		if a == o.Algorithm {
+			o.Algorithm = DiffAlgorithms[(i+1)%len(DiffAlgorithms)]
+			return o
+		}
+	}
+	o.Algorithm = ""
+	return o
+}
+
+// NextRenames chuyển vòng: mặc định → rename+copy → tắt
+func (o DiffOptions) NextRenames() DiffOptions {
+	o.Renames = (o.Renames + 1) % 3
+	return o
+}
+
+// Args trả về args cho git diff/show, nil khi dùng toàn bộ mặc định
+func (o DiffOptions) Args() []string {
+	var args []string
+	switch o.Whitespace {
+	case WhitespaceIgnoreAll:
+		args = append(args, "-w")
+	case WhitespaceIgnoreChange:
+		args = append(args, "-b")
+	}
+	if o.Context > 0 {
+		args = append(args, "-U"+strconv.Itoa(o.Context))
+	}
+	if o.Algorithm != "" {
+		args = append(args, "--diff-algorithm="+o.Algorithm)
+	}
+	switch o.Renames {
+	case RenamesAndCopies:
+		args = append(args, "-M", "-C")
+	case RenamesOff:
+		args = append(args, "--no-renames")
+	}
+	if o.WordDiff {
+		args = append(args, "--word-diff=plain")
+	}
+	return args
+}
+
+// String mô tả các tuỳ chọn khác mặc định cho tiêu đề DiffView, e.g. "-w U5 patience"
+func (o DiffOptions) String() string {
+	var parts []string
+	switch o.Whitespace {
+	case WhitespaceIgnoreAll:
+		parts = append(parts, "-w")
+	case WhitespaceIgnoreChange:
+		parts = append(parts, "-b")
+	}
+	if o.Context > 0 {
+		parts = append(parts, "U"+strconv.Itoa(o.Context))
+	}
+	if o.Algorithm != "" {
+		parts = append(parts, o.Algorithm)
+	}
+	switch o.Renames {
+	case RenamesAndCopies:
+		parts = append(parts, "copies")
+	case RenamesOff:
+		parts = append(parts, "no-renames")
+	}
+	if o.WordDiff {
+		parts = append(parts, "word-diff")
+	}
+	if o.SmudgeLFS {
+		parts = append(parts, "lfs-smudge")
+	}
+	return strings.Join(parts, " ")
+}
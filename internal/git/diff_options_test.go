@@ -0,0 +1,84 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffOptionsDefaults(t *testing.T) {
+	var o DiffOptions
+	if args := o.Args(); args != nil {
+		t.Errorf("default Args() = %v, want nil", args)
+	}
+	if s := o.String(); s != "" {
+		t.Errorf("default String() = %q, want empty", s)
+	}
+	if n := o.ContextLines(); n != DefaultDiffContext {
+		t.Errorf("default ContextLines() = %d, want %d", n, DefaultDiffContext)
+	}
+}
+
+func TestDiffOptionsArgs(t *testing.T) {
+	o := DiffOptions{
+		Whitespace: WhitespaceIgnoreAll,
+		Context:    5,
+		Algorithm:  "patience",
+		Renames:    RenamesAndCopies,
+		WordDiff:   true,
+	}
+	want := []string{"-w", "-U5", "--diff-algorithm=patience", "-M", "-C", "--word-diff=plain"}
+	if got := o.Args(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Args() = %v, want %v", got, want)
+	}
+	if got, want := o.String(), "-w U5 patience copies word-diff"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	o = DiffOptions{Whitespace: WhitespaceIgnoreChange, Renames: RenamesOff}
+	if got, want := o.Args(), []string{"-b", "--no-renames"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Args() = %v, want %v", got, want)
+	}
+}
+
+func TestDiffOptionsWithContext(t *testing.T) {
+	var o DiffOptions
+	o = o.WithContext(1)
+	if o.Context != 4 {
+		t.Errorf("Context after +1 = %d, want 4", o.Context)
+	}
+	o = o.WithContext(-1)
+	if o.Context != 0 {
+		t.Errorf("back to default context should reset to 0, got %d", o.Context)
+	}
+	o = o.WithContext(-10)
+	if o.ContextLines() != 1 {
+		t.Errorf("context should not go below 1, got %d", o.ContextLines())
+	}
+	o = o.WithContext(1000)
+	if o.ContextLines() != MaxDiffContext {
+		t.Errorf("context should be capped at %d, got %d", MaxDiffContext, o.ContextLines())
+	}
+}
+
+func TestDiffOptionsCycles(t *testing.T) {
+	var o DiffOptions
+	var algos []string
+	for range DiffAlgorithms {
+		o = o.NextAlgorithm()
+		algos = append(algos, o.Algorithm)
+	}
+	if want := []string{"patience", "histogram", "myers", ""}; !reflect.DeepEqual(algos, want) {
+		t.Errorf("algorithm cycle = %v, want %v", algos, want)
+	}
+
+	o = o.NextWhitespace().NextWhitespace()
+	if o.Whitespace != WhitespaceIgnoreChange {
+		t.Errorf("whitespace after two steps = %d, want WhitespaceIgnoreChange", o.Whitespace)
+	}
+	if o.NextWhitespace().Whitespace != WhitespaceShow {
+		t.Error("whitespace cycle should wrap to WhitespaceShow")
+	}
+	if o.NextRenames().NextRenames().NextRenames().Renames != RenamesDefault {
+		t.Error("rename cycle should wrap to RenamesDefault")
+	}
+}
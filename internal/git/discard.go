@@ -0,0 +1,136 @@
+package git
+
+import (
+	"strconv"
+	"strings"
+)
+
+// DiscardPlan chia các file theo cách bỏ toàn bộ thay đổi (cả staged và unstaged)
+type DiscardPlan struct {
+	Restore []string // file có trong HEAD: restore --source=HEAD --staged --worktree
+	Remove  []string // file mới thêm vào index: rm -f (xoá khỏi index và working tree)
+	Clean   []string // file untracked: clean -f
+}
+
+// Empty cho biết không có file nào để discard
+func (p DiscardPlan) Empty() bool {
+	return len(p.Restore) == 0 && len(p.Remove) == 0 && len(p.Clean) == 0
+}
+
+// PlanDiscard lập DiscardPlan từ các file staged/unstaged được chọn; cùng một path
+// có thể xuất hiện ở cả hai phía
+func PlanDiscard(staged, unstaged []FileItem) DiscardPlan {
+	kinds := make(map[string]string)
+	var order []string
+	note := func(path, kind string) {
+		if _, ok := kinds[path]; !ok {
+			order = append(order, path)
+		}
+		// file mới trong index thắng mọi trường hợp khác, untracked chỉ khi không ở index
+		if kinds[path] != "remove" && (kind != "clean" || kinds[path] == "") {
+			kinds[path] = kind
+		}
+	}
+	for _, f := range staged {
+		switch f.Status {
+		case "A":
+			note(f.Path, "remove")
+		case "?": // ParseStatusPorcelainV1Z báo file untracked ở cả hai phía
+			note(f.Path, "clean")
+		case "R", "C":
+			// restore cả path gốc lẫn path mới: path mới không có trong HEAD nên bị bỏ
+			// khỏi index và working tree, path gốc được khôi phục
+			if f.OrigPath != "" {
+				note(f.OrigPath, "restore")
+			}
+			note(f.Path, "restore")
+		default:
+			note(f.Path, "restore")
+		}
+	}
+	for _, f := range unstaged {
+		if f.Status == "?" {
+			note(f.Path, "clean")
+		} else {
+			note(f.Path, "restore")
+		}
+	}
+
+	var plan DiscardPlan
+	for _, path := range order {
+		switch kinds[path] {
+		case "remove":
+			plan.Remove = append(plan.Remove, path)
+		case "clean":
+			plan.Clean = append(plan.Clean, path)
+		default:
+			plan.Restore = append(plan.Restore, path)
+		}
+	}
+	return plan
+}
+
+// DiscardAllChanges bỏ thay đổi staged và unstaged của các file trong plan
+func (r Runner) DiscardAllChanges(plan DiscardPlan) error {
+	if len(plan.Restore) > 0 {
+		args := literalPathArgs([]string{"restore", "--source=HEAD", "--staged", "--worktree"}, plan.Restore...)
+		if _, err := r.run(DefaultCmdTimeout, args...); err != nil {
+			return err
+		}
+	}
+	if len(plan.Remove) > 0 {
+		if _, err := r.run(DefaultCmdTimeout, literalPathArgs([]string{"rm", "-f", "--quiet"}, plan.Remove...)...); err != nil {
+			return err
+		}
+	}
+	if len(plan.Clean) > 0 {
+		return r.DiscardUntracked(plan.Clean...)
+	}
+	return nil
+}
+
+// ResetHard bỏ mọi thay đổi của file tracked (git reset --hard HEAD), giữ file untracked
+func (r Runner) ResetHard() error {
+	_, err := r.run(DefaultCmdTimeout, "reset", "--hard", "HEAD")
+	return err
+}
+
+// CleanPreview liệt kê file/thư mục untracked sẽ bị xoá bởi git clean -fd (git clean -nd)
+func (r Runner) CleanPreview() ([]string, error) {
+	out, err := r.run(DefaultCmdTimeout, "-c", "core.quotePath=false", "clean", "-n", "-d")
+	if err != nil {
+		return nil, err
+	}
+	return ParseCleanDryRun(out), nil
+}
+
+// CleanUntracked xoá file và thư mục untracked (không đụng file bị ignore). paths
+// là danh sách đã xem trước bằng CleanPreview, được so khớp nguyên văn để file
+// untracked mới xuất hiện sau lúc xem trước không bị xoá; rỗng = mọi file untracked.
+func (r Runner) CleanUntracked(paths ...string) error {
+	args := []string{"clean", "-f", "-d"}
+	if len(paths) > 0 {
+		args = literalPathArgs(args, paths...)
+	}
+	_, err := r.run(DefaultCmdTimeout, args...)
+	return err
+}
+
+// ParseCleanDryRun đọc output "Would remove <path>" của git clean -n
+func ParseCleanDryRun(out string) []string {
+	var paths []string
+	for _, line := range strings.Split(out, "\n") {
+		path, ok := strings.CutPrefix(strings.TrimSpace(line), "Would remove ")
+		if !ok || path == "" {
+			continue
+		}
+		// path có ký tự đặc biệt (dấu nháy, xuống dòng) vẫn được git quote kiểu C
+		if strings.HasPrefix(path, `"`) {
+			if unquoted, err := strconv.Unquote(path); err == nil {
+				path = unquoted
+			}
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
@@ -0,0 +1,134 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// initTestRepo tạo repo tạm với một commit chứa tracked (path -> nội dung)
+func initTestRepo(t *testing.T, tracked map[string]string) Runner {
+	t.Helper()
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@t", "-c", "commit.gpgsign=false"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	for path, content := range tracked {
+		writeTestFile(t, filepath.Join(dir, path), content)
+	}
+	git("add", "-A")
+	git("commit", "-q", "--allow-empty", "-m", "init")
+	return New(dir)
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fileContent trả về "" khi file không tồn tại
+func fileContent(path string) string {
+	data, _ := os.ReadFile(path)
+	return string(data)
+}
+
+func TestPlanDiscard(t *testing.T) {
+	staged := []FileItem{
+		{Path: "new.go", Status: "A", Staged: true},
+		{Path: "main.go", Status: "M", Staged: true},
+		{Path: "notes.txt", Status: "?", Staged: true},
+		{Path: "b.txt", Status: "R", Staged: true, OrigPath: "a.txt"},
+	}
+	unstaged := []FileItem{
+		{Path: "new.go", Status: "M"},
+		{Path: "main.go", Status: "M"},
+		{Path: "gone.go", Status: "D"},
+		{Path: "notes.txt", Status: "?"},
+		{Path: "b.txt", Status: "M", OrigPath: "a.txt"},
+	}
+	plan := PlanDiscard(staged, unstaged)
+	want := DiscardPlan{
+		Restore: []string{"main.go", "a.txt", "b.txt", "gone.go"},
+		Remove:  []string{"new.go"},
+		Clean:   []string{"notes.txt"},
+	}
+	if !reflect.DeepEqual(plan, want) {
+		t.Fatalf("PlanDiscard = %+v, want %+v", plan, want)
+	}
+	if plan.Empty() || !PlanDiscard(nil, nil).Empty() {
+		t.Error("Empty() mismatch")
+	}
+}
+
+func TestParseCleanDryRun(t *testing.T) {
+	out := "Would remove build/\nWould remove notes.txt\nWould remove \"a\\\"b.txt\"\n\nsomething else\n"
+	if got, want := ParseCleanDryRun(out), []string{"build/", "notes.txt", `a"b.txt`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseCleanDryRun = %q, want %q", got, want)
+	}
+	if got := ParseCleanDryRun(""); got != nil {
+		t.Errorf("empty output = %q", got)
+	}
+}
+
+// tên file có ký tự glob không được khớp nhầm file khác khi discard
+func TestDiscardLiteralPaths(t *testing.T) {
+	r := initTestRepo(t, map[string]string{"[ab].go": "1\n", "a.go": "1\n", "b.go": "1\n"})
+	path := func(name string) string { return filepath.Join(r.RepoRoot, name) }
+	for _, name := range []string{"[ab].go", "a.go", "b.go"} {
+		writeTestFile(t, path(name), "2\n")
+	}
+	for _, name := range []string{"[xy].tsx", "x.tsx", "y.tsx"} {
+		writeTestFile(t, path(name), "new\n")
+	}
+	untouched := func(step string) {
+		t.Helper()
+		for _, name := range []string{"a.go", "b.go"} {
+			if got := fileContent(path(name)); got != "2\n" {
+				t.Errorf("%s: %s = %q, should keep its changes", step, name, got)
+			}
+		}
+		for _, name := range []string{"x.tsx", "y.tsx"} {
+			if got := fileContent(path(name)); got != "new\n" {
+				t.Errorf("%s: %s was removed", step, name)
+			}
+		}
+	}
+
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"DiscardFile", func() error { return r.DiscardFile("[ab].go") }},
+		{"DiscardUntracked", func() error { return r.DiscardUntracked("[xy].tsx") }},
+		{"DiscardAllChanges", func() error {
+			writeTestFile(t, path("[ab].go"), "2\n")
+			writeTestFile(t, path("[xy].tsx"), "new\n")
+			return r.DiscardAllChanges(DiscardPlan{Restore: []string{"[ab].go"}, Clean: []string{"[xy].tsx"}})
+		}},
+		{"CleanUntracked", func() error {
+			writeTestFile(t, path("[xy].tsx"), "new\n")
+			return r.CleanUntracked("[xy].tsx")
+		}},
+	}
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if got := fileContent(path("[ab].go")); got != "1\n" {
+			t.Errorf("%s: [ab].go = %q, want restored", step.name, got)
+		}
+		if fileContent(path("[xy].tsx")) != "" && step.name != "DiscardFile" {
+			t.Errorf("%s: [xy].tsx should be removed", step.name)
+		}
+		untouched(step.name)
+	}
+}
@@ -18,9 +18,9 @@ func (r Runner) FetchBranches(remote string, branches []string) error {
 		refspecs = append(refspecs, refspec)
 	}
 
-	// Thực hiện git fetch với NetworkTimeout cho network operation
+	// Thực hiện git fetch với idle timeout (không giới hạn tổng thời gian)
 	args := append([]string{"fetch", remote}, refspecs...)
-	_, err := r.run(NetworkTimeout, args...)
+	_, err := r.runNetwork(args...)
 	if err != nil {
 		return fmt.Errorf("cannot fetch branches %v from %s: %w", branches, remote, err)
 	}
@@ -28,6 +28,11 @@ func (r Runner) FetchBranches(remote string, branches []string) error {
 	return nil
 }
 
+// StreamFetch fetch tất cả remote với progress được stream
+func (r Runner) StreamFetch() (*Stream, error) {
+	return r.StartNetworkStream("fetch", "--all", "--prune")
+}
+
 // GetDefaultBranch trả về default branch của remote, fallback về "main"
 func (r Runner) GetDefaultBranch(remote string) (string, error) {
 	// Thử phương pháp 1: git symbolic-ref refs/remotes/remote/HEAD
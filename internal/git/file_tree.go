@@ -0,0 +1,107 @@
+package git
+
+import (
+	"sort"
+	"strings"
+)
+
+// FileTreeRow là một dòng của cây thư mục trong Files pane: thư mục hoặc file
+type FileTreeRow struct {
+	Path      string // đường dẫn đầy đủ (thư mục không có "/" ở cuối)
+	Name      string // tên hiển thị; chuỗi thư mục chỉ có một thư mục con được gộp, e.g. "internal/git"
+	Depth     int
+	IsDir     bool
+	Collapsed bool
+	Item      FileItem   // file của dòng khi !IsDir
+	Files     []FileItem // mọi file bên dưới khi IsDir
+}
+
+// Status trả về status của file, hoặc status gộp của các file trong thư mục
+func (r FileTreeRow) Status() string {
+	if !r.IsDir {
+		return r.Item.Status
+	}
+	return DirStatus(r.Files)
+}
+
+// DirStatus gộp status của nhiều file: giống nhau thì giữ nguyên, khác nhau thì "M"
+func DirStatus(files []FileItem) string {
+	if len(files) == 0 {
+		return ""
+	}
+	status := files[0].Status
+	for _, f := range files[1:] {
+		if f.Status != status {
+			return "M"
+		}
+	}
+	return status
+}
+
+type fileTreeNode struct {
+	name  string
+	path  string
+	dirs  map[string]*fileTreeNode
+	files []FileItem
+	all   []FileItem
+}
+
+func newFileTreeNode(name, path string) *fileTreeNode {
+	return &fileTreeNode{name: name, path: path, dirs: make(map[string]*fileTreeNode)}
+}
+
+// BuildFileTree dựng cây thư mục từ danh sách file và trải phẳng thành các dòng
+// hiển thị: thư mục trước file, mỗi nhóm theo thứ tự tên. Thư mục có path trong
+// collapsed được đóng (không có dòng con).
+func BuildFileTree(items []FileItem, collapsed map[string]bool) []FileTreeRow {
+	root := newFileTreeNode("", "")
+	for _, item := range items {
+		// untracked directory được git status báo dạng "dir/", coi như một file
+		parts := strings.Split(strings.TrimSuffix(item.Path, "/"), "/")
+		node := root
+		for i, part := range parts[:len(parts)-1] {
+			child, ok := node.dirs[part]
+			if !ok {
+				child = newFileTreeNode(part, strings.Join(parts[:i+1], "/"))
+				node.dirs[part] = child
+			}
+			child.all = append(child.all, item)
+			node = child
+		}
+		node.files = append(node.files, item)
+	}
+
+	var rows []FileTreeRow
+	var walk func(node *fileTreeNode, depth int)
+	walk = func(node *fileTreeNode, depth int) {
+		names := make([]string, 0, len(node.dirs))
+		for name := range node.dirs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			dir := node.dirs[name]
+			label := dir.name
+			for len(dir.dirs) == 1 && len(dir.files) == 0 {
+				for _, only := range dir.dirs {
+					dir = only
+				}
+				label += "/" + dir.name
+			}
+			row := FileTreeRow{Path: dir.path, Name: label, Depth: depth, IsDir: true, Collapsed: collapsed[dir.path], Files: dir.all}
+			rows = append(rows, row)
+			if !row.Collapsed {
+				walk(dir, depth+1)
+			}
+		}
+
+		files := append([]FileItem(nil), node.files...)
+		sort.SliceStable(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+		for _, f := range files {
+			name := f.Path[strings.LastIndex(strings.TrimSuffix(f.Path, "/"), "/")+1:]
+			rows = append(rows, FileTreeRow{Path: f.Path, Name: name, Depth: depth, Item: f})
+		}
+	}
+	walk(root, 0)
+	return rows
+}
@@ -0,0 +1,76 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func treeRowNames(rows []FileTreeRow) []string {
+	var names []string
+	for _, r := range rows {
+		prefix := ""
+		for range r.Depth {
+			prefix += "  "
+		}
+		if r.IsDir {
+			names = append(names, prefix+r.Name+"/ "+r.Status())
+		} else {
+			names = append(names, prefix+r.Name+" "+r.Status())
+		}
+	}
+	return names
+}
+
+func TestBuildFileTree(t *testing.T) {
+	items := []FileItem{
+		{Path: "README.md", Status: "M"},
+		{Path: "internal/git/git.go", Status: "M"},
+		{Path: "internal/git/tree.go", Status: "?"},
+		{Path: "internal/app/keys.go", Status: "M"},
+		{Path: "cmd/gitzen/main.go", Status: "D"},
+		{Path: "docs/", Status: "?"},
+	}
+	rows := BuildFileTree(items, nil)
+	want := []string{
+		"cmd/gitzen/ D",
+		"  main.go D",
+		"internal/ M",
+		"  app/ M",
+		"    keys.go M",
+		"  git/ M",
+		"    git.go M",
+		"    tree.go ?",
+		"README.md M",
+		"docs/ ?",
+	}
+	if got := treeRowNames(rows); !reflect.DeepEqual(got, want) {
+		t.Fatalf("BuildFileTree =\n%q\nwant\n%q", got, want)
+	}
+	if rows[0].Path != "cmd/gitzen" || len(rows[2].Files) != 3 {
+		t.Errorf("dir rows = %+v, %+v", rows[0], rows[2])
+	}
+	if rows[9].IsDir || rows[9].Item.Path != "docs/" {
+		t.Errorf("untracked directory entry should be a file row: %+v", rows[9])
+	}
+
+	rows = BuildFileTree(items, map[string]bool{"internal": true})
+	want = []string{"cmd/gitzen/ D", "  main.go D", "internal/ M", "README.md M", "docs/ ?"}
+	if got := treeRowNames(rows); !reflect.DeepEqual(got, want) {
+		t.Fatalf("collapsed tree =\n%q\nwant\n%q", got, want)
+	}
+	if !rows[2].Collapsed || len(rows[2].Files) != 3 {
+		t.Errorf("collapsed dir row = %+v", rows[2])
+	}
+}
+
+func TestDirStatus(t *testing.T) {
+	if got := DirStatus([]FileItem{{Status: "A"}, {Status: "A"}}); got != "A" {
+		t.Errorf("same status = %q, want A", got)
+	}
+	if got := DirStatus([]FileItem{{Status: "A"}, {Status: "D"}}); got != "M" {
+		t.Errorf("mixed status = %q, want M", got)
+	}
+	if got := DirStatus(nil); got != "" {
+		t.Errorf("empty = %q", got)
+	}
+}
@@ -0,0 +1,82 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// autosquashArgs trả về args cho rebase --autosquash không tương tác.
+// sequence.editor=: giữ nguyên todo list mà git đã sắp xếp lại.
+// Khi target là root commit (không có parent) thì dùng --root.
+func autosquashArgs(target string, hasParent bool) []string {
+	args := []string{"-c", "sequence.editor=:", "rebase", "-i", "--autosquash", "--autostash"}
+	if hasParent {
+		return append(args, target+"~1")
+	}
+	return append(args, "--root")
+}
+
+// IsFixupSubject kiểm tra subject có phải commit fixup!/squash!/amend! hay không
+func IsFixupSubject(subject string) bool {
+	for _, prefix := range []string{"fixup! ", "squash! ", "amend! "} {
+		if strings.HasPrefix(subject, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// StartRebaseAutosquash bắt đầu rebase --autosquash từ parent của target tới HEAD,
+// gộp mọi fixup!/squash! commit vào commit gốc của chúng. Chạy dạng stream, không có
+// timeout cố định vì rebase có thể replay nhiều commit, chạy hook và ký từng commit;
+// chỉ dừng khi người dùng huỷ. Gọi FinishRebaseAutosquash với lỗi của stream.
+func (r Runner) StartRebaseAutosquash(target string) (*Stream, error) {
+	if err := r.checkLinearSince(target); err != nil {
+		return nil, err
+	}
+	return r.StartStream(autosquashArgs(target, r.HasParent(target))...)
+}
+
+// FinishRebaseAutosquash abort rebase khi stream autosquash thất bại hoặc bị huỷ
+func (r Runner) FinishRebaseAutosquash(err error) error {
+	if err != nil {
+		// Không để repo kẹt giữa chừng rebase
+		_, _ = r.run(DefaultCmdTimeout, "rebase", "--abort")
+	}
+	return err
+}
+
+// StartAmendCommitWithStaged gộp các thay đổi đang staged vào target (không nhất thiết
+// là HEAD): kiểm tra target rồi tạo fixup! commit cho nó với output hook được stream.
+// Sau khi commit thành công thì autosquash (StartRebaseAutosquash) và gọi
+// FinishAmendCommitWithStaged với lỗi của bước đó. noVerify bỏ qua hook của fixup commit.
+func (r Runner) StartAmendCommitWithStaged(target string, noVerify bool) (*Stream, error) {
+	if err := r.checkLinearSince(target); err != nil {
+		return nil, err
+	}
+	return r.StreamCommitFixup(target, noVerify)
+}
+
+// FinishAmendCommitWithStaged: autosquash thất bại thì bỏ fixup commit vừa tạo và
+// trả thay đổi về index
+func (r Runner) FinishAmendCommitWithStaged(err error) error {
+	if err != nil {
+		_, _ = r.run(DefaultCmdTimeout, "reset", "--soft", "HEAD~1")
+	}
+	return err
+}
+
+// checkLinearSince từ chối khi target không thuộc branch hiện tại hoặc có merge commit phía sau
+func (r Runner) checkLinearSince(target string) error {
+	if _, err := r.run(DefaultCmdTimeout, "merge-base", "--is-ancestor", target, "HEAD"); err != nil {
+		return fmt.Errorf("%s is not an ancestor of HEAD", target)
+	}
+	merges, err := r.run(DefaultCmdTimeout, "rev-list", "--merges", target+"..HEAD")
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(merges) != "" {
+		return fmt.Errorf("cannot autosquash across merge commits after %s", target)
+	}
+	return nil
+}
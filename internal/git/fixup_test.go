@@ -0,0 +1,106 @@
+package git
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAutosquashArgs(t *testing.T) {
+	got := autosquashArgs("abc123", true)
+	want := []string{"-c", "sequence.editor=:", "rebase", "-i", "--autosquash", "--autostash", "abc123~1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("autosquashArgs(parent) = %v, want %v", got, want)
+	}
+
+	got = autosquashArgs("abc123", false)
+	if got[len(got)-1] != "--root" {
+		t.Errorf("autosquashArgs(root) should end with --root, got %v", got)
+	}
+}
+
+func TestIsFixupSubject(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    bool
+	}{
+		{"fixup! add parser", true},
+		{"squash! add parser", true},
+		{"amend! add parser", true},
+		{"add fixup! support", false},
+		{"fixup!no space", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsFixupSubject(tt.subject); got != tt.want {
+			t.Errorf("IsFixupSubject(%q) = %v, want %v", tt.subject, got, tt.want)
+		}
+	}
+}
+
+// amendWithStaged chạy các bước của amend vào commit cũ như app: fixup commit rồi autosquash
+func amendWithStaged(r Runner, target string) error {
+	if _, err := drainStream(r.StartAmendCommitWithStaged(target, true)); err != nil {
+		return err
+	}
+	_, err := drainStream(r.StartRebaseAutosquash(target))
+	return r.FinishAmendCommitWithStaged(r.FinishRebaseAutosquash(err))
+}
+
+func TestAmendCommitWithStaged(t *testing.T) {
+	r := initTestRepo(t, map[string]string{"a.txt": "1\n"})
+	commit := func(path, content, message string) {
+		t.Helper()
+		writeTestFile(t, filepath.Join(r.RepoRoot, path), content)
+		if err := r.Add(path); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := drainStream(r.StreamCommit(message, false, true)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	commit("b.txt", "1\n", "add b")
+	target, err := r.run(DefaultCmdTimeout, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	target = strings.TrimSpace(target)
+	commit("c.txt", "1\n", "add c")
+
+	writeTestFile(t, filepath.Join(r.RepoRoot, "b.txt"), "2\n")
+	if err := r.Add("b.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := amendWithStaged(r, target); err != nil {
+		t.Fatal(err)
+	}
+	out, _ := r.run(DefaultCmdTimeout, "log", "--format=%s")
+	if got := strings.Fields(out); !reflect.DeepEqual(got, []string{"add", "c", "add", "b", "init"}) {
+		t.Errorf("log after amend = %q", got)
+	}
+	if out, _ := r.run(DefaultCmdTimeout, "show", "HEAD~1:b.txt"); strings.TrimSpace(out) != "2" {
+		t.Errorf("b.txt in amended commit = %q", out)
+	}
+
+	// autosquash xung đột với commit phía sau: rebase được abort, thay đổi về lại index
+	commit("b.txt", "3\n", "change b")
+	writeTestFile(t, filepath.Join(r.RepoRoot, "b.txt"), "4\n")
+	if err := r.Add("b.txt"); err != nil {
+		t.Fatal(err)
+	}
+	head, _ := r.run(DefaultCmdTimeout, "rev-parse", "HEAD")
+	amended, _ := r.run(DefaultCmdTimeout, "rev-parse", "HEAD~2")
+	if err := amendWithStaged(r, amended); err == nil {
+		t.Fatal("conflicting autosquash should fail")
+	}
+	if state := DetectRepoState(filepath.Join(r.RepoRoot, ".git")); state.Kind != StateNone {
+		t.Errorf("repo left in %v", state.Kind)
+	}
+	if after, _ := r.run(DefaultCmdTimeout, "rev-parse", "HEAD"); after != head {
+		t.Errorf("HEAD moved to %s, want %s", after, head)
+	}
+	if staged, _ := r.run(DefaultCmdTimeout, "diff", "--cached", "--name-only"); strings.TrimSpace(staged) != "b.txt" {
+		t.Errorf("staged after failed amend = %q, want b.txt", staged)
+	}
+}
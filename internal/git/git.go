@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
@@ -20,10 +21,62 @@ var (
 	DefaultCmdTimeout  = time.Duration(limits.CmdTimeoutSec) * time.Second
 	DefaultDiffTimeout = time.Duration(limits.DiffTimeoutSec) * time.Second
 	NetworkTimeout     = time.Duration(limits.NetworkTimeoutSec) * time.Second
+	NetworkIdleTimeout = time.Duration(limits.NetworkIdleTimeoutSec) * time.Second
 )
 
+// CommandError là lỗi khi lệnh git thoát với mã khác 0, giữ nguyên stderr
+// để caller có thể hiển thị output thật (e.g. lỗi ký commit, hook).
+type CommandError struct {
+	Args   []string
+	Stderr string // stderr đầy đủ, chưa trim
+	Text   string // stderr (hoặc stdout) đã trim, dùng trong Error()
+}
+
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("git %s: %s", strings.Join(e.Args, " "), e.Text)
+}
+
+// Runner chạy lệnh git trong RepoRoot. Runner là value type: WithContext trả
+// về bản sao gắn context, mọi method của bản sao đó sẽ bị huỷ khi ctx bị cancel.
 type Runner struct {
 	RepoRoot string
+
+	ctx context.Context
+	// networkIdle: 0 = NetworkIdleTimeout, âm = không có idle timeout
+	networkIdle time.Duration
+	// reflogAction ghi vào GIT_REFLOG_ACTION, rỗng = message mặc định của git
+	reflogAction string
+}
+
+// WithContext trả về Runner mà mọi lệnh git chạy dưới ctx (cancel được)
+func (r Runner) WithContext(ctx context.Context) Runner {
+	r.ctx = ctx
+	return r
+}
+
+// WithNetworkIdleTimeout trả về Runner huỷ lệnh mạng khi không có output trong d;
+// d <= 0 tắt idle timeout (hook im lặng lâu), lệnh chỉ dừng khi người dùng huỷ
+func (r Runner) WithNetworkIdleTimeout(d time.Duration) Runner {
+	if d <= 0 {
+		d = -1
+	}
+	r.networkIdle = d
+	return r
+}
+
+// WithReflogAction trả về Runner ghi action vào reflog thay cho message mặc định của git
+// (e.g. đánh dấu các entry do undo tạo ra)
+func (r Runner) WithReflogAction(action string) Runner {
+	r.reflogAction = action
+	return r
+}
+
+// Context trả về context của Runner, mặc định context.Background()
+func (r Runner) Context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
 }
 
 func LookPath() error {
@@ -35,7 +88,7 @@ func LookPath() error {
 
 func DetectRepoRoot(repoPath string) (string, error) {
 	args := []string{"rev-parse", "--show-toplevel"}
-	out, err := runRaw(repoPath, DefaultCmdTimeout, args...)
+	out, err := runRaw(context.Background(), repoPath, DefaultCmdTimeout, args...)
 	if err != nil {
 		return "", ErrNotARepository
 	}
@@ -77,29 +130,48 @@ func (r Runner) Reflog() (string, error) {
 	return r.run(DefaultCmdTimeout, "reflog", "-n", fmt.Sprintf("%d", limits.MaxReflogEntries))
 }
 
-func (r Runner) DiffFile(path string, staged bool) (string, error) {
+// DiffFile trả về diff của file. Diff dùng để stage hunk phải dùng DiffOptions{}
+// (mặc định) vì -w/--word-diff tạo ra patch không apply được.
+func (r Runner) DiffFile(path string, staged bool, opts DiffOptions) (string, error) {
+	return r.run(DefaultDiffTimeout, diffFileArgs(path, staged, opts)...)
+}
+
+// StreamDiffFile như DiffFile nhưng stream vào DiffBuffer (diff lớn)
+func (r Runner) StreamDiffFile(path string, staged bool, opts DiffOptions) (*DiffBuffer, error) {
+	return r.streamSummarizedDiff(!staged, opts.SmudgeLFS, diffFileArgs(path, staged, opts)...)
+}
+
+func diffFileArgs(path string, staged bool, opts DiffOptions) []string {
+	args := []string{"diff"}
 	if staged {
-		return r.run(DefaultDiffTimeout, "diff", "--staged", "--", path)
+		args = append(args, "--staged")
 	}
-	return r.run(DefaultDiffTimeout, "diff", "--", path)
+	args = append(args, opts.Args()...)
+	return append(args, "--", path)
 }
 
-func (r Runner) ShowCommit(hash string) (string, error) {
-	return r.run(DefaultDiffTimeout, "show", hash)
+func (r Runner) ShowCommit(hash string, opts DiffOptions) (string, error) {
+	return r.run(DefaultDiffTimeout, showCommitArgs(hash, opts)...)
 }
 
-func (r Runner) Add(path string) error {
-	_, err := r.run(DefaultCmdTimeout, "add", "--", path)
-	return err
+// StreamShowCommit như ShowCommit nhưng stream vào DiffBuffer
+func (r Runner) StreamShowCommit(hash string, opts DiffOptions) (*DiffBuffer, error) {
+	return r.streamSummarizedDiff(false, opts.SmudgeLFS, showCommitArgs(hash, opts)...)
+}
+
+func showCommitArgs(hash string, opts DiffOptions) []string {
+	args := append([]string{"show"}, opts.Args()...)
+	return append(args, hash)
 }
 
-func (r Runner) RestoreStaged(path string) error {
-	_, err := r.run(DefaultCmdTimeout, "restore", "--staged", "--", path)
+func (r Runner) Add(paths ...string) error {
+	_, err := r.run(DefaultCmdTimeout, literalPathArgs([]string{"add"}, paths...)...)
 	return err
 }
 
-func (r Runner) Commit(message string) (string, error) {
-	return r.run(DefaultCmdTimeout, "commit", "-m", message)
+func (r Runner) RestoreStaged(paths ...string) error {
+	_, err := r.run(DefaultCmdTimeout, literalPathArgs([]string{"restore", "--staged"}, paths...)...)
+	return err
 }
 
 func (r Runner) CurrentBranch() (string, error) {
@@ -111,6 +183,7 @@ type Branch struct {
 	Name      string
 	IsCurrent bool
 	IsRemote  bool
+	Upstream  string // e.g. origin/main, rỗng nếu chưa set upstream
 }
 
 // CommitCount đại diện cho số lượng commit ahead/behind của branch
@@ -124,28 +197,11 @@ type BranchCommitCounts map[string]CommitCount
 
 // ListBranches returns all local branches
 func (r Runner) ListBranches() ([]Branch, error) {
-	out, err := r.run(DefaultCmdTimeout, "branch", "--format=%(HEAD)%(refname:short)")
+	out, err := r.run(DefaultCmdTimeout, "branch", "--format=%(HEAD)%(refname:short)%09%(upstream:short)")
 	if err != nil {
 		return nil, err
 	}
-	var branches []Branch
-	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
-		if line == "" {
-			continue
-		}
-		isCurrent := false
-		name := line
-		if len(line) > 0 && line[0] == '*' {
-			isCurrent = true
-			name = line[1:]
-		}
-		branches = append(branches, Branch{
-			Name:      strings.TrimSpace(name),
-			IsCurrent: isCurrent,
-			IsRemote:  false,
-		})
-	}
-	return branches, nil
+	return ParseBranches(out), nil
 }
 
 // StashEntry represents a git stash entry
@@ -185,20 +241,40 @@ func (r Runner) ListStash() ([]StashEntry, error) {
 }
 
 // ShowStash shows the diff for a stash entry
-func (r Runner) ShowStash(ref string) (string, error) {
-	return r.run(DefaultDiffTimeout, "stash", "show", "-p", ref)
+func (r Runner) ShowStash(ref string, opts DiffOptions) (string, error) {
+	return r.run(DefaultDiffTimeout, showStashArgs(ref, opts)...)
+}
+
+// StreamShowStash như ShowStash nhưng stream vào DiffBuffer
+func (r Runner) StreamShowStash(ref string, opts DiffOptions) (*DiffBuffer, error) {
+	return r.streamSummarizedDiff(false, opts.SmudgeLFS, showStashArgs(ref, opts)...)
+}
+
+func showStashArgs(ref string, opts DiffOptions) []string {
+	args := append([]string{"stash", "show", "-p"}, opts.Args()...)
+	return append(args, ref)
 }
 
 // DiffBranch shows the diff between current HEAD and a branch
-func (r Runner) DiffBranch(branch string) (string, error) {
-	return r.run(DefaultDiffTimeout, "diff", branch+"...HEAD")
+func (r Runner) DiffBranch(branch string, opts DiffOptions) (string, error) {
+	return r.run(DefaultDiffTimeout, diffBranchArgs(branch, opts)...)
+}
+
+// StreamDiffBranch như DiffBranch nhưng stream vào DiffBuffer
+func (r Runner) StreamDiffBranch(branch string, opts DiffOptions) (*DiffBuffer, error) {
+	return r.streamSummarizedDiff(false, opts.SmudgeLFS, diffBranchArgs(branch, opts)...)
+}
+
+func diffBranchArgs(branch string, opts DiffOptions) []string {
+	args := append([]string{"diff"}, opts.Args()...)
+	return append(args, branch+"...HEAD")
 }
 
 // ========== HIGH PRIORITY FEATURES ==========
 
-// DiscardFile discards changes in a file (unstaged changes only)
-func (r Runner) DiscardFile(path string) error {
-	_, err := r.run(DefaultCmdTimeout, "checkout", "--", path)
+// DiscardFile discards changes in files (unstaged changes only)
+func (r Runner) DiscardFile(paths ...string) error {
+	_, err := r.run(DefaultCmdTimeout, literalPathArgs([]string{"checkout"}, paths...)...)
 	return err
 }
 
@@ -223,7 +299,7 @@ func (r Runner) UnstageHunk(path string, hunkContent string) error {
 	header := "diff --git a/" + path + " b/" + path + "\n"
 	fullPatch := header + hunkContent + "\n"
 
-	cmd := exec.Command("git", "apply", "-R", "-", "--", path)
+	cmd := exec.CommandContext(r.Context(), "git", "apply", "-R", "-", "--", path)
 	cmd.Dir = r.RepoRoot
 	cmd.Stdin = strings.NewReader(fullPatch)
 
@@ -236,7 +312,7 @@ func (r Runner) UnstageHunk(path string, hunkContent string) error {
 }
 
 func (r Runner) runWithStdin(stdin string, timeout time.Duration, args ...string) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "git", args...)
@@ -251,6 +327,9 @@ func (r Runner) runWithStdin(stdin string, timeout time.Duration, args ...string
 	cmd.Stderr = &stderr
 
 	err := cmd.Run()
+	if r.Context().Err() != nil {
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), ErrCancelled)
+	}
 	if ctx.Err() == context.DeadlineExceeded {
 		return "", fmt.Errorf("git %s: timeout", strings.Join(args, " "))
 	}
@@ -262,7 +341,7 @@ func (r Runner) runWithStdin(stdin string, timeout time.Duration, args ...string
 		if errText == "" {
 			errText = err.Error()
 		}
-		return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), errText)
+		return "", &CommandError{Args: args, Stderr: stderr.String(), Text: errText}
 	}
 
 	return stdout.String(), nil
@@ -285,30 +364,50 @@ func reverseHunk(hunkContent string) string {
 	return strings.Join(result, "\n")
 }
 
-// DiscardUntracked removes an untracked file
-func (r Runner) DiscardUntracked(path string) error {
-	_, err := r.run(DefaultCmdTimeout, "clean", "-f", "--", path)
+// DiscardUntracked removes untracked files (-d: git status báo thư mục untracked dạng "dir/")
+func (r Runner) DiscardUntracked(paths ...string) error {
+	_, err := r.run(DefaultCmdTimeout, literalPathArgs([]string{"clean", "-f", "-d"}, paths...)...)
 	return err
 }
 
-// Pull fetches and merges from remote
-func (r Runner) Pull() (string, error) {
-	return r.run(NetworkTimeout, "pull")
+// CheckoutBranch switches to a branch
+func (r Runner) CheckoutBranch(branch string) (string, error) {
+	return r.run(DefaultCmdTimeout, "checkout", branch)
 }
 
-// Push pushes to remote
-func (r Runner) Push() (string, error) {
-	return r.run(NetworkTimeout, "push")
+// CheckoutCommit checks out a commit as detached HEAD
+func (r Runner) CheckoutCommit(hash string) (string, error) {
+	return r.run(DefaultCmdTimeout, "checkout", "--detach", hash)
 }
 
-// PushSetUpstream pushes and sets upstream for new branch
-func (r Runner) PushSetUpstream(remote, branch string) (string, error) {
-	return r.run(NetworkTimeout, "push", "-u", remote, branch)
+// HasParent kiểm tra commit có parent không (false với root commit)
+func (r Runner) HasParent(hash string) bool {
+	_, err := r.run(DefaultCmdTimeout, "rev-parse", "--verify", "-q", hash+"~1")
+	return err == nil
 }
 
-// CheckoutBranch switches to a branch
-func (r Runner) CheckoutBranch(branch string) (string, error) {
-	return r.run(DefaultCmdTimeout, "checkout", branch)
+// HeadShortHash returns the abbreviated hash of HEAD
+func (r Runner) HeadShortHash() (string, error) {
+	out, err := r.run(DefaultCmdTimeout, "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// PreviousBranch tìm branch được checkout gần nhất trước đó (bỏ qua các lần checkout commit detached)
+func (r Runner) PreviousBranch() (string, error) {
+	for n := 1; n <= 20; n++ {
+		out, err := r.run(DefaultCmdTimeout, "rev-parse", "--symbolic-full-name", fmt.Sprintf("@{-%d}", n))
+		if err != nil {
+			break
+		}
+		ref := strings.TrimSpace(out)
+		if strings.HasPrefix(ref, "refs/heads/") {
+			return strings.TrimPrefix(ref, "refs/heads/"), nil
+		}
+	}
+	return "", errors.New("no previous branch found")
 }
 
 // CreateBranch creates a new branch and switches to it
@@ -318,11 +417,6 @@ func (r Runner) CreateBranch(name string) (string, error) {
 
 // ========== MEDIUM PRIORITY FEATURES ==========
 
-// Fetch fetches from remote
-func (r Runner) Fetch() (string, error) {
-	return r.run(NetworkTimeout, "fetch", "--all", "--prune")
-}
-
 // DeleteBranch deletes a local branch
 func (r Runner) DeleteBranch(name string) error {
 	_, err := r.run(DefaultCmdTimeout, "branch", "-d", name)
@@ -335,6 +429,16 @@ func (r Runner) DeleteBranchForce(name string) error {
 	return err
 }
 
+// StashPush stashes changes of the given paths (kể cả file untracked), message rỗng dùng mặc định của git
+func (r Runner) StashPush(message string, paths ...string) error {
+	args := []string{"stash", "push", "--include-untracked"}
+	if message != "" {
+		args = append(args, "-m", message)
+	}
+	_, err := r.run(DefaultCmdTimeout, literalPathArgs(args, paths...)...)
+	return err
+}
+
 // StashApply applies a stash entry without removing it
 func (r Runner) StashApply(ref string) error {
 	_, err := r.run(DefaultCmdTimeout, "stash", "apply", ref)
@@ -353,14 +457,6 @@ func (r Runner) StashDrop(ref string) error {
 	return err
 }
 
-// CommitAmend amends the last commit with staged changes
-func (r Runner) CommitAmend(message string) (string, error) {
-	if message == "" {
-		return r.run(DefaultCmdTimeout, "commit", "--amend", "--no-edit")
-	}
-	return r.run(DefaultCmdTimeout, "commit", "--amend", "-m", message)
-}
-
 // ResetSoftHead resets to previous commit, keeping changes staged
 func (r Runner) ResetSoftHead(n int) error {
 	_, err := r.run(DefaultCmdTimeout, "reset", "--soft", fmt.Sprintf("HEAD~%d", n))
@@ -461,44 +557,76 @@ func ParseCommitCountOutput(output string) (int, int, error) {
 	return behind, ahead, nil
 }
 
+// literalPathArgs thêm path do người dùng chọn vào sau "--" và bật --literal-pathspecs
+// để tên file chứa ký tự glob (e.g. "[id].tsx") không khớp nhầm sang file khác
+func literalPathArgs(args []string, paths ...string) []string {
+	out := append([]string{"--literal-pathspecs"}, args...)
+	return append(append(out, "--"), paths...)
+}
+
 func (r Runner) run(timeout time.Duration, args ...string) (string, error) {
-	out, err := runRaw(r.RepoRoot, timeout, args...)
+	out, err := r.runBytes(timeout, args...)
 	if err != nil {
 		return "", err
 	}
-	return out, nil
+	return string(out), nil
+}
+
+// runNetwork chạy lệnh mạng (fetch/push/pull) qua StartNetworkStream và chờ
+// tới khi kết thúc
+func (r Runner) runNetwork(args ...string) (string, error) {
+	s, err := r.StartNetworkStream(args...)
+	if err != nil {
+		return "", err
+	}
+	return s.Drain()
 }
 
 func (r Runner) runBytes(timeout time.Duration, args ...string) ([]byte, error) {
-	out, err := runRawBytes(r.RepoRoot, timeout, args...)
+	out, err := runRawBytes(r.Context(), r.RepoRoot, r.env(), timeout, args...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func runRaw(repoRoot string, timeout time.Duration, args ...string) (string, error) {
-	b, err := runRawBytes(repoRoot, timeout, args...)
+// env trả về biến môi trường thêm vào lệnh git của Runner
+func (r Runner) env() []string {
+	if r.reflogAction == "" {
+		return nil
+	}
+	return []string{"GIT_REFLOG_ACTION=" + r.reflogAction}
+}
+
+func runRaw(parent context.Context, repoRoot string, timeout time.Duration, args ...string) (string, error) {
+	b, err := runRawBytes(parent, repoRoot, nil, timeout, args...)
 	if err != nil {
 		return "", err
 	}
 	return string(b), nil
 }
 
-func runRawBytes(repoRoot string, timeout time.Duration, args ...string) ([]byte, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+// runRawBytes chạy git với timeout và env bổ sung; parent bị cancel thì lệnh dừng và trả về ErrCancelled
+func runRawBytes(parent context.Context, repoRoot string, env []string, timeout time.Duration, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(parent, timeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "git", args...)
 	if repoRoot != "" {
 		cmd.Dir = repoRoot
 	}
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
 	err := cmd.Run()
+	if parent.Err() != nil {
+		return nil, fmt.Errorf("git %s: %w", strings.Join(args, " "), ErrCancelled)
+	}
 	if ctx.Err() == context.DeadlineExceeded {
 		return nil, fmt.Errorf("git %s: timeout", strings.Join(args, " "))
 	}
@@ -510,7 +638,7 @@ func runRawBytes(repoRoot string, timeout time.Duration, args ...string) ([]byte
 		if errText == "" {
 			errText = err.Error()
 		}
-		return nil, fmt.Errorf("git %s: %s", strings.Join(args, " "), errText)
+		return nil, &CommandError{Args: args, Stderr: stderr.String(), Text: errText}
 	}
 
 	return stdout.Bytes(), nil
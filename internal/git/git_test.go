@@ -0,0 +1,55 @@
+package git
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// tên file có ký tự glob (e.g. "[ab].go") chỉ khớp đúng file đó khi stage/stash
+func TestStageAndStashLiteralPaths(t *testing.T) {
+	r := initTestRepo(t, map[string]string{"[ab].go": "1\n", "a.go": "1\n", "b.go": "1\n"})
+	for _, name := range []string{"[ab].go", "a.go", "b.go"} {
+		writeTestFile(t, filepath.Join(r.RepoRoot, name), "2\n")
+	}
+	staged := func() []string {
+		t.Helper()
+		out, err := r.run(DefaultCmdTimeout, "diff", "--cached", "--name-only")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Fields(out)
+	}
+
+	if err := r.Add("[ab].go"); err != nil {
+		t.Fatal(err)
+	}
+	if got := staged(); !reflect.DeepEqual(got, []string{"[ab].go"}) {
+		t.Errorf("staged after Add = %q", got)
+	}
+	if err := r.Add("."); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RestoreStaged("[ab].go"); err != nil {
+		t.Fatal(err)
+	}
+	if got := staged(); !reflect.DeepEqual(got, []string{"a.go", "b.go"}) {
+		t.Errorf("staged after RestoreStaged = %q", got)
+	}
+
+	if err := r.RestoreStaged("a.go", "b.go"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.StashPush("", "[ab].go"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fileContent(filepath.Join(r.RepoRoot, "[ab].go")); got != "1\n" {
+		t.Errorf("[ab].go = %q, should be stashed", got)
+	}
+	for _, name := range []string{"a.go", "b.go"} {
+		if got := fileContent(filepath.Join(r.RepoRoot, name)); got != "2\n" {
+			t.Errorf("%s = %q, should not be stashed", name, got)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreEntries thêm pattern cho các path vào nội dung .gitignore. Pattern được
+// neo vào gốc repo ("/path") để không khớp file cùng tên ở thư mục khác và được
+// escape để chỉ khớp đúng path đó; path đã có trong file thì bỏ qua. Trả về nội
+// dung mới và các pattern được thêm.
+func IgnoreEntries(existing string, paths []string) (string, []string) {
+	have := make(map[string]bool)
+	for _, line := range strings.Split(existing, "\n") {
+		// giữ cả dạng chưa trim vì khoảng trắng cuối đã escape là một phần của pattern
+		have[strings.TrimSuffix(line, "\r")] = true
+		have[strings.TrimSpace(line)] = true
+	}
+	var added []string
+	for _, path := range paths {
+		if strings.ContainsAny(path, "\n\r") {
+			// .gitignore không biểu diễn được path nhiều dòng
+			continue
+		}
+		pattern := "/" + escapeIgnorePattern(strings.TrimPrefix(filepath.ToSlash(path), "/"))
+		if have[pattern] || have[strings.TrimPrefix(pattern, "/")] {
+			continue
+		}
+		have[pattern] = true
+		added = append(added, pattern)
+	}
+	if len(added) == 0 {
+		return existing, nil
+	}
+	content := existing
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + strings.Join(added, "\n") + "\n", added
+}
+
+// escapeIgnorePattern escape ký tự đặc biệt của gitignore trong path: glob
+// (*, ?, [), backslash, # hoặc ! ở đầu và khoảng trắng ở cuối (git bỏ qua nếu không escape)
+func escapeIgnorePattern(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case c == '\\' || c == '*' || c == '?' || c == '[':
+			b.WriteByte('\\')
+		case i == 0 && (c == '#' || c == '!'):
+			b.WriteByte('\\')
+		case c == ' ' && strings.TrimRight(path[i:], " ") == "":
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// Ignore thêm các path vào .gitignore ở gốc repo, trả về các pattern được thêm
+func (r Runner) Ignore(paths ...string) ([]string, error) {
+	path := filepath.Join(r.RepoRoot, ".gitignore")
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read .gitignore: %w", err)
+	}
+	content, added := IgnoreEntries(string(data), paths)
+	if len(added) == 0 {
+		return nil, nil
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return nil, fmt.Errorf("write .gitignore: %w", err)
+	}
+	return added, nil
+}
+
+// Untrack bỏ các path khỏi index (git rm -r --cached), file vẫn còn trên đĩa;
+// cần làm trước khi ignore file đang được track vì .gitignore không áp dụng cho chúng
+func (r Runner) Untrack(paths ...string) error {
+	_, err := r.run(DefaultCmdTimeout, literalPathArgs([]string{"rm", "-r", "--cached", "--quiet"}, paths...)...)
+	return err
+}
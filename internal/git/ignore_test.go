@@ -0,0 +1,70 @@
+package git
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIgnoreEntries(t *testing.T) {
+	content, added := IgnoreEntries("", []string{"build/", "tmp.log"})
+	if content != "/build/\n/tmp.log\n" || !reflect.DeepEqual(added, []string{"/build/", "/tmp.log"}) {
+		t.Fatalf("IgnoreEntries(empty) = %q, %q", content, added)
+	}
+
+	content, added = IgnoreEntries("node_modules\n/tmp.log", []string{"tmp.log", "node_modules", "a/b.txt", "a/b.txt"})
+	if want := "node_modules\n/tmp.log\n/a/b.txt\n"; content != want {
+		t.Errorf("content = %q, want %q", content, want)
+	}
+	if !reflect.DeepEqual(added, []string{"/a/b.txt"}) {
+		t.Errorf("added = %q", added)
+	}
+
+	content, added = IgnoreEntries("x\n", []string{"x"})
+	if content != "x\n" || added != nil {
+		t.Errorf("duplicate should not change content: %q, %q", content, added)
+	}
+}
+
+func TestEscapeIgnorePattern(t *testing.T) {
+	tests := map[string]string{
+		"plain/file.go": "plain/file.go",
+		"a*b.txt":       `a\*b.txt`,
+		"q?.txt":        `q\?.txt`,
+		"[x].txt":       `\[x].txt`,
+		"back\\slash":   `back\\slash`,
+		"#notes":        `\#notes`,
+		"!bang":         `\!bang`,
+		"dir/#x":        "dir/#x",
+		"sp  ":          `sp\ \ `,
+		"a b/":          "a b/",
+	}
+	for path, want := range tests {
+		if got := escapeIgnorePattern(path); got != want {
+			t.Errorf("escapeIgnorePattern(%q) = %q, want %q", path, got, want)
+		}
+	}
+
+	content, added := IgnoreEntries("/a\\*b.txt\n", []string{"a*b.txt", "#x", "bad\nname"})
+	if want := "/a\\*b.txt\n/\\#x\n"; content != want || !reflect.DeepEqual(added, []string{`/\#x`}) {
+		t.Errorf("IgnoreEntries = %q, %q; want %q", content, added, want)
+	}
+}
+
+func TestUntrackLiteralPaths(t *testing.T) {
+	r := initTestRepo(t, map[string]string{"[ab].go": "1\n", "a.go": "1\n", "b.go": "1\n"})
+	if err := r.Untrack("[ab].go"); err != nil {
+		t.Fatal(err)
+	}
+	out, err := r.run(DefaultCmdTimeout, "ls-files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Fields(out); !reflect.DeepEqual(got, []string{"a.go", "b.go"}) {
+		t.Errorf("tracked after Untrack = %q, want a.go b.go", got)
+	}
+	if fileContent(filepath.Join(r.RepoRoot, "[ab].go")) != "1\n" {
+		t.Error("Untrack should keep the file on disk")
+	}
+}
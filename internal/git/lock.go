@@ -0,0 +1,165 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// IndexLock mô tả file .git/index.lock đang tồn tại
+type IndexLock struct {
+	Path       string
+	ModTime    time.Time
+	GitRunning bool   // có process git khác đang chạy trong repo, lock có thể chưa stale
+	Holder     string // process git đó, e.g. "pid 123: git commit"
+}
+
+// Stale cho biết lock có thể xoá an toàn (không còn process git nào của repo giữ nó)
+func (l IndexLock) Stale() bool {
+	return !l.GitRunning
+}
+
+// Age là thời gian từ lần cuối lock được ghi
+func (l IndexLock) Age() time.Duration {
+	return time.Since(l.ModTime).Round(time.Second)
+}
+
+// IsIndexLockError kiểm tra lỗi do index.lock đã tồn tại
+// ("Unable to create '.../index.lock': File exists.")
+func IsIndexLockError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "index.lock") && strings.Contains(msg, "File exists")
+}
+
+// IndexLockPath trả về đường dẫn tuyệt đối của index.lock (đúng cả với worktree)
+func (r Runner) IndexLockPath() (string, error) {
+	out, err := r.run(DefaultCmdTimeout, "rev-parse", "--git-path", "index.lock")
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve index.lock path: %w", err)
+	}
+	path := strings.TrimSpace(out)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.RepoRoot, path)
+	}
+	return path, nil
+}
+
+// CheckIndexLock trả về thông tin index.lock, found=false nếu không có lock
+func (r Runner) CheckIndexLock() (lock IndexLock, found bool, err error) {
+	path, err := r.IndexLockPath()
+	if err != nil {
+		return IndexLock{}, false, err
+	}
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return IndexLock{}, false, nil
+	}
+	if err != nil {
+		return IndexLock{}, false, fmt.Errorf("cannot stat %s: %w", path, err)
+	}
+
+	lock = IndexLock{Path: path, ModTime: info.ModTime()}
+	procs, err := listGitProcesses()
+	if err != nil {
+		// Không xác định được thì coi như đang có process, không cho xoá
+		lock.GitRunning = true
+		lock.Holder = "unknown (cannot list processes: " + err.Error() + ")"
+		return lock, true, nil
+	}
+	if p, ok := lockHolder(procs, []string{r.RepoRoot, filepath.Dir(path)}); ok {
+		lock.GitRunning = true
+		lock.Holder = fmt.Sprintf("pid %d: %s", p.pid, p.args)
+	}
+	return lock, true, nil
+}
+
+// RemoveIndexLock xoá index.lock sau khi kiểm tra lại không có process git nào của repo
+// đang chạy; force xoá cả khi có (người dùng đã xác nhận sau khi xem tuổi của lock)
+func (r Runner) RemoveIndexLock(force bool) error {
+	lock, found, err := r.CheckIndexLock()
+	if err != nil {
+		return err
+	}
+	if !found {
+		return nil
+	}
+	if !lock.Stale() && !force {
+		return fmt.Errorf("cannot remove %s: git process %s is running", lock.Path, lock.Holder)
+	}
+	if err := os.Remove(lock.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("cannot remove %s: %w", lock.Path, err)
+	}
+	return nil
+}
+
+// isGitProcessName nhận diện tên process của git (git, git-remote-https, git.exe...)
+func isGitProcessName(name string) bool {
+	name = strings.ToLower(filepath.Base(strings.TrimSpace(name)))
+	name = strings.TrimSuffix(name, ".exe")
+	return name == "git" || strings.HasPrefix(name, "git-")
+}
+
+// gitProcess là một process đang chạy; cwd rỗng khi không biết thư mục làm việc
+type gitProcess struct {
+	pid  int
+	name string
+	args string
+	cwd  string
+}
+
+// lockHolder tìm process git có thể đang giữ index.lock: chạy trong một trong các
+// thư mục dirs (repo, git dir) hoặc không rõ thư mục, bỏ qua daemon chạy lâu không
+// giữ index.lock (credential cache, fsmonitor)
+func lockHolder(procs []gitProcess, dirs []string) (gitProcess, bool) {
+	roots := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+			dir = resolved
+		}
+		roots = append(roots, dir)
+	}
+	for _, p := range procs {
+		if !isGitProcessName(p.name) || isGitDaemon(p.args) {
+			continue
+		}
+		if p.cwd == "" {
+			return p, true
+		}
+		for _, dir := range roots {
+			if rel, err := filepath.Rel(dir, p.cwd); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return p, true
+			}
+		}
+	}
+	return gitProcess{}, false
+}
+
+// isGitDaemon nhận diện process git chạy nền lâu dài (credential cache, fsmonitor,
+// git daemon) theo tên chương trình hoặc subcommand đầu tiên, không theo tham số
+// khác (e.g. commit message)
+func isGitDaemon(args string) bool {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return false
+	}
+	daemons := map[string]bool{"credential-cache--daemon": true, "fsmonitor--daemon": true, "daemon": true}
+	name := strings.TrimSuffix(strings.ToLower(filepath.Base(fields[0])), ".exe")
+	if sub, ok := strings.CutPrefix(name, "git-"); ok {
+		return daemons[sub]
+	}
+	if name != "git" {
+		return false
+	}
+	for _, f := range fields[1:] {
+		if !strings.HasPrefix(f, "-") {
+			return daemons[f]
+		}
+	}
+	return false
+}
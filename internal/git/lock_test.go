@@ -0,0 +1,124 @@
+package git
+
+import (
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsIndexLockError(t *testing.T) {
+	lockErr := &CommandError{
+		Args: []string{"add", "a"},
+		Text: "fatal: Unable to create '/repo/.git/index.lock': File exists.",
+	}
+	if !IsIndexLockError(lockErr) {
+		t.Error("expected index.lock error to be detected")
+	}
+	if IsIndexLockError(errors.New("fatal: pathspec 'x' did not match any files")) {
+		t.Error("unrelated error should not be detected")
+	}
+	if IsIndexLockError(nil) {
+		t.Error("nil error should not be detected")
+	}
+}
+
+func TestIsGitProcessName(t *testing.T) {
+	tests := map[string]bool{
+		"git":                  true,
+		"/usr/bin/git":         true,
+		"git-remote-https":     true,
+		"git.exe":              true,
+		"Git.EXE":              true,
+		"gitzen":               false,
+		"lazygit":              false,
+		"":                     false,
+		"  git  ":              true,
+		"/usr/lib/git-core/gi": false,
+	}
+	for name, want := range tests {
+		if got := isGitProcessName(name); got != want {
+			t.Errorf("isGitProcessName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestLockHolder(t *testing.T) {
+	repo := t.TempDir()
+	gitDir := filepath.Join(repo, ".git")
+	other := t.TempDir()
+	tests := []struct {
+		name string
+		proc gitProcess
+		want bool
+	}{
+		{"git in repo", gitProcess{name: "git", args: "git commit -m x", cwd: repo}, true},
+		{"git in subdirectory", gitProcess{name: "git", args: "git add .", cwd: filepath.Join(repo, "src")}, true},
+		{"git in git dir", gitProcess{name: "git", args: "git gc", cwd: gitDir}, true},
+		{"git in other repo", gitProcess{name: "git", args: "git commit", cwd: other}, false},
+		{"sibling with same prefix", gitProcess{name: "git", args: "git commit", cwd: repo + "-copy"}, false},
+		{"unknown cwd", gitProcess{name: "git.exe", args: "git.exe"}, true},
+		{"credential daemon", gitProcess{name: "git-credential-cache--daemon", args: "git-credential-cache--daemon /tmp/sock", cwd: repo}, false},
+		{"fsmonitor daemon", gitProcess{name: "git", args: "git fsmonitor--daemon run --detach", cwd: repo}, false},
+		{"message mentioning a daemon", gitProcess{name: "git", args: "git commit -m fsmonitor--daemon", cwd: repo}, true},
+		{"not git", gitProcess{name: "gitzen", args: "gitzen", cwd: repo}, false},
+	}
+	for _, tt := range tests {
+		if _, got := lockHolder([]gitProcess{tt.proc}, []string{repo, gitDir}); got != tt.want {
+			t.Errorf("%s: lockHolder = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// process git đang chạy ở repo khác không chặn việc xoá index.lock stale
+func TestCheckIndexLockIgnoresOtherRepos(t *testing.T) {
+	r := initTestRepo(t, nil)
+	other := initTestRepo(t, nil)
+	path, err := r.IndexLockPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, path, "")
+
+	sleep := func(dir string) *exec.Cmd {
+		cmd := exec.Command("git", "-c", "alias.wait=!sleep 5", "wait")
+		cmd.Dir = dir
+		if err := cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = cmd.Process.Kill(); _ = cmd.Wait() })
+		return cmd
+	}
+
+	sleep(other.RepoRoot)
+	lock, found, err := r.CheckIndexLock()
+	if err != nil || !found {
+		t.Fatalf("CheckIndexLock = %v, %v", found, err)
+	}
+	if !lock.Stale() {
+		t.Errorf("git in another repo should not hold the lock: %s", lock.Holder)
+	}
+
+	cmd := sleep(r.RepoRoot)
+	// chờ process exec xong thành git
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if lock, _, _ = r.CheckIndexLock(); !lock.Stale() {
+			break
+		}
+	}
+	if lock.Stale() || !strings.Contains(lock.Holder, strconv.Itoa(cmd.Process.Pid)) {
+		t.Errorf("git in this repo should hold the lock, holder %q", lock.Holder)
+	}
+	if err := r.RemoveIndexLock(false); err == nil {
+		t.Error("RemoveIndexLock should refuse while git runs in the repo")
+	}
+	if err := r.RemoveIndexLock(true); err != nil {
+		t.Fatal(err)
+	}
+	if _, found, _ := r.CheckIndexLock(); found {
+		t.Error("forced RemoveIndexLock should remove the lock")
+	}
+}
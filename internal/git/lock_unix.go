@@ -0,0 +1,106 @@
+//go:build !windows
+
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// listGitProcesses liệt kê process git đang chạy kèm thư mục làm việc: đọc /proc trên
+// Linux, dùng ps và lsof trên macOS/BSD
+func listGitProcesses() ([]gitProcess, error) {
+	if entries, err := os.ReadDir("/proc"); err == nil && procHasSelf() {
+		return procGitProcesses(entries), nil
+	}
+	return psGitProcesses()
+}
+
+func procHasSelf() bool {
+	_, err := os.Stat("/proc/self/cwd")
+	return err == nil
+}
+
+// procGitProcesses đọc tên, command line và cwd từ /proc/<pid>. Process không đọc
+// được cwd (của user khác) không thể ghi vào repo của user này nên bị bỏ qua.
+func procGitProcesses(entries []os.DirEntry) []gitProcess {
+	var procs []gitProcess
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil || pid == os.Getpid() {
+			continue
+		}
+		dir := filepath.Join("/proc", e.Name())
+		comm, err := os.ReadFile(filepath.Join(dir, "comm"))
+		if err != nil || !isGitProcessName(string(comm)) {
+			continue
+		}
+		cwd, err := os.Readlink(filepath.Join(dir, "cwd"))
+		if err != nil {
+			continue
+		}
+		cmdline, _ := os.ReadFile(filepath.Join(dir, "cmdline"))
+		args := strings.TrimSpace(string(bytes.ReplaceAll(cmdline, []byte{0}, []byte{' '})))
+		procs = append(procs, gitProcess{pid: pid, name: strings.TrimSpace(string(comm)), args: args, cwd: cwd})
+	}
+	return procs
+}
+
+// psGitProcesses dùng ps để tìm process git và lsof để lấy cwd; không lấy được cwd
+// thì để trống (coi như có thể đang giữ lock)
+func psGitProcesses() ([]gitProcess, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultCmdTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "ps", "-A", "-o", "pid=,args=").Output()
+	if err != nil {
+		return nil, err
+	}
+	var procs []gitProcess
+	var pids []string
+	for _, line := range strings.Split(string(out), "\n") {
+		pidText, args, ok := strings.Cut(strings.TrimSpace(line), " ")
+		pid, err := strconv.Atoi(pidText)
+		if !ok || err != nil || pid == os.Getpid() {
+			continue
+		}
+		args = strings.TrimSpace(args)
+		name := ""
+		if fields := strings.Fields(args); len(fields) > 0 {
+			name = fields[0]
+		}
+		if !isGitProcessName(name) {
+			continue
+		}
+		procs = append(procs, gitProcess{pid: pid, name: name, args: args})
+		pids = append(pids, pidText)
+	}
+	if len(procs) == 0 {
+		return nil, nil
+	}
+
+	// lsof -Fpn: dòng "p<pid>" rồi "n<cwd>"
+	out, _ = exec.CommandContext(ctx, "lsof", "-a", "-d", "cwd", "-p", strings.Join(pids, ","), "-Fpn").Output()
+	cwds := make(map[int]string)
+	pid := 0
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "p"):
+			pid, _ = strconv.Atoi(line[1:])
+		case strings.HasPrefix(line, "n") && pid != 0:
+			cwds[pid] = line[1:]
+		}
+	}
+	for i := range procs {
+		procs[i].cwd = cwds[procs[i].pid]
+	}
+	return procs, nil
+}
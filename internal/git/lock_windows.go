@@ -0,0 +1,34 @@
+//go:build windows
+
+package git
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// listGitProcesses liệt kê process git đang chạy (dùng tasklist). tasklist không cho
+// biết thư mục làm việc nên cwd để trống: mọi process git đều có thể đang giữ lock.
+func listGitProcesses() ([]gitProcess, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultCmdTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "tasklist", "/FO", "CSV", "/NH").Output()
+	if err != nil {
+		return nil, err
+	}
+	var procs []gitProcess
+	for _, line := range strings.Split(string(out), "\n") {
+		// "git.exe","1234","Console","1","40,000 K"
+		fields := strings.Split(line, ",")
+		name := strings.Trim(fields[0], `"`)
+		if !isGitProcessName(name) || len(fields) < 2 {
+			continue
+		}
+		pid, _ := strconv.Atoi(strings.Trim(fields[1], `"`))
+		procs = append(procs, gitProcess{pid: pid, name: name, args: name})
+	}
+	return procs, nil
+}
@@ -3,9 +3,10 @@ package git
 import "bytes"
 
 type FileItem struct {
-	Path   string
-	Status string
-	Staged bool
+	Path     string
+	Status   string
+	Staged   bool
+	OrigPath string // path gốc khi status là R/C (rename/copy)
 }
 
 type Status struct {
@@ -16,7 +17,9 @@ type Status struct {
 func ParseStatusPorcelainV1Z(data []byte) Status {
 	var staged []FileItem
 	var unstaged []FileItem
-	for _, entry := range bytes.Split(data, []byte{0}) {
+	entries := bytes.Split(data, []byte{0})
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
 		if len(entry) == 0 {
 			continue
 		}
@@ -27,15 +30,23 @@ func ParseStatusPorcelainV1Z(data []byte) Status {
 		x := entry[0]
 		y := entry[1]
 		path := string(bytes.TrimSpace(entry[2:]))
+		// rename/copy có thêm một field -z chứa path gốc ngay sau entry
+		var orig string
+		if x == 'R' || x == 'C' || y == 'R' || y == 'C' {
+			if i+1 < len(entries) {
+				orig = string(entries[i+1])
+				i++
+			}
+		}
 		if path == "" {
 			continue
 		}
 
 		if x != ' ' {
-			staged = append(staged, FileItem{Path: path, Status: string(x), Staged: true})
+			staged = append(staged, FileItem{Path: path, Status: string(x), Staged: true, OrigPath: orig})
 		}
 		if y != ' ' {
-			unstaged = append(unstaged, FileItem{Path: path, Status: string(y), Staged: false})
+			unstaged = append(unstaged, FileItem{Path: path, Status: string(y), Staged: false, OrigPath: orig})
 		}
 	}
 	return Status{Staged: staged, Unstaged: unstaged}
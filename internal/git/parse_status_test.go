@@ -162,3 +162,22 @@ func TestParseStatusPorcelainV1Z_EmptyPath(t *testing.T) {
 		t.Errorf("expected 0 unstaged (empty path), got %d", len(result.Unstaged))
 	}
 }
+
+func TestParseStatusPorcelainV1Z_Rename(t *testing.T) {
+	// rename có thêm field path gốc, không được đọc thành một entry riêng
+	data := []byte("RM b.txt\x00a.txt\x00A  d.txt\x00")
+	result := ParseStatusPorcelainV1Z(data)
+
+	if len(result.Staged) != 2 {
+		t.Fatalf("expected 2 staged, got %+v", result.Staged)
+	}
+	if got := result.Staged[0]; got.Path != "b.txt" || got.Status != "R" || got.OrigPath != "a.txt" {
+		t.Errorf("unexpected rename entry %+v", got)
+	}
+	if result.Staged[1].Path != "d.txt" {
+		t.Errorf("expected d.txt after rename, got %+v", result.Staged[1])
+	}
+	if len(result.Unstaged) != 1 || result.Unstaged[0].OrigPath != "a.txt" {
+		t.Errorf("unexpected unstaged %+v", result.Unstaged)
+	}
+}
@@ -0,0 +1,152 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PatchRevs trả về revision args cho format-patch: một commit, hoặc từ commit
+// đó tới HEAD (--root khi commit không có parent)
+func PatchRevs(hash string, toHead, hasParent bool) []string {
+	if !toHead {
+		return []string{"-1", hash}
+	}
+	if !hasParent {
+		return []string{"--root", "HEAD"}
+	}
+	return []string{hash + "~1..HEAD"}
+}
+
+// ResolvePatchPath mở rộng "~" và đưa path tương đối về thư mục repo
+func ResolvePatchPath(repoRoot, path string) string {
+	path = strings.TrimSpace(path)
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(repoRoot, path)
+	}
+	return filepath.Clean(path)
+}
+
+// FormatPatch xuất các commit thành file .patch trong outDir, trả về danh sách file
+func (r Runner) FormatPatch(revs []string, outDir string) ([]string, error) {
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return nil, fmt.Errorf("cannot create %s: %w", outDir, err)
+	}
+	args := append([]string{"format-patch", "-o", outDir}, revs...)
+	out, err := r.run(DefaultDiffTimeout, args...)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// FormatPatchMbox xuất các commit thành một file mbox duy nhất, trả về số patch
+func (r Runner) FormatPatchMbox(revs []string, path string) (int, error) {
+	args := append([]string{"format-patch", "--stdout"}, revs...)
+	out, err := r.run(DefaultDiffTimeout, args...)
+	if err != nil {
+		return 0, err
+	}
+	if err := writePatchFile(path, out); err != nil {
+		return 0, err
+	}
+	return CountMboxPatches(out), nil
+}
+
+// writePatchFile ghi nội dung patch/mbox ra path, tạo thư mục cha nếu chưa có
+func writePatchFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("cannot create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("cannot write %s: %w", path, err)
+	}
+	return nil
+}
+
+// CountMboxPatches đếm số patch trong nội dung mbox (mỗi patch bắt đầu bằng "From <sha> ")
+func CountMboxPatches(mbox string) int {
+	n := 0
+	for _, line := range strings.Split(mbox, "\n") {
+		if strings.HasPrefix(line, "From ") && len(line) > 45 {
+			n++
+		}
+	}
+	return n
+}
+
+// PatchFiles trả về các file patch từ path: chính nó nếu là file, hoặc các
+// *.patch / *.diff / *.mbox trong thư mục (sắp xếp theo tên như format-patch đánh số)
+func PatchFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", path, err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", path, err)
+	}
+	var files []string
+	for _, e := range entries {
+		switch filepath.Ext(e.Name()) {
+		case ".patch", ".diff", ".mbox", ".eml":
+			if !e.IsDir() {
+				files = append(files, filepath.Join(path, e.Name()))
+			}
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no patch files in %s", path)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// AmArgs trả về args cho git am, threeWay dùng --3way để để lại conflict thay vì thất bại
+func AmArgs(files []string, threeWay bool) []string {
+	args := []string{"am"}
+	if threeWay {
+		args = append(args, "--3way")
+	}
+	return append(args, files...)
+}
+
+// ApplyArgs trả về args cho git apply: index áp dụng vào cả index, threeWay fallback 3-way merge
+func ApplyArgs(files []string, index, threeWay bool) []string {
+	args := []string{"apply"}
+	if index {
+		args = append(args, "--index")
+	}
+	if threeWay {
+		args = append(args, "--3way")
+	}
+	return append(args, files...)
+}
+
+// StreamAm chạy git am (output của applypatch hook được stream)
+func (r Runner) StreamAm(files []string, threeWay bool) (*Stream, error) {
+	return r.StartStream(AmArgs(files, threeWay)...)
+}
+
+// ApplyPatchFiles chạy git apply với các file patch
+func (r Runner) ApplyPatchFiles(files []string, index, threeWay bool) error {
+	_, err := r.run(DefaultDiffTimeout, ApplyArgs(files, index, threeWay)...)
+	return err
+}
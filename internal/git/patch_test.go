@@ -0,0 +1,83 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPatchRevs(t *testing.T) {
+	tests := []struct {
+		toHead, hasParent bool
+		want              []string
+	}{
+		{false, true, []string{"-1", "abc"}},
+		{false, false, []string{"-1", "abc"}},
+		{true, true, []string{"abc~1..HEAD"}},
+		{true, false, []string{"--root", "HEAD"}},
+	}
+	for _, tt := range tests {
+		if got := PatchRevs("abc", tt.toHead, tt.hasParent); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("PatchRevs(toHead=%v, hasParent=%v) = %v, want %v", tt.toHead, tt.hasParent, got, tt.want)
+		}
+	}
+}
+
+func TestResolvePatchPath(t *testing.T) {
+	if got, want := ResolvePatchPath("/repo", "patches"), filepath.Clean("/repo/patches"); got != want {
+		t.Errorf("relative path = %q, want %q", got, want)
+	}
+	if got, want := ResolvePatchPath("/repo", "/tmp/x.mbox"), filepath.Clean("/tmp/x.mbox"); got != want {
+		t.Errorf("absolute path = %q, want %q", got, want)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		if got, want := ResolvePatchPath("/repo", "~/p"), filepath.Join(home, "p"); got != want {
+			t.Errorf("home path = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestCountMboxPatches(t *testing.T) {
+	mbox := "From 1234567890123456789012345678901234567890 Mon Sep 17 00:00:00 2001\n" +
+		"Subject: [PATCH 1/2] a\n\nFrom the docs\n" +
+		"From abcdefabcdefabcdefabcdefabcdefabcdefabcd Mon Sep 17 00:00:00 2001\n" +
+		"Subject: [PATCH 2/2] b\n"
+	if got := CountMboxPatches(mbox); got != 2 {
+		t.Errorf("CountMboxPatches = %d, want 2", got)
+	}
+}
+
+func TestPatchFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"0002-b.patch", "0001-a.patch", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := PatchFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "0001-a.patch"), filepath.Join(dir, "0002-b.patch")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PatchFiles(dir) = %v, want %v", got, want)
+	}
+
+	single := filepath.Join(dir, "notes.txt")
+	if got, err := PatchFiles(single); err != nil || !reflect.DeepEqual(got, []string{single}) {
+		t.Errorf("PatchFiles(file) = %v, %v", got, err)
+	}
+	if _, err := PatchFiles(t.TempDir()); err == nil {
+		t.Error("PatchFiles on empty dir should error")
+	}
+}
+
+func TestAmAndApplyArgs(t *testing.T) {
+	if got, want := AmArgs([]string{"a.patch"}, true), []string{"am", "--3way", "a.patch"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AmArgs = %v, want %v", got, want)
+	}
+	if got, want := ApplyArgs([]string{"a.patch"}, true, false), []string{"apply", "--index", "a.patch"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ApplyArgs = %v, want %v", got, want)
+	}
+}
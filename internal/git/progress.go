@@ -0,0 +1,107 @@
+package git
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Progress là một dòng tiến trình git ghi ra stderr khi chạy với --progress,
+// e.g. "Receiving objects:  45% (450/1000), 1.20 MiB | 2.00 MiB/s"
+type Progress struct {
+	Phase   string // "Receiving objects", "Resolving deltas", ...
+	Percent int    // -1 khi git chỉ báo số lượng (e.g. "Enumerating objects: 12")
+	Current int
+	Total   int
+	Detail  string // throughput, e.g. "1.20 MiB | 2.00 MiB/s"
+	Done    bool
+}
+
+// progressPhases là các phase git in ra cho fetch/push/pull/clone
+var progressPhases = []string{
+	"Enumerating objects",
+	"Counting objects",
+	"Compressing objects",
+	"Receiving objects",
+	"Resolving deltas",
+	"Writing objects",
+	"Unpacking objects",
+	"Updating files",
+	"Checking out files",
+}
+
+var (
+	progressPercentRe = regexp.MustCompile(`^(\d+)%\s+\((\d+)/(\d+)\)(.*)$`)
+	progressCountRe   = regexp.MustCompile(`^(\d+)(.*)$`)
+)
+
+// ParseProgress parse một dòng progress, ok=false nếu không phải dòng progress
+// (output khác như hook, "To origin", "Already up to date"...)
+func ParseProgress(line string) (Progress, bool) {
+	line = strings.TrimSpace(line)
+	line = strings.TrimSpace(strings.TrimPrefix(line, "remote:"))
+
+	var p Progress
+	for _, phase := range progressPhases {
+		if strings.HasPrefix(line, phase+":") {
+			p.Phase = phase
+			break
+		}
+	}
+	if p.Phase == "" {
+		return Progress{}, false
+	}
+	rest := strings.TrimSpace(strings.TrimPrefix(line, p.Phase+":"))
+
+	if m := progressPercentRe.FindStringSubmatch(rest); m != nil {
+		p.Percent, _ = strconv.Atoi(m[1])
+		p.Current, _ = strconv.Atoi(m[2])
+		p.Total, _ = strconv.Atoi(m[3])
+		rest = m[4]
+	} else if m := progressCountRe.FindStringSubmatch(rest); m != nil {
+		p.Percent = -1
+		p.Current, _ = strconv.Atoi(m[1])
+		rest = m[2]
+	} else {
+		return Progress{}, false
+	}
+
+	// Phần còn lại: ", 1.20 MiB | 2.00 MiB/s, done." hoặc ", done."
+	rest = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest), ","))
+	if strings.HasSuffix(rest, "done.") {
+		p.Done = true
+		rest = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(rest, "done."), ","))
+	}
+	// "(delta 3)" / "(reused 0)" ở cuối không có ý nghĩa hiển thị
+	if i := strings.Index(rest, "(delta"); i >= 0 {
+		rest = strings.TrimSpace(rest[:i])
+	}
+	p.Detail = strings.TrimSpace(strings.TrimSuffix(rest, ","))
+	return p, true
+}
+
+// String mô tả progress gọn cho status bar, e.g. "Receiving objects 450/1000 1.20 MiB | 2.00 MiB/s"
+func (p Progress) String() string {
+	s := p.Phase
+	switch {
+	case p.Total > 0:
+		s += " " + strconv.Itoa(p.Current) + "/" + strconv.Itoa(p.Total)
+	case p.Current > 0:
+		s += " " + strconv.Itoa(p.Current)
+	}
+	if p.Detail != "" {
+		s += " " + p.Detail
+	}
+	return s
+}
+
+// withProgress chèn --progress ngay sau subcommand để git luôn in progress
+// ra stderr, kể cả khi stderr không phải terminal
+func withProgress(args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+	out := make([]string, 0, len(args)+1)
+	out = append(out, args[0], "--progress")
+	return append(out, args[1:]...)
+}
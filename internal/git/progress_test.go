@@ -0,0 +1,86 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseProgress(t *testing.T) {
+	tests := []struct {
+		line string
+		want Progress
+		ok   bool
+	}{
+		{
+			line: "remote: Counting objects:  45% (9/20)",
+			want: Progress{Phase: "Counting objects", Percent: 45, Current: 9, Total: 20},
+			ok:   true,
+		},
+		{
+			line: "remote: Compressing objects: 100% (12/12), done.",
+			want: Progress{Phase: "Compressing objects", Percent: 100, Current: 12, Total: 12, Done: true},
+			ok:   true,
+		},
+		{
+			line: "Receiving objects:  45% (450/1000), 1.20 MiB | 2.00 MiB/s",
+			want: Progress{Phase: "Receiving objects", Percent: 45, Current: 450, Total: 1000, Detail: "1.20 MiB | 2.00 MiB/s"},
+			ok:   true,
+		},
+		{
+			line: "Receiving objects: 100% (1000/1000), 3.10 MiB | 2.50 MiB/s, done.",
+			want: Progress{Phase: "Receiving objects", Percent: 100, Current: 1000, Total: 1000, Detail: "3.10 MiB | 2.50 MiB/s", Done: true},
+			ok:   true,
+		},
+		{
+			line: "Resolving deltas:  10% (1/10)",
+			want: Progress{Phase: "Resolving deltas", Percent: 10, Current: 1, Total: 10},
+			ok:   true,
+		},
+		{
+			line: "Writing objects: 100% (3/3), 280 bytes | 280.00 KiB/s, done.",
+			want: Progress{Phase: "Writing objects", Percent: 100, Current: 3, Total: 3, Detail: "280 bytes | 280.00 KiB/s", Done: true},
+			ok:   true,
+		},
+		{
+			line: "remote: Enumerating objects: 5, done.",
+			want: Progress{Phase: "Enumerating objects", Percent: -1, Current: 5, Done: true},
+			ok:   true,
+		},
+		{line: "To github.com:user/repo.git", ok: false},
+		{line: "remote: Total 3 (delta 0), reused 0 (delta 0)", ok: false},
+		{line: "Counting objects: lots", ok: false},
+		{line: "", ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseProgress(tt.line)
+		if ok != tt.ok {
+			t.Errorf("ParseProgress(%q) ok = %v, want %v", tt.line, ok, tt.ok)
+			continue
+		}
+		if ok && got != tt.want {
+			t.Errorf("ParseProgress(%q) = %+v, want %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestProgressString(t *testing.T) {
+	p := Progress{Phase: "Receiving objects", Percent: 45, Current: 450, Total: 1000, Detail: "1.20 MiB | 2.00 MiB/s"}
+	if got, want := p.String(), "Receiving objects 450/1000 1.20 MiB | 2.00 MiB/s"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	p = Progress{Phase: "Enumerating objects", Percent: -1, Current: 5}
+	if got, want := p.String(), "Enumerating objects 5"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestWithProgress(t *testing.T) {
+	got := withProgress([]string{"push", "-u", "origin", "main"})
+	want := []string{"push", "--progress", "-u", "origin", "main"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("withProgress = %v, want %v", got, want)
+	}
+	if got := withProgress(nil); len(got) != 0 {
+		t.Errorf("withProgress(nil) = %v, want empty", got)
+	}
+}
@@ -0,0 +1,108 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PullArgs trả về args cho git pull theo strategy (merge, rebase, ff-only)
+func PullArgs(strategy string, autostash bool) []string {
+	args := []string{"pull"}
+	switch strategy {
+	case "merge":
+		args = append(args, "--no-rebase")
+	case "rebase":
+		args = append(args, "--rebase")
+	default:
+		args = append(args, "--ff-only")
+	}
+	if autostash {
+		args = append(args, "--autostash")
+	}
+	return args
+}
+
+// divergentMarkers là các dấu hiệu git từ chối pull vì branch đã phân nhánh
+var divergentMarkers = []string{
+	"divergent branches",
+	"not possible to fast-forward",
+	"need to specify how to reconcile",
+}
+
+// IsDivergentPull kiểm tra pull thất bại vì local và upstream đã phân nhánh
+func IsDivergentPull(err error) bool {
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		return false
+	}
+	lower := strings.ToLower(cmdErr.Stderr)
+	for _, marker := range divergentMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// PullPreview mô tả những gì một lần pull sẽ mang về
+type PullPreview struct {
+	Upstream string // e.g. origin/main
+	Incoming []CommitItem
+	Ahead    int // commit local chưa có trên upstream
+	Behind   int // commit upstream chưa có ở local
+	Dirty    bool
+}
+
+// Diverged cho biết local và upstream đều có commit riêng
+func (p PullPreview) Diverged() bool {
+	return p.Ahead > 0 && p.Behind > 0
+}
+
+// PreviewPull fetch upstream của branch hiện tại và liệt kê các commit sẽ được pull
+func (r Runner) PreviewPull(maxCommits int) (PullPreview, error) {
+	upstream, err := r.run(DefaultCmdTimeout, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{u}")
+	if err != nil {
+		return PullPreview{}, fmt.Errorf("no upstream configured for the current branch")
+	}
+	preview := PullPreview{Upstream: strings.TrimSpace(upstream)}
+
+	branch, err := r.CurrentBranch()
+	if err != nil {
+		return preview, err
+	}
+	if remote, mergeRef, err := r.BranchUpstreamRef(strings.TrimSpace(branch)); err == nil && remote != "." {
+		if _, err := r.runNetwork("fetch", remote, mergeRef+":refs/remotes/"+preview.Upstream); err != nil {
+			// Fallback: fetch toàn bộ remote (refspec khác chuẩn)
+			if _, err := r.runNetwork("fetch", remote); err != nil {
+				return preview, err
+			}
+		}
+	}
+
+	counts, err := r.run(DefaultCmdTimeout, "rev-list", "--count", "--left-right", "@{u}...HEAD")
+	if err != nil {
+		return preview, err
+	}
+	preview.Behind, preview.Ahead, err = ParseCommitCountOutput(counts)
+	if err != nil {
+		return preview, err
+	}
+
+	if preview.Behind > 0 {
+		out, err := r.run(DefaultCmdTimeout, "log", "--oneline", "-n", fmt.Sprintf("%d", maxCommits), "HEAD..@{u}")
+		if err != nil {
+			return preview, err
+		}
+		preview.Incoming = ParseLogOneline(out)
+	}
+
+	clean, err := r.IsWorkingDirectoryClean()
+	preview.Dirty = err == nil && !clean
+	return preview, nil
+}
+
+// StreamPull chạy git pull theo strategy với output được stream
+func (r Runner) StreamPull(strategy string, autostash bool) (*Stream, error) {
+	return r.StartNetworkStream(PullArgs(strategy, autostash)...)
+}
@@ -0,0 +1,47 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPullArgs(t *testing.T) {
+	tests := []struct {
+		strategy  string
+		autostash bool
+		want      []string
+	}{
+		{"merge", false, []string{"pull", "--no-rebase"}},
+		{"rebase", true, []string{"pull", "--rebase", "--autostash"}},
+		{"ff-only", false, []string{"pull", "--ff-only"}},
+		{"", false, []string{"pull", "--ff-only"}},
+	}
+	for _, tt := range tests {
+		if got := PullArgs(tt.strategy, tt.autostash); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("PullArgs(%q, %v) = %v, want %v", tt.strategy, tt.autostash, got, tt.want)
+		}
+	}
+}
+
+func TestIsDivergentPull(t *testing.T) {
+	err := &CommandError{Stderr: "hint: You have divergent branches and need to specify how to reconcile them.\nfatal: Need to specify how to reconcile divergent branches.\n"}
+	if !IsDivergentPull(err) {
+		t.Error("divergent branches error should be detected")
+	}
+	ffErr := &CommandError{Stderr: "fatal: Not possible to fast-forward, aborting.\n"}
+	if !IsDivergentPull(ffErr) {
+		t.Error("ff-only failure should be detected")
+	}
+	if IsDivergentPull(&CommandError{Stderr: "fatal: couldn't find remote ref main\n"}) {
+		t.Error("other pull errors should not be divergent")
+	}
+}
+
+func TestPullPreviewDiverged(t *testing.T) {
+	if (PullPreview{Ahead: 1, Behind: 0}).Diverged() {
+		t.Error("ahead only should not be diverged")
+	}
+	if !(PullPreview{Ahead: 2, Behind: 3}).Diverged() {
+		t.Error("ahead and behind should be diverged")
+	}
+}
@@ -0,0 +1,126 @@
+package git
+
+import (
+	"errors"
+	"strings"
+)
+
+// PushOptions mô tả một lần push từ push modal
+type PushOptions struct {
+	Remote       string
+	LocalBranch  string
+	RemoteBranch string // tên branch trên remote, mặc định = LocalBranch
+	SetUpstream  bool
+	Tags         bool
+	DryRun       bool
+	NoVerify     bool
+
+	// ForceWithLease bật --force-with-lease; LeaseHash là SHA remote mong đợi
+	// (rỗng = để git tự dùng remote-tracking ref)
+	ForceWithLease bool
+	LeaseHash      string
+}
+
+// PushArgs trả về args cho git push theo options
+func PushArgs(opts PushOptions) []string {
+	args := []string{"push"}
+	if opts.SetUpstream {
+		args = append(args, "-u")
+	}
+	if opts.ForceWithLease {
+		lease := "--force-with-lease"
+		if opts.LeaseHash != "" {
+			lease += "=" + opts.remoteBranch() + ":" + opts.LeaseHash
+		}
+		args = append(args, lease)
+	}
+	if opts.Tags {
+		args = append(args, "--follow-tags")
+	}
+	if opts.DryRun {
+		args = append(args, "--dry-run")
+	}
+	if opts.NoVerify {
+		args = append(args, "--no-verify")
+	}
+	if opts.Remote != "" {
+		args = append(args, opts.Remote)
+		if opts.LocalBranch != "" {
+			refspec := opts.LocalBranch
+			if rb := opts.remoteBranch(); rb != opts.LocalBranch {
+				refspec += ":" + rb
+			}
+			args = append(args, refspec)
+		}
+	}
+	return args
+}
+
+func (opts PushOptions) remoteBranch() string {
+	if opts.RemoteBranch != "" {
+		return opts.RemoteBranch
+	}
+	return opts.LocalBranch
+}
+
+// pushRejectedMarkers là các dấu hiệu push bị từ chối vì không fast-forward
+var pushRejectedMarkers = []string{
+	"non-fast-forward",
+	"[rejected]",
+	"fetch first",
+	"updates were rejected",
+}
+
+// IsPushRejected kiểm tra push bị từ chối vì remote đã có commit mới (cần force)
+func IsPushRejected(err error) bool {
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		return false
+	}
+	lower := strings.ToLower(cmdErr.Stderr)
+	if strings.Contains(lower, "stale info") {
+		// force-with-lease thất bại: không đề nghị force lần nữa
+		return false
+	}
+	for _, marker := range pushRejectedMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListRemotes trả về danh sách remote, "origin" đứng đầu nếu có
+func (r Runner) ListRemotes() ([]string, error) {
+	out, err := r.run(DefaultCmdTimeout, "remote")
+	if err != nil {
+		return nil, err
+	}
+	var remotes []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if line == "origin" {
+			remotes = append([]string{line}, remotes...)
+		} else {
+			remotes = append(remotes, line)
+		}
+	}
+	return remotes, nil
+}
+
+// RemoteTrackingHash trả về SHA của remote-tracking ref (refs/remotes/<remote>/<branch>)
+func (r Runner) RemoteTrackingHash(remote, branch string) (string, error) {
+	out, err := r.run(DefaultCmdTimeout, "rev-parse", "--verify", "-q", "refs/remotes/"+remote+"/"+branch)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// StreamPush chạy git push với output (pre-push hook, progress) được stream
+func (r Runner) StreamPush(opts PushOptions) (*Stream, error) {
+	return r.StartNetworkStream(PushArgs(opts)...)
+}
@@ -0,0 +1,67 @@
+package git
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPushArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		opts PushOptions
+		want []string
+	}{
+		{
+			name: "plain",
+			opts: PushOptions{Remote: "origin", LocalBranch: "main"},
+			want: []string{"push", "origin", "main"},
+		},
+		{
+			name: "set upstream with different remote branch",
+			opts: PushOptions{Remote: "fork", LocalBranch: "feat", RemoteBranch: "feature/x", SetUpstream: true},
+			want: []string{"push", "-u", "fork", "feat:feature/x"},
+		},
+		{
+			name: "force with lease and flags",
+			opts: PushOptions{Remote: "origin", LocalBranch: "main", ForceWithLease: true, LeaseHash: "abc123", Tags: true, DryRun: true, NoVerify: true},
+			want: []string{"push", "--force-with-lease=main:abc123", "--follow-tags", "--dry-run", "--no-verify", "origin", "main"},
+		},
+		{
+			name: "force with lease without expected hash",
+			opts: PushOptions{Remote: "origin", LocalBranch: "main", ForceWithLease: true},
+			want: []string{"push", "--force-with-lease", "origin", "main"},
+		},
+		{
+			name: "no remote",
+			opts: PushOptions{},
+			want: []string{"push"},
+		},
+	}
+	for _, tt := range tests {
+		if got := PushArgs(tt.opts); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: PushArgs = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsPushRejected(t *testing.T) {
+	rejected := &CommandError{Stderr: " ! [rejected]        main -> main (non-fast-forward)\nerror: failed to push some refs\n"}
+	if !IsPushRejected(rejected) {
+		t.Error("non-fast-forward push should be detected")
+	}
+
+	fetchFirst := &CommandError{Stderr: " ! [rejected]        main -> main (fetch first)\n"}
+	if !IsPushRejected(fetchFirst) {
+		t.Error("fetch first rejection should be detected")
+	}
+
+	stale := &CommandError{Stderr: " ! [rejected]        main -> main (stale info)\n"}
+	if IsPushRejected(stale) {
+		t.Error("failed force-with-lease should not offer another force push")
+	}
+
+	if IsPushRejected(errors.New("git push: timeout")) {
+		t.Error("plain errors should not be push rejections")
+	}
+}
@@ -0,0 +1,35 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRunnerWithContext(t *testing.T) {
+	runner := New(t.TempDir())
+	if runner.Context() == nil {
+		t.Fatal("Context() should default to context.Background()")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cancelled := runner.WithContext(ctx)
+
+	// WithContext trả về bản sao, runner gốc không bị ảnh hưởng
+	if runner.Context().Err() != nil {
+		t.Error("WithContext should not modify the original runner")
+	}
+
+	_, err := cancelled.run(DefaultCmdTimeout, "version")
+	if !errors.Is(err, ErrCancelled) {
+		t.Errorf("run with cancelled context: got %v, want ErrCancelled", err)
+	}
+
+	s, err := cancelled.StartStream("version")
+	if err == nil {
+		if _, err = s.Drain(); !errors.Is(err, ErrCancelled) {
+			t.Errorf("stream with cancelled context: got %v, want ErrCancelled", err)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package git
+
+import "strings"
+
+// SideKind là loại một ô trong hiển thị side-by-side
+type SideKind int
+
+const (
+	SideEmpty   SideKind = iota // ô trống (bên kia có dòng, bên này không)
+	SideContext                 // dòng không đổi, có ở cả hai bên
+	SideRemoved                 // dòng bị xoá (cột old)
+	SideAdded                   // dòng được thêm (cột new)
+)
+
+// SideLine là một ô của hàng side-by-side
+type SideLine struct {
+	Kind SideKind
+	Num  int    // số dòng trong file, 0 khi ô trống
+	Text string // nội dung không có tiền tố +/-/space
+}
+
+// SideBySideRow là một hàng gồm ô old (trái) và new (phải)
+type SideBySideRow struct {
+	Old, New SideLine
+}
+
+// AlignHunk ghép dòng của hunk thành các hàng old/new: context nằm cùng hàng ở
+// hai bên, khối "-" liền nhau được ghép theo thứ tự với khối "+" ngay sau nó,
+// phần dư bên ngắn hơn để trống. Số dòng tính từ OldStart/NewStart.
+func AlignHunk(h Hunk) []SideBySideRow {
+	var rows []SideBySideRow
+	var removed, added []SideLine
+	oldNum, newNum := h.OldStart, h.NewStart
+
+	flush := func() {
+		n := len(removed)
+		if len(added) > n {
+			n = len(added)
+		}
+		for i := 0; i < n; i++ {
+			var row SideBySideRow
+			if i < len(removed) {
+				row.Old = removed[i]
+			}
+			if i < len(added) {
+				row.New = added[i]
+			}
+			rows = append(rows, row)
+		}
+		removed, added = nil, nil
+	}
+
+	lines := strings.Split(h.Content, "\n")
+	for i, line := range lines {
+		if i == 0 && strings.HasPrefix(line, "@@") {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "-"):
+			removed = append(removed, SideLine{Kind: SideRemoved, Num: oldNum, Text: line[1:]})
+			oldNum++
+		case strings.HasPrefix(line, "+"):
+			added = append(added, SideLine{Kind: SideAdded, Num: newNum, Text: line[1:]})
+			newNum++
+		case strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file" không phải dòng của file
+		case line == "" && i == len(lines)-1:
+			// dòng trống cuối do Split
+		default:
+			flush()
+			text := strings.TrimPrefix(line, " ")
+			rows = append(rows, SideBySideRow{
+				Old: SideLine{Kind: SideContext, Num: oldNum, Text: text},
+				New: SideLine{Kind: SideContext, Num: newNum, Text: text},
+			})
+			oldNum++
+			newNum++
+		}
+	}
+	flush()
+	return rows
+}
+
+// IsUnifiedHunkLine kiểm tra dòng có hợp lệ trong hunk unified không
+// (output --word-diff không có tiền tố nên không ghép side-by-side được)
+func IsUnifiedHunkLine(line string) bool {
+	if line == "" {
+		return true
+	}
+	switch line[0] {
+	case ' ', '+', '-', '\\':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAlignHunk(t *testing.T) {
+	h := parseHunk(0, "@@ -10,5 +10,6 @@ func x\n ctx\n-old1\n-old2\n+new1\n+new2\n+new3\n ctx2\n-gone\n\\ No newline at end of file\n")
+
+	rows := AlignHunk(h)
+	want := []SideBySideRow{
+		{Old: SideLine{SideContext, 10, "ctx"}, New: SideLine{SideContext, 10, "ctx"}},
+		{Old: SideLine{SideRemoved, 11, "old1"}, New: SideLine{SideAdded, 11, "new1"}},
+		{Old: SideLine{SideRemoved, 12, "old2"}, New: SideLine{SideAdded, 12, "new2"}},
+		{Old: SideLine{}, New: SideLine{SideAdded, 13, "new3"}},
+		{Old: SideLine{SideContext, 13, "ctx2"}, New: SideLine{SideContext, 14, "ctx2"}},
+		{Old: SideLine{SideRemoved, 14, "gone"}, New: SideLine{}},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("AlignHunk() =\n%+v\nwant\n%+v", rows, want)
+	}
+}
+
+func TestIsUnifiedHunkLine(t *testing.T) {
+	for _, line := range []string{"", " ctx", "+add", "-del", `\ No newline at end of file`} {
+		if !IsUnifiedHunkLine(line) {
+			t.Errorf("IsUnifiedHunkLine(%q) = false, want true", line)
+		}
+	}
+	if IsUnifiedHunkLine("[-old-]{+new+}") {
+		t.Error("word-diff line should not be a unified hunk line")
+	}
+}
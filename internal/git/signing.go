@@ -0,0 +1,156 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SignatureStatus là kết quả verify chữ ký commit, rút gọn từ %G?
+type SignatureStatus string
+
+const (
+	SignatureNone    SignatureStatus = "none"
+	SignatureGood    SignatureStatus = "good"
+	SignatureBad     SignatureStatus = "bad"
+	SignatureUnknown SignatureStatus = "unknown"
+)
+
+// ParseSignatureStatus chuyển mã %G? sang SignatureStatus.
+// G = good; B/R = bad hoặc key bị revoke; U/X/Y/E = không xác minh được trust/key; N = không ký.
+func ParseSignatureStatus(code string) SignatureStatus {
+	switch strings.TrimSpace(code) {
+	case "G":
+		return SignatureGood
+	case "B", "R":
+		return SignatureBad
+	case "U", "X", "Y", "E":
+		return SignatureUnknown
+	default:
+		return SignatureNone
+	}
+}
+
+// ParseLogSignatures parse output của `git log --format=%h%x09%G?` thành map hash → status
+func ParseLogSignatures(out string) map[string]SignatureStatus {
+	sigs := make(map[string]SignatureStatus)
+	for _, line := range strings.Split(strings.ReplaceAll(out, "\r\n", "\n"), "\n") {
+		hash, code, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok || hash == "" {
+			continue
+		}
+		sigs[hash] = ParseSignatureStatus(code)
+	}
+	return sigs
+}
+
+// SignatureInfo chứa chi tiết chữ ký của một commit
+type SignatureInfo struct {
+	Status      SignatureStatus
+	Code        string // mã %G? gốc
+	Signer      string // %GS
+	Key         string // %GK
+	Fingerprint string // %GF
+	Trust       string // %GT
+}
+
+// ParseSignatureInfo parse output của `git log -1 --format=%G?%x00%GS%x00%GK%x00%GF%x00%GT`
+func ParseSignatureInfo(out string) SignatureInfo {
+	fields := strings.Split(strings.TrimRight(out, "\r\n"), "\x00")
+	for len(fields) < 5 {
+		fields = append(fields, "")
+	}
+	return SignatureInfo{
+		Status:      ParseSignatureStatus(fields[0]),
+		Code:        strings.TrimSpace(fields[0]),
+		Signer:      strings.TrimSpace(fields[1]),
+		Key:         strings.TrimSpace(fields[2]),
+		Fingerprint: strings.TrimSpace(fields[3]),
+		Trust:       strings.TrimSpace(fields[4]),
+	}
+}
+
+// Summary mô tả ngắn gọn chữ ký để hiển thị trong commit view
+func (s SignatureInfo) Summary() string {
+	if s.Status == SignatureNone {
+		return "not signed"
+	}
+	parts := []string{string(s.Status) + " (" + s.Code + ")"}
+	if s.Signer != "" {
+		parts = append(parts, "by "+s.Signer)
+	}
+	if s.Key != "" {
+		parts = append(parts, "key "+s.Key)
+	}
+	if s.Trust != "" && s.Trust != "undefined" {
+		parts = append(parts, "trust "+s.Trust)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SigningConfig là cấu hình ký commit của repo (commit.gpgsign, gpg.format, user.signingkey)
+type SigningConfig struct {
+	Enabled bool
+	Format  string // openpgp, ssh, x509
+	Key     string
+}
+
+// SigningConfig đọc cấu hình ký commit hiện tại (git tự áp dụng cho commit/amend/rebase)
+func (r Runner) SigningConfig() SigningConfig {
+	cfg := SigningConfig{Format: "openpgp"}
+	if out, err := r.run(DefaultCmdTimeout, "config", "--type=bool", "--get", "commit.gpgsign"); err == nil {
+		cfg.Enabled = strings.TrimSpace(out) == "true"
+	}
+	if out, err := r.run(DefaultCmdTimeout, "config", "--get", "gpg.format"); err == nil && strings.TrimSpace(out) != "" {
+		cfg.Format = strings.TrimSpace(out)
+	}
+	if out, err := r.run(DefaultCmdTimeout, "config", "--get", "user.signingkey"); err == nil {
+		cfg.Key = strings.TrimSpace(out)
+	}
+	return cfg
+}
+
+// LogSignatures trả về trạng thái chữ ký của các commit trong Commits pane
+func (r Runner) LogSignatures(n int) (map[string]SignatureStatus, error) {
+	out, err := r.run(DefaultDiffTimeout, "log", "-n", fmt.Sprintf("%d", n), "--format=%h%x09%G?")
+	if err != nil {
+		return nil, err
+	}
+	return ParseLogSignatures(out), nil
+}
+
+// CommitSignature trả về chi tiết chữ ký của commit
+func (r Runner) CommitSignature(hash string) (SignatureInfo, error) {
+	out, err := r.run(DefaultDiffTimeout, "log", "-1", "--format=%G?%x00%GS%x00%GK%x00%GF%x00%GT", hash)
+	if err != nil {
+		return SignatureInfo{}, err
+	}
+	return ParseSignatureInfo(out), nil
+}
+
+// signingFailureMarkers là các dấu hiệu trong stderr khi git không ký được commit
+var signingFailureMarkers = []string{
+	"gpg failed to sign",
+	"failed to sign the data",
+	"failed to write commit object",
+	"couldn't load public key",
+	"ssh-keygen",
+	"signing failed",
+	"gpgsm",
+	"error: cannot run gpg",
+}
+
+// SigningFailure trả về stderr đầy đủ nếu err là lỗi ký commit
+func SigningFailure(err error) (string, bool) {
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		return "", false
+	}
+	lower := strings.ToLower(cmdErr.Stderr)
+	for _, marker := range signingFailureMarkers {
+		if strings.Contains(lower, marker) {
+			return strings.TrimSpace(cmdErr.Stderr), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,64 @@
+package git
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSignatureStatus(t *testing.T) {
+	tests := map[string]SignatureStatus{
+		"G": SignatureGood,
+		"B": SignatureBad,
+		"R": SignatureBad,
+		"U": SignatureUnknown,
+		"E": SignatureUnknown,
+		"N": SignatureNone,
+		"":  SignatureNone,
+	}
+	for code, want := range tests {
+		if got := ParseSignatureStatus(code); got != want {
+			t.Errorf("ParseSignatureStatus(%q) = %q, want %q", code, got, want)
+		}
+	}
+}
+
+func TestParseLogSignatures(t *testing.T) {
+	out := "abc1234\tG\r\ndef5678\tN\n\n9999999\tE\n"
+	sigs := ParseLogSignatures(out)
+	if len(sigs) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(sigs))
+	}
+	if sigs["abc1234"] != SignatureGood || sigs["def5678"] != SignatureNone || sigs["9999999"] != SignatureUnknown {
+		t.Errorf("unexpected signatures: %v", sigs)
+	}
+}
+
+func TestParseSignatureInfo(t *testing.T) {
+	info := ParseSignatureInfo("G\x00Dev <dev@example.com>\x00ABCDEF\x00FINGERPRINT\x00ultimate\n")
+	if info.Status != SignatureGood || info.Signer != "Dev <dev@example.com>" || info.Key != "ABCDEF" || info.Trust != "ultimate" {
+		t.Errorf("unexpected info: %+v", info)
+	}
+	if got := info.Summary(); got != "good (G), by Dev <dev@example.com>, key ABCDEF, trust ultimate" {
+		t.Errorf("Summary() = %q", got)
+	}
+
+	if got := ParseSignatureInfo("N\x00\x00\x00\x00\n").Summary(); got != "not signed" {
+		t.Errorf("unsigned Summary() = %q", got)
+	}
+}
+
+func TestSigningFailure(t *testing.T) {
+	err := &CommandError{
+		Args:   []string{"commit", "-m", "x"},
+		Stderr: "error: gpg failed to sign the data\nfatal: failed to write commit object\n",
+		Text:   "error: gpg failed to sign the data\nfatal: failed to write commit object",
+	}
+	stderr, ok := SigningFailure(err)
+	if !ok || stderr != "error: gpg failed to sign the data\nfatal: failed to write commit object" {
+		t.Errorf("SigningFailure = %q, %v", stderr, ok)
+	}
+
+	if _, ok := SigningFailure(errors.New("git commit: timeout")); ok {
+		t.Error("plain errors should not be signing failures")
+	}
+}
@@ -0,0 +1,234 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// RepoStateKind là thao tác nhiều bước đang dừng giữa chừng trong repository
+type RepoStateKind int
+
+const (
+	StateNone RepoStateKind = iota
+	StateMerging
+	StateRebasing
+	StateCherryPicking
+	StateReverting
+	StateBisecting
+	StateApplyingMailbox // git am
+)
+
+// String trả về nhãn hiển thị trên banner
+func (k RepoStateKind) String() string {
+	switch k {
+	case StateMerging:
+		return "MERGING"
+	case StateRebasing:
+		return "REBASING"
+	case StateCherryPicking:
+		return "CHERRY-PICKING"
+	case StateReverting:
+		return "REVERTING"
+	case StateBisecting:
+		return "BISECTING"
+	case StateApplyingMailbox:
+		return "APPLYING PATCHES"
+	default:
+		return ""
+	}
+}
+
+// RepoState là trạng thái đọc từ các state file trong git dir
+type RepoState struct {
+	Kind  RepoStateKind
+	Step  int    // bước hiện tại (1-based), 0 nếu không biết
+	Total int    // tổng số bước, 0 nếu không biết
+	Head  string // branch đang được rebase (rỗng nếu không có)
+}
+
+// InProgress kiểm tra repository có đang giữa một thao tác nhiều bước không
+func (s RepoState) InProgress() bool {
+	return s.Kind != StateNone
+}
+
+// String mô tả trạng thái, e.g. "REBASING feature 3/7"
+func (s RepoState) String() string {
+	if !s.InProgress() {
+		return ""
+	}
+	out := s.Kind.String()
+	if s.Head != "" {
+		out += " " + s.Head
+	}
+	if s.Total > 0 {
+		out += fmt.Sprintf(" %d/%d", s.Step, s.Total)
+	}
+	return out
+}
+
+// CanSkip kiểm tra thao tác hiện tại có hỗ trợ --skip không
+func (s RepoState) CanSkip() bool {
+	return len(SkipArgs(s.Kind)) > 0
+}
+
+// CanContinue kiểm tra thao tác hiện tại có hỗ trợ --continue không
+func (s RepoState) CanContinue() bool {
+	return len(ContinueArgs(s.Kind)) > 0
+}
+
+// DetectRepoState đọc state file trong gitDir (MERGE_HEAD, rebase-merge/, ...)
+func DetectRepoState(gitDir string) RepoState {
+	// rebase-merge/: rebase -i và merge backend (mặc định)
+	if dir := filepath.Join(gitDir, "rebase-merge"); isDir(dir) {
+		return RepoState{
+			Kind:  StateRebasing,
+			Step:  readIntFile(filepath.Join(dir, "msgnum")),
+			Total: readIntFile(filepath.Join(dir, "end")),
+			Head:  shortRefName(readTrimmedFile(filepath.Join(dir, "head-name"))),
+		}
+	}
+
+	// rebase-apply/: git am (có file "applying") hoặc rebase apply backend
+	if dir := filepath.Join(gitDir, "rebase-apply"); isDir(dir) {
+		state := RepoState{
+			Kind:  StateRebasing,
+			Step:  readIntFile(filepath.Join(dir, "next")),
+			Total: readIntFile(filepath.Join(dir, "last")),
+			Head:  shortRefName(readTrimmedFile(filepath.Join(dir, "head-name"))),
+		}
+		if fileExists(filepath.Join(dir, "applying")) {
+			state.Kind = StateApplyingMailbox
+			state.Head = ""
+		}
+		return state
+	}
+
+	switch {
+	case fileExists(filepath.Join(gitDir, "MERGE_HEAD")):
+		return RepoState{Kind: StateMerging}
+	case fileExists(filepath.Join(gitDir, "CHERRY_PICK_HEAD")):
+		return RepoState{Kind: StateCherryPicking}
+	case fileExists(filepath.Join(gitDir, "REVERT_HEAD")):
+		return RepoState{Kind: StateReverting}
+	case fileExists(filepath.Join(gitDir, "BISECT_LOG")):
+		return RepoState{Kind: StateBisecting}
+	}
+	return RepoState{}
+}
+
+// GitDir trả về đường dẫn tuyệt đối của git dir (.git, hoặc thư mục worktree)
+func (r Runner) GitDir() (string, error) {
+	out, err := r.run(DefaultCmdTimeout, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve git dir: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// RepoState phát hiện merge/rebase/cherry-pick/revert/bisect/am đang dở dang
+func (r Runner) RepoState() (RepoState, error) {
+	gitDir, err := r.GitDir()
+	if err != nil {
+		return RepoState{}, err
+	}
+	return DetectRepoState(gitDir), nil
+}
+
+// ContinueArgs trả về args để tiếp tục thao tác, nil nếu không hỗ trợ.
+// core.editor=true giữ message mặc định thay vì mở editor bên trong TUI.
+func ContinueArgs(kind RepoStateKind) []string {
+	switch kind {
+	case StateMerging:
+		return []string{"-c", "core.editor=true", "merge", "--continue"}
+	case StateRebasing:
+		return []string{"-c", "core.editor=true", "rebase", "--continue"}
+	case StateCherryPicking:
+		return []string{"-c", "core.editor=true", "cherry-pick", "--continue"}
+	case StateReverting:
+		return []string{"-c", "core.editor=true", "revert", "--continue"}
+	case StateApplyingMailbox:
+		return []string{"am", "--continue"}
+	}
+	return nil
+}
+
+// AbortArgs trả về args để huỷ thao tác và quay về trạng thái trước đó
+func AbortArgs(kind RepoStateKind) []string {
+	switch kind {
+	case StateMerging:
+		return []string{"merge", "--abort"}
+	case StateRebasing:
+		return []string{"rebase", "--abort"}
+	case StateCherryPicking:
+		return []string{"cherry-pick", "--abort"}
+	case StateReverting:
+		return []string{"revert", "--abort"}
+	case StateBisecting:
+		return []string{"bisect", "reset"}
+	case StateApplyingMailbox:
+		return []string{"am", "--abort"}
+	}
+	return nil
+}
+
+// SkipArgs trả về args để bỏ qua bước hiện tại, nil nếu không hỗ trợ (merge)
+func SkipArgs(kind RepoStateKind) []string {
+	switch kind {
+	case StateRebasing:
+		return []string{"rebase", "--skip"}
+	case StateCherryPicking:
+		return []string{"cherry-pick", "--skip"}
+	case StateReverting:
+		return []string{"revert", "--skip"}
+	case StateBisecting:
+		return []string{"bisect", "skip"}
+	case StateApplyingMailbox:
+		return []string{"am", "--skip"}
+	}
+	return nil
+}
+
+// StreamRepoStateAction chạy continue/abort/skip với output (hook, conflict) được stream
+func (r Runner) StreamRepoStateAction(args []string) (*Stream, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("no git command for this repository state")
+	}
+	return r.StartStream(args...)
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func readTrimmedFile(path string) string {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+func readIntFile(path string) int {
+	n, err := strconv.Atoi(readTrimmedFile(path))
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// shortRefName bỏ tiền tố refs/heads/ ("detached HEAD" khi rebase từ detached)
+func shortRefName(ref string) string {
+	if ref == "detached HEAD" {
+		return ""
+	}
+	return strings.TrimPrefix(ref, "refs/heads/")
+}
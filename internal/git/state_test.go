@@ -0,0 +1,104 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeStateFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDetectRepoState(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  RepoState
+	}{
+		{"clean", nil, RepoState{}},
+		{
+			"interactive rebase",
+			map[string]string{
+				"rebase-merge/msgnum":    "3\n",
+				"rebase-merge/end":       "7\n",
+				"rebase-merge/head-name": "refs/heads/feature\n",
+			},
+			RepoState{Kind: StateRebasing, Step: 3, Total: 7, Head: "feature"},
+		},
+		{
+			"apply rebase",
+			map[string]string{
+				"rebase-apply/next":      "2",
+				"rebase-apply/last":      "4",
+				"rebase-apply/head-name": "detached HEAD",
+			},
+			RepoState{Kind: StateRebasing, Step: 2, Total: 4},
+		},
+		{
+			"git am",
+			map[string]string{
+				"rebase-apply/next":     "1",
+				"rebase-apply/last":     "5",
+				"rebase-apply/applying": "",
+			},
+			RepoState{Kind: StateApplyingMailbox, Step: 1, Total: 5},
+		},
+		{"merge", map[string]string{"MERGE_HEAD": "abc"}, RepoState{Kind: StateMerging}},
+		{"cherry-pick", map[string]string{"CHERRY_PICK_HEAD": "abc"}, RepoState{Kind: StateCherryPicking}},
+		{"revert", map[string]string{"REVERT_HEAD": "abc"}, RepoState{Kind: StateReverting}},
+		{"bisect", map[string]string{"BISECT_LOG": "git bisect start"}, RepoState{Kind: StateBisecting}},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		for name, content := range tt.files {
+			writeStateFile(t, filepath.Join(dir, name), content)
+		}
+		if got := DetectRepoState(dir); got != tt.want {
+			t.Errorf("%s: DetectRepoState = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRepoStateString(t *testing.T) {
+	tests := []struct {
+		state RepoState
+		want  string
+	}{
+		{RepoState{}, ""},
+		{RepoState{Kind: StateRebasing, Step: 3, Total: 7, Head: "feature"}, "REBASING feature 3/7"},
+		{RepoState{Kind: StateMerging}, "MERGING"},
+		{RepoState{Kind: StateApplyingMailbox, Step: 1, Total: 2}, "APPLYING PATCHES 1/2"},
+	}
+	for _, tt := range tests {
+		if got := tt.state.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestRepoStateActions(t *testing.T) {
+	if (RepoState{Kind: StateMerging}).CanSkip() {
+		t.Error("merge should not support skip")
+	}
+	if (RepoState{Kind: StateBisecting}).CanContinue() {
+		t.Error("bisect should not support continue")
+	}
+	if got := AbortArgs(StateBisecting); len(got) != 2 || got[1] != "reset" {
+		t.Errorf("AbortArgs(bisect) = %v, want [bisect reset]", got)
+	}
+	for _, kind := range []RepoStateKind{StateMerging, StateRebasing, StateCherryPicking, StateReverting, StateBisecting, StateApplyingMailbox} {
+		if len(AbortArgs(kind)) == 0 {
+			t.Errorf("AbortArgs(%v) should not be empty", kind)
+		}
+	}
+	if ContinueArgs(StateNone) != nil || AbortArgs(StateNone) != nil || SkipArgs(StateNone) != nil {
+		t.Error("StateNone should have no actions")
+	}
+}
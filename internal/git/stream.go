@@ -0,0 +1,258 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCancelled được trả về khi người dùng huỷ lệnh đang chạy
+var ErrCancelled = errors.New("cancelled")
+
+// ErrIdleTimeout được trả về khi lệnh không ghi output nào trong khoảng idle timeout
+var ErrIdleTimeout = errors.New("no output")
+
+// Stream là một lệnh git chạy nền không có timeout cố định, stdout/stderr
+// được đẩy từng dòng qua Lines() (e.g. output của pre-commit hook).
+type Stream struct {
+	Args []string
+
+	cmd    *exec.Cmd
+	cancel context.CancelFunc
+	lines  chan string
+	done   chan struct{}
+
+	// idle > 0 thì lệnh bị huỷ khi không có output trong khoảng này
+	idle     time.Duration
+	activity chan struct{}
+
+	mu        sync.Mutex
+	stdout    bytes.Buffer
+	stderr    bytes.Buffer
+	cancelled bool
+	timedOut  bool
+	err       error
+}
+
+// StartStream khởi chạy lệnh git và bắt đầu stream output
+func (r Runner) StartStream(args ...string) (*Stream, error) {
+	return r.startStream(0, args...)
+}
+
+// StartNetworkStream chạy lệnh mạng (fetch/push/pull) với --progress, không có
+// timeout cố định. Lệnh bị huỷ khi không có dòng output nào (progress, output của
+// hook pre-push/post-merge) trong idle timeout, xem WithNetworkIdleTimeout.
+func (r Runner) StartNetworkStream(args ...string) (*Stream, error) {
+	return r.startStream(r.networkIdleTimeout(), withProgress(args)...)
+}
+
+// networkIdleTimeout trả về idle timeout cho lệnh mạng, 0 = không giới hạn
+func (r Runner) networkIdleTimeout() time.Duration {
+	switch {
+	case r.networkIdle < 0:
+		return 0
+	case r.networkIdle == 0:
+		return NetworkIdleTimeout
+	}
+	return r.networkIdle
+}
+
+func (r Runner) startStream(idle time.Duration, args ...string) (*Stream, error) {
+	parent := r.Context()
+	ctx, cancel := context.WithCancel(parent)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	if r.RepoRoot != "" {
+		cmd.Dir = r.RepoRoot
+	}
+	configureProcessGroup(cmd)
+
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	stderrPipe, err := cmd.StderrPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	s := &Stream{
+		Args:     args,
+		cmd:      cmd,
+		cancel:   cancel,
+		lines:    make(chan string, 256),
+		done:     make(chan struct{}),
+		idle:     idle,
+		activity: make(chan struct{}, 1),
+	}
+
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+
+	var readers sync.WaitGroup
+	readers.Add(2)
+	go s.pump(stdoutPipe, &s.stdout, &readers)
+	go s.pump(stderrPipe, &s.stderr, &readers)
+	if idle > 0 {
+		go s.watchIdle()
+	}
+
+	go func() {
+		readers.Wait()
+		close(s.lines)
+		waitErr := cmd.Wait()
+		cancel()
+
+		s.mu.Lock()
+		switch {
+		case s.timedOut:
+			s.err = fmt.Errorf("git %s: %w for %s, aborted", strings.Join(args, " "), ErrIdleTimeout, s.idle)
+		case s.cancelled || parent.Err() != nil:
+			s.err = fmt.Errorf("git %s: %w", strings.Join(args, " "), ErrCancelled)
+		case waitErr != nil:
+			text := strings.TrimSpace(s.stderr.String())
+			if text == "" {
+				text = strings.TrimSpace(s.stdout.String())
+			}
+			if text == "" {
+				text = waitErr.Error()
+			}
+			s.err = &CommandError{Args: args, Stderr: s.stderr.String(), Text: text}
+		}
+		s.mu.Unlock()
+		close(s.done)
+	}()
+
+	return s, nil
+}
+
+// pump đọc output theo dòng (tách cả \r cho progress), lưu lại và đẩy sang channel
+func (s *Stream) pump(r io.Reader, buf *bytes.Buffer, wg *sync.WaitGroup) {
+	defer wg.Done()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	scanner.Split(scanLinesCR)
+	for scanner.Scan() {
+		line := scanner.Text()
+		s.mu.Lock()
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+		s.mu.Unlock()
+		select {
+		case s.activity <- struct{}{}:
+		default:
+		}
+		if strings.TrimSpace(line) != "" {
+			s.lines <- line
+		}
+	}
+}
+
+// watchIdle huỷ lệnh khi không có dòng output nào trong s.idle
+func (s *Stream) watchIdle() {
+	timer := time.NewTimer(s.idle)
+	defer timer.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.activity:
+			timer.Reset(s.idle)
+		case <-timer.C:
+			s.mu.Lock()
+			s.timedOut = true
+			s.mu.Unlock()
+			s.cancel()
+			return
+		}
+	}
+}
+
+// Drain bỏ qua output còn lại và chờ lệnh kết thúc, dùng khi không cần hiển thị từng dòng
+func (s *Stream) Drain() (string, error) {
+	for range s.lines {
+	}
+	return s.Wait()
+}
+
+// scanLinesCR giống bufio.ScanLines nhưng coi \r cũng là kết thúc dòng
+func scanLinesCR(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// Lines trả về channel output, đóng khi process kết thúc
+func (s *Stream) Lines() <-chan string {
+	return s.lines
+}
+
+// Wait chờ lệnh kết thúc, trả về stdout và lỗi (CommandError giữ nguyên stderr)
+func (s *Stream) Wait() (string, error) {
+	<-s.done
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stdout.String(), s.err
+}
+
+// Cancel dừng lệnh đang chạy
+func (s *Stream) Cancel() {
+	s.mu.Lock()
+	s.cancelled = true
+	s.mu.Unlock()
+	s.cancel()
+}
+
+// String mô tả lệnh, dùng cho cmd log
+func (s *Stream) String() string {
+	return "git " + strings.Join(s.Args, " ")
+}
+
+// CommitArgs trả về args cho git commit, noVerify bỏ qua pre-commit/commit-msg hook
+func CommitArgs(message string, amend, noVerify bool) []string {
+	args := []string{"commit"}
+	if amend {
+		args = append(args, "--amend")
+		if message == "" {
+			args = append(args, "--no-edit")
+		}
+	}
+	if message != "" {
+		args = append(args, "-m", message)
+	}
+	if noVerify {
+		args = append(args, "--no-verify")
+	}
+	return args
+}
+
+// StreamCommit commit (hoặc amend) với output hook được stream
+func (r Runner) StreamCommit(message string, amend, noVerify bool) (*Stream, error) {
+	return r.StartStream(CommitArgs(message, amend, noVerify)...)
+}
+
+// StreamCommitFixup tạo fixup! commit với output hook được stream
+func (r Runner) StreamCommitFixup(target string, noVerify bool) (*Stream, error) {
+	args := []string{"commit", "--fixup=" + target}
+	if noVerify {
+		args = append(args, "--no-verify")
+	}
+	return r.StartStream(args...)
+}
@@ -0,0 +1,85 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommitArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		amend    bool
+		noVerify bool
+		want     []string
+	}{
+		{"commit", "msg", false, false, []string{"commit", "-m", "msg"}},
+		{"commit no-verify", "msg", false, true, []string{"commit", "-m", "msg", "--no-verify"}},
+		{"amend keep message", "", true, false, []string{"commit", "--amend", "--no-edit"}},
+		{"amend new message", "msg", true, true, []string{"commit", "--amend", "-m", "msg", "--no-verify"}},
+	}
+	for _, tt := range tests {
+		if got := CommitArgs(tt.message, tt.amend, tt.noVerify); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: CommitArgs = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestScanLinesCR(t *testing.T) {
+	in := "Counting objects: 10%\rCounting objects: 100%, done.\nline two\r\nlast"
+	scanner := bufio.NewScanner(strings.NewReader(in))
+	scanner.Split(scanLinesCR)
+
+	var got []string
+	for scanner.Scan() {
+		got = append(got, scanner.Text())
+	}
+	want := []string{"Counting objects: 10%", "Counting objects: 100%, done.", "line two", "", "last"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("scanLinesCR = %q, want %q", got, want)
+	}
+}
+
+// drainStream chờ stream vừa khởi chạy kết thúc
+func drainStream(s *Stream, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	return s.Drain()
+}
+
+func TestNetworkIdleTimeout(t *testing.T) {
+	r := New(t.TempDir())
+	tests := []struct {
+		runner Runner
+		want   time.Duration
+	}{
+		{r, NetworkIdleTimeout},
+		{r.WithNetworkIdleTimeout(5 * time.Minute), 5 * time.Minute},
+		// tắt hẳn chỉ khi cấu hình rõ ràng
+		{r.WithNetworkIdleTimeout(-1), 0},
+		{r.WithNetworkIdleTimeout(0), 0},
+	}
+	for i, tt := range tests {
+		if got := tt.runner.networkIdleTimeout(); got != tt.want {
+			t.Errorf("%d: networkIdleTimeout = %v, want %v", i, got, tt.want)
+		}
+	}
+}
+
+// output của hook (không phải progress) cũng giữ cho lệnh không bị huỷ vì idle
+func TestStreamIdleTimeoutResetsOnOutput(t *testing.T) {
+	r := initTestRepo(t, nil)
+	chatty := "alias.slow=!echo a; sleep 0.3; echo b; sleep 0.3; echo c"
+	if _, err := drainStream(r.startStream(time.Second/2, "-c", chatty, "slow")); err != nil {
+		t.Errorf("stream with regular output: %v", err)
+	}
+	silent := "alias.slow=!echo a; sleep 2"
+	if _, err := drainStream(r.startStream(time.Second/2, "-c", silent, "slow")); !errors.Is(err, ErrIdleTimeout) {
+		t.Errorf("silent stream: got %v, want ErrIdleTimeout", err)
+	}
+}
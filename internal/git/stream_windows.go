@@ -0,0 +1,8 @@
+//go:build windows
+
+package git
+
+import "os/exec"
+
+// configureProcessGroup: trên Windows chỉ kill process git (mặc định của CommandContext)
+func configureProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,238 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// HeadState là snapshot vị trí HEAD, dùng cho undo/redo
+type HeadState struct {
+	Branch string // rỗng khi HEAD detached
+	Hash   string
+}
+
+// ReflogAction là kết quả diễn giải reflog thành một thao tác có thể undo
+type ReflogAction struct {
+	Kind        string // commit, amend, reset, checkout, merge, rebase, pull, cherry-pick, revert
+	Description string
+	FromHash    string // HEAD trước thao tác
+	FromBranch  string // branch trước thao tác (chỉ xác định được với checkout)
+	Detached    bool   // HEAD detached trước thao tác (checkout từ một commit)
+	ToHash      string // HEAD sau thao tác
+
+	// UndoReflogAction là GIT_REFLOG_ACTION cho lệnh undo thao tác này, để lần undo
+	// sau bỏ qua nó
+	UndoReflogAction string
+}
+
+var fullHashRE = regexp.MustCompile(`^[0-9a-f]{40}([0-9a-f]{24})?$`)
+
+// reflogUndoPrefix đánh dấu entry reflog do undo từ reflog tạo ra: "gitzen undo N" nghĩa là
+// N thao tác mới nhất (không tính các entry undo) đã được undo
+const reflogUndoPrefix = "gitzen undo "
+
+// reflogUndoCount trả về N nếu entry do undo từ reflog tạo ra. Checkout ghi nguyên
+// GIT_REFLOG_ACTION làm message, reset ghi "<action>: updating HEAD".
+func reflogUndoCount(e ReflogEntry) (int, bool) {
+	text := e.Action
+	if text == "" {
+		text = e.Message
+	}
+	if !strings.HasPrefix(text, reflogUndoPrefix) {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(text, reflogUndoPrefix))
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// InterpretReflog diễn giải các entry reflog của HEAD (mới nhất trước) thành thao tác cần undo.
+// Các thao tác đã được undo trước đó (entry "gitzen undo N") bị bỏ qua để undo lần nữa đi
+// tiếp về quá khứ thay vì undo chính lệnh undo.
+// Trả về false nếu thao tác tiếp theo không thể undo (e.g. initial commit).
+func InterpretReflog(entries []ReflogEntry) (ReflogAction, bool) {
+	undone, i := 0, 0
+	if len(entries) > 0 {
+		undone, _ = reflogUndoCount(entries[0])
+	}
+	for i < len(entries) {
+		if _, ok := reflogUndoCount(entries[i]); !ok {
+			break
+		}
+		i++
+	}
+	for n := 0; n < undone; n++ {
+		_, next, ok := interpretReflogAt(entries, i)
+		if !ok {
+			return ReflogAction{}, false
+		}
+		i = next
+	}
+
+	action, _, ok := interpretReflogAt(entries, i)
+	if !ok {
+		return ReflogAction{}, false
+	}
+	action.UndoReflogAction = reflogUndoPrefix + strconv.Itoa(undone+1)
+	return action, true
+}
+
+// interpretReflogAt diễn giải thao tác có entry mới nhất là entries[i], trả về kèm vị trí
+// entry đầu tiên trước thao tác đó
+func interpretReflogAt(entries []ReflogEntry, i int) (ReflogAction, int, bool) {
+	if i+1 >= len(entries) {
+		return ReflogAction{}, 0, false
+	}
+	top := entries[i]
+	action := ReflogAction{ToHash: top.Hash, FromHash: entries[i+1].Hash}
+
+	switch {
+	case top.Action == "commit":
+		action.Kind = "commit"
+		action.Description = "commit '" + top.Message + "'"
+	case top.Action == "commit (amend)":
+		action.Kind = "amend"
+		action.Description = "amend '" + top.Message + "'"
+	case top.Action == "commit (merge)" || strings.HasPrefix(top.Action, "merge "):
+		action.Kind = "merge"
+		action.Description = top.Action
+	case top.Action == "reset":
+		action.Kind = "reset"
+		action.Description = "reset (" + top.Message + ")"
+	case top.Action == "cherry-pick" || top.Action == "revert":
+		action.Kind = top.Action
+		action.Description = top.Action + " '" + top.Message + "'"
+	case top.Action == "checkout":
+		action.Kind = "checkout"
+		action.Description = "checkout (" + top.Message + ")"
+		from, _, ok := strings.Cut(strings.TrimPrefix(top.Message, "moving from "), " to ")
+		if !ok {
+			return ReflogAction{}, 0, false
+		}
+		if fullHashRE.MatchString(from) {
+			action.Detached = true
+		} else {
+			action.FromBranch = from
+		}
+	case strings.HasSuffix(top.Action, "(finish)"):
+		// rebase/pull --rebase tạo nhiều entry, tìm entry (start) để lấy HEAD trước khi rebase
+		prefix := strings.TrimSuffix(top.Action, "(finish)")
+		for j := i + 1; j < len(entries)-1; j++ {
+			if entries[j].Action == prefix+"(start)" {
+				action.Kind = "rebase"
+				action.Description = strings.TrimSpace(prefix)
+				action.FromHash = entries[j+1].Hash
+				return action, j + 1, true
+			}
+		}
+		return ReflogAction{}, 0, false
+	case strings.HasPrefix(top.Action, "pull"):
+		action.Kind = "pull"
+		action.Description = top.Action + " (" + top.Message + ")"
+	default:
+		return ReflogAction{}, 0, false
+	}
+	return action, i + 1, true
+}
+
+// CurrentHeadState trả về branch và hash hiện tại của HEAD
+func (r Runner) CurrentHeadState() (HeadState, error) {
+	hash, err := r.run(DefaultCmdTimeout, "rev-parse", "HEAD")
+	if err != nil {
+		return HeadState{}, err
+	}
+	branch, err := r.run(DefaultCmdTimeout, "symbolic-ref", "-q", "--short", "HEAD")
+	if err != nil {
+		// symbolic-ref fails khi HEAD detached
+		branch = ""
+	}
+	return HeadState{Branch: strings.TrimSpace(branch), Hash: strings.TrimSpace(hash)}, nil
+}
+
+// WouldClobber trả về các file có thay đổi local sẽ bị ghi đè khi di chuyển HEAD tới target
+func (r Runner) WouldClobber(target string) ([]string, error) {
+	changed, err := r.run(DefaultCmdTimeout, "diff", "--name-only", target, "HEAD", "--")
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(changed) == "" {
+		return nil, nil
+	}
+	b, err := r.StatusPorcelainZ()
+	if err != nil {
+		return nil, err
+	}
+	dirty := make(map[string]bool)
+	st := ParseStatusPorcelainV1Z(b)
+	for _, f := range append(st.Staged, st.Unstaged...) {
+		dirty[f.Path] = true
+	}
+
+	var clobbered []string
+	for _, path := range strings.Split(strings.TrimSpace(changed), "\n") {
+		if dirty[path] {
+			clobbered = append(clobbered, path)
+		}
+	}
+	return clobbered, nil
+}
+
+// ResetTo di chuyển branch hiện tại tới hash với mode (soft, mixed, keep)
+func (r Runner) ResetTo(mode, hash string) error {
+	_, err := r.run(DefaultCmdTimeout, "reset", "--"+mode, hash)
+	return err
+}
+
+// RestoreHeadState đưa HEAD về snapshot target: checkout branch (hoặc commit detached) rồi reset nếu cần.
+// resetMode rỗng nghĩa là "keep" (giữ thay đổi local, git từ chối nếu bị ghi đè).
+func (r Runner) RestoreHeadState(target HeadState, resetMode string) error {
+	if resetMode == "" {
+		resetMode = "keep"
+	}
+	current, err := r.CurrentHeadState()
+	if err != nil {
+		return err
+	}
+
+	if target.Branch == "" {
+		if current.Branch != "" || current.Hash != target.Hash {
+			_, err := r.CheckoutCommit(target.Hash)
+			return err
+		}
+		return nil
+	}
+
+	if target.Branch != current.Branch {
+		if _, err := r.CheckoutBranch(target.Branch); err != nil {
+			return err
+		}
+		if current, err = r.CurrentHeadState(); err != nil {
+			return err
+		}
+	}
+	if !strings.HasPrefix(current.Hash, target.Hash) {
+		return r.ResetTo(resetMode, target.Hash)
+	}
+	return nil
+}
+
+// BranchHash trả về hash mà branch local đang trỏ tới
+func (r Runner) BranchHash(name string) (string, error) {
+	out, err := r.run(DefaultCmdTimeout, "rev-parse", "refs/heads/"+name)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// CreateBranchAt tạo branch tại hash mà không checkout
+func (r Runner) CreateBranchAt(name, hash string) error {
+	if _, err := r.run(DefaultCmdTimeout, "branch", name, hash); err != nil {
+		return fmt.Errorf("cannot restore branch %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package git
+
+import "testing"
+
+func TestInterpretReflog_Empty(t *testing.T) {
+	if _, ok := InterpretReflog(nil); ok {
+		t.Error("expected no undoable action for empty reflog")
+	}
+}
+
+func TestInterpretReflog_Commit(t *testing.T) {
+	entries := ParseReflog("5bab862 HEAD@{0}: commit: add feature\ne15f360 HEAD@{1}: checkout: moving from main to work")
+	action, ok := InterpretReflog(entries)
+	if !ok {
+		t.Fatal("expected commit to be undoable")
+	}
+	if action.Kind != "commit" || action.FromHash != "e15f360" || action.ToHash != "5bab862" {
+		t.Errorf("unexpected action: %+v", action)
+	}
+}
+
+func TestInterpretReflog_InitialCommitNotUndoable(t *testing.T) {
+	entries := ParseReflog("e15f360 HEAD@{0}: commit (initial): one")
+	if _, ok := InterpretReflog(entries); ok {
+		t.Error("initial commit should not be undoable")
+	}
+}
+
+func TestInterpretReflog_Checkout(t *testing.T) {
+	entries := ParseReflog("c95c13e HEAD@{0}: checkout: moving from work to main\ne15f360 HEAD@{1}: commit: x")
+	action, ok := InterpretReflog(entries)
+	if !ok {
+		t.Fatal("expected checkout to be undoable")
+	}
+	if action.Kind != "checkout" || action.FromBranch != "work" || action.Detached {
+		t.Errorf("unexpected action: %+v", action)
+	}
+}
+
+func TestInterpretReflog_CheckoutFromDetached(t *testing.T) {
+	entries := ParseReflog("e15f360 HEAD@{0}: checkout: moving from c95c13e7906d453a4e884a68ee0b9102a35bd228 to work\nc95c13e HEAD@{1}: checkout: moving from work to c95c13e")
+	action, ok := InterpretReflog(entries)
+	if !ok {
+		t.Fatal("expected checkout to be undoable")
+	}
+	if !action.Detached || action.FromBranch != "" || action.FromHash != "c95c13e" {
+		t.Errorf("unexpected action: %+v", action)
+	}
+}
+
+func TestInterpretReflog_Rebase(t *testing.T) {
+	out := "c95c13e HEAD@{0}: rebase (finish): returning to refs/heads/work\n" +
+		"aaaaaaa HEAD@{1}: rebase (pick): x\n" +
+		"bbbbbbb HEAD@{2}: rebase (start): checkout main\n" +
+		"e15f360 HEAD@{3}: commit: x"
+	action, ok := InterpretReflog(ParseReflog(out))
+	if !ok {
+		t.Fatal("expected rebase to be undoable")
+	}
+	if action.Kind != "rebase" || action.FromHash != "e15f360" || action.ToHash != "c95c13e" {
+		t.Errorf("unexpected action: %+v", action)
+	}
+}
+
+func TestInterpretReflog_UnknownAction(t *testing.T) {
+	entries := ParseReflog("abc1234 HEAD@{0}: bisect: something\ndef5678 HEAD@{1}: commit: x")
+	if _, ok := InterpretReflog(entries); ok {
+		t.Error("unknown action should not be undoable")
+	}
+}
+
+func TestInterpretReflog_SkipsUndoneActions(t *testing.T) {
+	out := "e15f360 HEAD@{0}: gitzen undo 2: updating HEAD\n" +
+		"c95c13e HEAD@{1}: gitzen undo 2\n" +
+		"aaaaaaa HEAD@{2}: gitzen undo 1: updating HEAD\n" +
+		"bbbbbbb HEAD@{3}: commit: three\n" +
+		"aaaaaaa HEAD@{4}: checkout: moving from main to work\n" +
+		"c95c13e HEAD@{5}: commit: two\n" +
+		"e15f360 HEAD@{6}: commit (initial): one"
+	action, ok := InterpretReflog(ParseReflog(out))
+	if !ok {
+		t.Fatal("expected the commit before the undone actions to be undoable")
+	}
+	if action.Kind != "commit" || action.ToHash != "c95c13e" || action.FromHash != "e15f360" || action.UndoReflogAction != "gitzen undo 3" {
+		t.Errorf("unexpected action: %+v", action)
+	}
+}
+
+func TestInterpretReflog_FirstUndo(t *testing.T) {
+	action, ok := InterpretReflog(ParseReflog("5bab862 HEAD@{0}: commit: add feature\ne15f360 HEAD@{1}: commit: x"))
+	if !ok || action.UndoReflogAction != "gitzen undo 1" {
+		t.Errorf("unexpected action: %+v (ok=%v)", action, ok)
+	}
+}
+
+func TestReflogUndoWalksBack(t *testing.T) {
+	r := initTestRepo(t, nil)
+	commit := func(msg string) string {
+		t.Helper()
+		if _, err := r.run(DefaultCmdTimeout, "-c", "user.name=t", "-c", "user.email=t@t", "-c", "commit.gpgsign=false", "commit", "-q", "--allow-empty", "-m", msg); err != nil {
+			t.Fatal(err)
+		}
+		head, err := r.CurrentHeadState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return head.Hash
+	}
+	initHead, err := r.CurrentHeadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	two := commit("two")
+	three := commit("three")
+	if _, err := r.run(DefaultCmdTimeout, "checkout", "-q", "-b", "work", initHead.Hash); err != nil {
+		t.Fatal(err)
+	}
+	commit("four")
+
+	// undo commit four, checkout work, commit three, commit two; initial commit thì dừng
+	want := []HeadState{
+		{Branch: "work", Hash: initHead.Hash},
+		{Branch: initHead.Branch, Hash: three},
+		{Branch: initHead.Branch, Hash: two},
+		{Branch: initHead.Branch, Hash: initHead.Hash},
+	}
+	for i, w := range want {
+		out, err := r.Reflog()
+		if err != nil {
+			t.Fatal(err)
+		}
+		action, ok := InterpretReflog(ParseReflog(out))
+		if !ok {
+			t.Fatalf("undo %d: nothing to undo", i+1)
+		}
+		current, err := r.CurrentHeadState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		target := HeadState{Branch: current.Branch, Hash: action.FromHash}
+		if action.Kind == "checkout" {
+			target.Branch = action.FromBranch
+		}
+		if err := r.WithReflogAction(action.UndoReflogAction).RestoreHeadState(target, ""); err != nil {
+			t.Fatalf("undo %d (%s): %v", i+1, action.Description, err)
+		}
+		got, err := r.CurrentHeadState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != w {
+			t.Fatalf("undo %d (%s): HEAD = %+v, want %+v", i+1, action.Description, got, w)
+		}
+	}
+
+	out, err := r.Reflog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if action, ok := InterpretReflog(ParseReflog(out)); ok {
+		t.Errorf("initial commit should not be undoable, got %+v", action)
+	}
+}
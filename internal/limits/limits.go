@@ -10,8 +10,9 @@ const (
 	// MaxReflogEntries là số lượng reflog entry tối đa được tải.
 	MaxReflogEntries = 100
 
-	// MaxDiffLines là số dòng diff tối đa hiển thị trong diff view.
-	// Giới hạn này tránh render quá nhiều dữ liệu cho các file lớn.
+	// MaxDiffLines là số dòng diff tối đa được render toàn bộ trong diff view.
+	// Diff dài hơn được stream vào file tạm và hiển thị bằng virtual scrolling
+	// (chỉ đọc và tô màu các dòng đang hiện).
 	MaxDiffLines = 5000
 
 	// MaxStashEntries là số lượng stash entry tối đa được tải.
@@ -23,7 +24,13 @@ const (
 	// DiffTimeoutSec là timeout (giây) cho các lệnh tạo diff (lớn hơn do diff lớn).
 	DiffTimeoutSec = 10
 
-	// NetworkTimeoutSec là timeout (giây) cho các lệnh cần kết nối mạng
-	// như push, pull, fetch.
+	// NetworkTimeoutSec là timeout (giây) cho các lệnh truy vấn remote ngắn
+	// như ls-remote. Fetch dùng NetworkIdleTimeoutSec.
 	NetworkTimeoutSec = 30
+
+	// NetworkIdleTimeoutSec là thời gian (giây) tối đa không có output
+	// (progress, output của hook) trước khi huỷ fetch/push/pull. Không giới
+	// hạn tổng thời gian để các fetch lớn (monorepo) không bị kill giữa
+	// chừng. Đổi theo repo bằng network_idle_timeout_sec.
+	NetworkIdleTimeoutSec = 60
 )
@@ -24,25 +24,85 @@ func DefaultDiffStyler() DiffStyler {
 	}
 }
 
+// Colorize tô màu diff: header, hunk, nền xanh/đỏ cho dòng thêm/xoá và syntax
+// highlighting theo ngôn ngữ đoán từ header của từng file
 func (s DiffStyler) Colorize(diff string) string {
+	return s.ColorizeFile("", diff)
+}
+
+// ColorizeFile như Colorize, path dùng để đoán ngôn ngữ khi diff không có header
+// (e.g. một hunk hoặc một dòng)
+func (s DiffStyler) ColorizeFile(path, diff string) string {
 	if diff == "" {
 		return ""
 	}
 
 	lines := strings.Split(strings.ReplaceAll(diff, "\r\n", "\n"), "\n")
+	out := make([]string, 0, len(lines))
+	for _, section := range splitFileSections(lines) {
+		out = append(out, cachedRender("unified\x00"+path, section, func() string {
+			return strings.Join(s.ColorizeLines(path, false, section), "\n")
+		}))
+	}
+	return strings.Join(out, "\n")
+}
+
+// ColorizeLines tô một đoạn dòng cắt ra từ giữa diff (virtual scrolling): path là
+// file chứa dòng đầu, inHunk cho biết dòng đầu nằm trong hunk. Block comment mở
+// từ trước đoạn không được biết nên tô như code thường.
+func (s DiffStyler) ColorizeLines(path string, inHunk bool, lines []string) []string {
+	hl := newHighlighter(path)
+	changed := pairChangedLines(lines, inHunk, lineText)
+	out := make([]string, len(lines))
 	for i, line := range lines {
 		switch {
-		case strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++"):
-			lines[i] = s.Added.Render(line)
-		case strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---"):
-			lines[i] = s.Removed.Render(line)
+		case strings.HasPrefix(line, "diff "):
+			inHunk = false
+			hl.setPath(diffHeaderPath(line))
+			out[i] = s.Header.Render(line)
 		case strings.HasPrefix(line, "@@"):
-			lines[i] = s.Hunk.Render(line)
-		case strings.HasPrefix(line, "diff --git") || strings.HasPrefix(line, "index ") || strings.HasPrefix(line, "---") || strings.HasPrefix(line, "+++"):
-			lines[i] = s.Header.Render(line)
+			inHunk = true
+			hl.resetState()
+			out[i] = s.Hunk.Render(line)
+		case inHunk && line != "" && (line[0] == '+' || line[0] == '-' || line[0] == ' '):
+			out[i] = s.highlightLine(hl, line, changed[i])
+		case strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---"):
+			if p := diffHeaderPath(line); p != "" {
+				hl.setPath(p)
+			}
+			out[i] = s.Header.Render(line)
+		case strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-"):
+			out[i] = s.highlightLine(hl, line, changed[i])
+		case strings.HasPrefix(line, "index "):
+			out[i] = s.Header.Render(line)
 		case strings.HasPrefix(line, "\\ No newline at end of file"):
-			lines[i] = s.Dim.Render(line)
+			out[i] = s.Dim.Render(line)
+		case strings.HasPrefix(line, "Binary file changed: ") || strings.HasPrefix(line, "LFS object "):
+			// bản tóm tắt của git.SummarizeBinaryDiff thay cho hunk
+			out[i] = s.Hunk.Render(line)
+		default:
+			out[i] = line
 		}
 	}
-	return strings.Join(lines, "\n")
+	return out
+}
+
+// highlightLine tô một dòng +/-/space: tiền tố theo màu Added/Removed, nội dung
+// theo syntax trên nền của loại dòng, các đoạn trong changed được nhấn mạnh
+func (s DiffStyler) highlightLine(hl *highlighter, line string, changed []byteRange) string {
+	tone := toneOf(line[0])
+	text := lineText(line)
+	prefix := line[:1]
+	switch tone {
+	case toneAdded:
+		prefix = s.Added.Background(toneBackgrounds[tone]).Render(prefix)
+	case toneRemoved:
+		prefix = s.Removed.Background(toneBackgrounds[tone]).Render(prefix)
+	}
+	return prefix + renderTokens(markChanged(hl.tokens(text, tone), changed), tone)
+}
+
+// lineText là nội dung dòng diff được render: bỏ tiền tố, tab thành 4 space
+func lineText(line string) string {
+	return expandTabs(line[1:])
 }
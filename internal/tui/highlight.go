@@ -0,0 +1,254 @@
+package tui
+
+import (
+	"hash/fnv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// lineTone là loại dòng diff, quyết định nền tô dưới màu syntax
+type lineTone int
+
+const (
+	toneContext lineTone = iota
+	toneAdded
+	toneRemoved
+	toneAddedEmph   // đoạn thay đổi trong dòng thêm
+	toneRemovedEmph // đoạn thay đổi trong dòng xoá
+	toneCount
+)
+
+// Màu syntax cho terminal truecolor/256 màu. Terminal 16 màu không đủ màu để vừa
+// tô nền vừa tô syntax nên dòng +/- giữ nguyên màu xanh/đỏ cả dòng như trước,
+// chỉ dòng context được tô syntax bằng màu ANSI cơ bản.
+var syntaxColors = [tokenKindCount]lipgloss.CompleteColor{
+	tokPlain:   {},
+	tokKeyword: {TrueColor: "#c678dd", ANSI256: "170", ANSI: "5"},
+	tokType:    {TrueColor: "#56b6c2", ANSI256: "73", ANSI: "6"},
+	tokString:  {TrueColor: "#e5c07b", ANSI256: "180", ANSI: "3"},
+	tokNumber:  {TrueColor: "#d19a66", ANSI256: "173", ANSI: "3"},
+	tokComment: {TrueColor: "#7f848e", ANSI256: "244", ANSI: "8"},
+}
+
+var (
+	toneBackgrounds = [toneCount]lipgloss.CompleteColor{
+		toneAdded:       {TrueColor: "#1e3a28", ANSI256: "22"},
+		toneRemoved:     {TrueColor: "#45232a", ANSI256: "52"},
+		toneAddedEmph:   {TrueColor: "#2c6b40", ANSI256: "28"},
+		toneRemovedEmph: {TrueColor: "#7d2f3b", ANSI256: "88"},
+	}
+	toneANSI = [toneCount]string{toneAdded: "2", toneRemoved: "1", toneAddedEmph: "2", toneRemovedEmph: "1"}
+
+	// tokenStyles[tone][kind] được tạo một lần, Render vẫn theo color profile lúc chạy
+	tokenStyles = func() (t [toneCount][tokenKindCount]lipgloss.Style) {
+		for tone := range t {
+			for kind := range t[tone] {
+				fg := syntaxColors[kind]
+				if tone != int(toneContext) {
+					fg.ANSI = toneANSI[tone]
+				}
+				t[tone][kind] = lipgloss.NewStyle().Foreground(fg).Background(toneBackgrounds[tone])
+				if tone == int(toneAddedEmph) || tone == int(toneRemovedEmph) {
+					// terminal 16 màu không có nền, bold để đoạn thay đổi vẫn nổi bật
+					t[tone][kind] = t[tone][kind].Bold(true)
+				}
+			}
+		}
+		return t
+	}()
+)
+
+// highlighter giữ trạng thái tô màu trong một file: ngôn ngữ và block comment
+// đang mở của phía old (context + dòng xoá) và new (context + dòng thêm)
+type highlighter struct {
+	lang         *language
+	oldIn, newIn bool
+}
+
+func newHighlighter(path string) *highlighter {
+	return &highlighter{lang: languageForPath(path)}
+}
+
+// setPath đổi ngôn ngữ khi gặp header của file khác
+func (h *highlighter) setPath(path string) {
+	h.lang = languageForPath(path)
+	h.resetState()
+}
+
+// resetState xoá block comment đang mở (mỗi hunk bắt đầu từ code chưa biết)
+func (h *highlighter) resetState() {
+	h.oldIn, h.newIn = false, false
+}
+
+// tokens tách nội dung một dòng unified (không có tiền tố +/-/space) và cập nhật
+// trạng thái; dòng context thuộc cả hai phía
+func (h *highlighter) tokens(text string, tone lineTone) []token {
+	switch tone {
+	case toneAdded:
+		return h.sideTokens(text, false)
+	case toneRemoved:
+		return h.sideTokens(text, true)
+	}
+	toks := h.sideTokens(text, false)
+	h.oldIn = h.newIn
+	return toks
+}
+
+// sideTokens tách một dòng của phía old hoặc new (dùng cho cột side-by-side)
+func (h *highlighter) sideTokens(text string, old bool) []token {
+	if h.lang == nil {
+		return []token{{kind: tokPlain, text: text}}
+	}
+	in := &h.newIn
+	if old {
+		in = &h.oldIn
+	}
+	toks, end := h.lang.tokenize(text, *in)
+	*in = end
+	return toks
+}
+
+func renderTokens(toks []token, tone lineTone) string {
+	var b strings.Builder
+	for _, t := range toks {
+		tt := tone
+		if t.emph {
+			tt = emphTone(tone)
+		}
+		b.WriteString(tokenStyles[tt][t.kind].Render(t.text))
+	}
+	return b.String()
+}
+
+func emphTone(tone lineTone) lineTone {
+	switch tone {
+	case toneAdded:
+		return toneAddedEmph
+	case toneRemoved:
+		return toneRemovedEmph
+	}
+	return tone
+}
+
+// wrapTokens chia token thành các dòng rộng tối đa width (cắt cả giữa token)
+func wrapTokens(toks []token, width int) [][]token {
+	lines := [][]token{nil}
+	used := 0
+	for _, t := range toks {
+		text := t.text
+		for text != "" {
+			if used == width {
+				lines = append(lines, nil)
+				used = 0
+			}
+			part := ansi.Truncate(text, width-used, "")
+			if part == "" {
+				// rune rộng hơn phần còn lại của dòng; dòng rỗng thì vẫn phải lấy một rune
+				if used == 0 {
+					_, size := utf8.DecodeRuneInString(text)
+					part = text[:size]
+				} else {
+					used = width
+					continue
+				}
+			}
+			t.text = part
+			lines[len(lines)-1] = append(lines[len(lines)-1], t)
+			used += ansi.StringWidth(part)
+			text = text[len(part):]
+		}
+	}
+	return lines
+}
+
+func toneOf(prefix byte) lineTone {
+	switch prefix {
+	case '+':
+		return toneAdded
+	case '-':
+		return toneRemoved
+	}
+	return toneContext
+}
+
+// diffHeaderPath lấy path mới từ "diff --git a/x b/x" hoặc "+++ b/x"/"--- a/x"
+func diffHeaderPath(line string) string {
+	switch {
+	case strings.HasPrefix(line, "diff --git "):
+		if i := strings.LastIndex(line, " b/"); i >= 0 {
+			return line[i+3:]
+		}
+	case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+		path := strings.TrimSpace(line[4:])
+		if path == "/dev/null" {
+			return ""
+		}
+		if len(path) > 2 && (path[:2] == "a/" || path[:2] == "b/") {
+			path = path[2:]
+		}
+		return path
+	}
+	return ""
+}
+
+// splitFileSections chia diff thành phần đầu (e.g. header commit) và từng file
+// bắt đầu bằng "diff ", để cache theo file
+func splitFileSections(lines []string) [][]string {
+	var sections [][]string
+	start := 0
+	for i, line := range lines {
+		if i > start && strings.HasPrefix(line, "diff ") {
+			sections = append(sections, lines[start:i])
+			start = i
+		}
+	}
+	return append(sections, lines[start:])
+}
+
+const (
+	// renderCacheSize giới hạn số file đã tô màu được giữ lại
+	renderCacheSize = 256
+	// minCachedLines: đoạn ngắn hơn (e.g. từng dòng của patch view) render thẳng
+	// để không đẩy các file lớn ra khỏi cache
+	minCachedLines = 8
+)
+
+// renderCache lưu kết quả tô màu theo nội dung file, để scroll/đổi file qua lại
+// và resize không phải tokenize lại
+var renderCache = struct {
+	mu      sync.Mutex
+	entries map[uint64]string
+}{entries: make(map[uint64]string)}
+
+func cachedRender(key string, section []string, render func() string) string {
+	if len(section) < minCachedLines {
+		return render()
+	}
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	for _, line := range section {
+		h.Write([]byte{'\n'})
+		h.Write([]byte(line))
+	}
+	sum := h.Sum64()
+
+	renderCache.mu.Lock()
+	out, ok := renderCache.entries[sum]
+	renderCache.mu.Unlock()
+	if ok {
+		return out
+	}
+
+	out = render()
+	renderCache.mu.Lock()
+	if len(renderCache.entries) >= renderCacheSize {
+		clear(renderCache.entries)
+	}
+	renderCache.entries[sum] = out
+	renderCache.mu.Unlock()
+	return out
+}
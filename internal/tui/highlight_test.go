@@ -0,0 +1,135 @@
+package tui
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestWrapTokens(t *testing.T) {
+	tests := []struct {
+		name  string
+		toks  []token
+		width int
+		want  [][]token
+	}{
+		{
+			name:  "split inside token keeps kind and emph",
+			toks:  []token{{kind: tokKeyword, text: "return"}, {kind: tokPlain, text: " xy", emph: true}},
+			width: 4,
+			want: [][]token{
+				{{kind: tokKeyword, text: "retu"}},
+				{{kind: tokKeyword, text: "rn"}, {kind: tokPlain, text: " x", emph: true}},
+				{{kind: tokPlain, text: "y", emph: true}},
+			},
+		},
+		{
+			name:  "wide rune moves to next line instead of overflowing",
+			toks:  []token{{kind: tokPlain, text: "ab"}, {kind: tokString, text: "漢字"}},
+			width: 3,
+			want: [][]token{
+				{{kind: tokPlain, text: "ab"}},
+				{{kind: tokString, text: "漢"}},
+				{{kind: tokString, text: "字"}},
+			},
+		},
+		{
+			name:  "wide rune wider than the line takes one rune per line",
+			toks:  []token{{kind: tokString, text: "漢字"}},
+			width: 1,
+			want: [][]token{
+				{{kind: tokString, text: "漢"}},
+				{{kind: tokString, text: "字"}},
+			},
+		},
+		{
+			name:  "empty input",
+			toks:  nil,
+			width: 5,
+			want:  [][]token{nil},
+		},
+	}
+	for _, tt := range tests {
+		if got := wrapTokens(tt.toks, tt.width); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: wrapTokens = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// Terminal 16 màu: dòng +/- giữ màu xanh/đỏ cả dòng, không có nền; đoạn thay đổi
+// được in đậm; dòng context vẫn tô syntax bằng màu ANSI cơ bản
+func TestTokenStyles16ColorFallback(t *testing.T) {
+	for kind := tokenKind(0); kind < tokenKindCount; kind++ {
+		for tone, ansi := range map[lineTone]string{
+			toneContext:     syntaxColors[kind].ANSI,
+			toneAdded:       "2",
+			toneRemoved:     "1",
+			toneAddedEmph:   "2",
+			toneRemovedEmph: "1",
+		} {
+			style := tokenStyles[tone][kind]
+			fg, ok := style.GetForeground().(lipgloss.CompleteColor)
+			if !ok || fg.ANSI != ansi {
+				t.Errorf("tone %d kind %d: foreground = %#v, want ANSI %q", tone, kind, style.GetForeground(), ansi)
+			}
+			if bg, ok := style.GetBackground().(lipgloss.CompleteColor); !ok || bg.ANSI != "" {
+				t.Errorf("tone %d kind %d: background = %#v, want no ANSI background", tone, kind, style.GetBackground())
+			}
+			emph := tone == toneAddedEmph || tone == toneRemovedEmph
+			if style.GetBold() != emph {
+				t.Errorf("tone %d kind %d: bold = %v, want %v", tone, kind, style.GetBold(), emph)
+			}
+		}
+	}
+}
+
+func TestCachedRender(t *testing.T) {
+	clear(renderCache.entries)
+	section := make([]string, minCachedLines)
+	for i := range section {
+		section[i] = fmt.Sprintf("+line %d", i)
+	}
+	calls := 0
+	render := func() string {
+		calls++
+		return fmt.Sprintf("out %d", calls)
+	}
+
+	if got := cachedRender("a.go", section, render); got != "out 1" {
+		t.Fatalf("first render = %q", got)
+	}
+	if got := cachedRender("a.go", section, render); got != "out 1" || calls != 1 {
+		t.Errorf("same key and section should hit cache: %q, calls %d", got, calls)
+	}
+	// key (e.g. độ rộng, tuỳ chọn) và nội dung đều thuộc khoá cache
+	cachedRender("b.go", section, render)
+	changed := append([]string(nil), section...)
+	changed[0] = "-line 0"
+	cachedRender("a.go", changed, render)
+	if calls != 3 {
+		t.Errorf("different key or content should render again, calls %d", calls)
+	}
+
+	// đoạn ngắn không được cache
+	short := section[:minCachedLines-1]
+	cachedRender("a.go", short, render)
+	cachedRender("a.go", short, render)
+	if calls != 5 {
+		t.Errorf("short sections should not be cached, calls %d", calls)
+	}
+
+	// đầy cache thì xoá hết rồi thêm lại
+	for i := range renderCacheSize {
+		cachedRender(fmt.Sprint("fill", i), section, func() string { return "" })
+	}
+	if n := len(renderCache.entries); n > renderCacheSize {
+		t.Errorf("cache grew past limit: %d", n)
+	}
+	before := calls
+	cachedRender("a.go", section, render)
+	if calls != before+1 {
+		t.Error("evicted entry should render again")
+	}
+}
@@ -0,0 +1,183 @@
+package tui
+
+import "strings"
+
+const (
+	// maxIntralineWords giới hạn số từ mỗi dòng khi so sánh (LCS là O(n*m))
+	maxIntralineWords = 256
+	// minIntralineSimilarity: hai dòng giống nhau ít hơn ngưỡng này coi như viết
+	// lại hoàn toàn, không nhấn mạnh gì (như delta)
+	minIntralineSimilarity = 0.4
+)
+
+// byteRange là đoạn [start, end) byte trong nội dung dòng
+type byteRange [2]int
+
+// splitWords tách dòng thành từ: chuỗi ký tự identifier, chuỗi khoảng trắng,
+// hoặc một ký tự dấu câu
+func splitWords(s string) []string {
+	var words []string
+	for i := 0; i < len(s); {
+		end := i + 1
+		switch {
+		case isIdentByte(s[i]):
+			for end < len(s) && isIdentByte(s[end]) {
+				end++
+			}
+		case s[i] == ' ':
+			for end < len(s) && s[end] == ' ' {
+				end++
+			}
+		}
+		words = append(words, s[i:end])
+		i = end
+	}
+	return words
+}
+
+// intralineRanges so sánh theo từ một cặp dòng xoá/thêm, trả về các đoạn thay
+// đổi trong mỗi dòng. ok=false khi dòng quá dài hoặc khác nhau quá nhiều.
+func intralineRanges(oldText, newText string) (oldRanges, newRanges []byteRange, ok bool) {
+	a, b := splitWords(oldText), splitWords(newText)
+	if len(a) > maxIntralineWords || len(b) > maxIntralineWords || len(a) == 0 && len(b) == 0 {
+		return nil, nil, false
+	}
+
+	// lcs[i][j] = độ dài LCS (tính theo byte) của a[i:] và b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + len(a[i])
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	common := lcs[0][0]
+	if float64(2*common) < minIntralineSimilarity*float64(len(oldText)+len(newText)) {
+		return nil, nil, false
+	}
+
+	var oldChanged, newChanged []bool
+	for i, j := 0, 0; i < len(a) || j < len(b); {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			oldChanged = append(oldChanged, false)
+			newChanged = append(newChanged, false)
+			i++
+			j++
+		case j >= len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			oldChanged = append(oldChanged, true)
+			i++
+		default:
+			newChanged = append(newChanged, true)
+			j++
+		}
+	}
+	return wordRanges(a, oldChanged), wordRanges(b, newChanged), true
+}
+
+// wordRanges gộp các từ thay đổi liền nhau thành đoạn byte; khoảng trắng không
+// đổi kẹp giữa hai từ thay đổi được gộp luôn để đoạn nhấn mạnh liền mạch
+func wordRanges(words []string, changed []bool) []byteRange {
+	var ranges []byteRange
+	pos := 0
+	for i, w := range words {
+		end := pos + len(w)
+		if changed[i] {
+			n := len(ranges)
+			if n > 0 && (ranges[n-1][1] == pos || gapIsSpace(words, changed, i)) {
+				ranges[n-1][1] = end
+			} else {
+				ranges = append(ranges, byteRange{pos, end})
+			}
+		}
+		pos = end
+	}
+	return ranges
+}
+
+// gapIsSpace kiểm tra từ ngay trước words[i] là khoảng trắng không đổi và
+// trước nó là từ thay đổi
+func gapIsSpace(words []string, changed []bool, i int) bool {
+	return i >= 2 && !changed[i-1] && strings.TrimSpace(words[i-1]) == "" && changed[i-2]
+}
+
+// markChanged tách token tại biên các đoạn thay đổi và đánh dấu emph
+func markChanged(toks []token, ranges []byteRange) []token {
+	if len(ranges) == 0 {
+		return toks
+	}
+	var out []token
+	pos, r := 0, 0
+	for _, t := range toks {
+		text := t.text
+		for text != "" {
+			for r < len(ranges) && ranges[r][1] <= pos {
+				r++
+			}
+			n := len(text)
+			emph := false
+			if r < len(ranges) {
+				if pos >= ranges[r][0] {
+					emph = true
+					n = min(n, ranges[r][1]-pos)
+				} else {
+					n = min(n, ranges[r][0]-pos)
+				}
+			}
+			out = append(out, token{kind: t.kind, text: text[:n], emph: emph})
+			text = text[n:]
+			pos += n
+		}
+	}
+	return out
+}
+
+// pairChangedLines ghép các khối dòng "-" với khối "+" ngay sau nó trong hunk
+// (dòng thứ i với dòng thứ i) và trả về đoạn thay đổi theo index dòng. text trả
+// về nội dung đã bỏ tiền tố và đổi tab như khi render; inHunk là trạng thái ở dòng đầu.
+func pairChangedLines(lines []string, inHunk bool, text func(line string) string) map[int][]byteRange {
+	ranges := make(map[int][]byteRange)
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "diff "):
+			inHunk = false
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		}
+		if !isRemovedLine(line, inHunk) {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(lines) && isRemovedLine(lines[i], inHunk) {
+			i++
+		}
+		mid := i
+		for i < len(lines) && isAddedLine(lines[i], inHunk) {
+			i++
+		}
+		for k := 0; k < mid-start && mid+k < i; k++ {
+			o, n := start+k, mid+k
+			if oldR, newR, ok := intralineRanges(text(lines[o]), text(lines[n])); ok {
+				ranges[o], ranges[n] = oldR, newR
+			}
+		}
+	}
+	return ranges
+}
+
+func isRemovedLine(line string, inHunk bool) bool {
+	return strings.HasPrefix(line, "-") && (inHunk || !strings.HasPrefix(line, "---"))
+}
+
+func isAddedLine(line string, inHunk bool) bool {
+	return strings.HasPrefix(line, "+") && (inHunk || !strings.HasPrefix(line, "+++"))
+}
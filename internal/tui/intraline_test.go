@@ -0,0 +1,191 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIntralineRanges(t *testing.T) {
+	tests := []struct {
+		name             string
+		oldText, newText string
+		oldWant, newWant []byteRange
+		ok               bool
+	}{
+		{
+			name:    "one character",
+			oldText: "x := 1", newText: "x := 2",
+			oldWant: []byteRange{{5, 6}}, newWant: []byteRange{{5, 6}},
+			ok: true,
+		},
+		{
+			name:    "unchanged space between changed words is merged",
+			oldText: "return foo bar", newText: "return baz qux",
+			oldWant: []byteRange{{7, 14}}, newWant: []byteRange{{7, 14}},
+			ok: true,
+		},
+		{
+			name:    "separate changes stay separate",
+			oldText: "f(a, b, c)", newText: "f(x, b, y)",
+			oldWant: []byteRange{{2, 3}, {8, 9}}, newWant: []byteRange{{2, 3}, {8, 9}},
+			ok: true,
+		},
+		{
+			name:    "insertion only",
+			oldText: "foo()", newText: "foo(bar)",
+			oldWant: nil, newWant: []byteRange{{4, 7}},
+			ok: true,
+		},
+		{
+			name:    "rewritten line is below similarity cutoff",
+			oldText: "alpha beta", newText: "gamma delta",
+			ok: false,
+		},
+		{
+			name:    "just above similarity cutoff",
+			oldText: "ab cd", newText: "ab xy",
+			oldWant: []byteRange{{3, 5}}, newWant: []byteRange{{3, 5}},
+			ok: true,
+		},
+		{
+			name:    "just below similarity cutoff",
+			oldText: "ab cdefgh", newText: "ab stuvwx",
+			ok: false,
+		},
+		{
+			name:    "empty lines",
+			oldText: "", newText: "",
+			ok: false,
+		},
+	}
+	for _, tt := range tests {
+		oldR, newR, ok := intralineRanges(tt.oldText, tt.newText)
+		if ok != tt.ok {
+			t.Errorf("%s: ok = %v, want %v", tt.name, ok, tt.ok)
+			continue
+		}
+		if !reflect.DeepEqual(oldR, tt.oldWant) || !reflect.DeepEqual(newR, tt.newWant) {
+			t.Errorf("%s: ranges = %v / %v, want %v / %v", tt.name, oldR, newR, tt.oldWant, tt.newWant)
+		}
+	}
+}
+
+func TestIntralineRangesTooLong(t *testing.T) {
+	long := ""
+	for range maxIntralineWords + 1 {
+		long += "a "
+	}
+	if _, _, ok := intralineRanges(long, long+"b"); ok {
+		t.Error("lines over maxIntralineWords should not be compared")
+	}
+}
+
+func TestWordRanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		words   []string
+		changed []bool
+		want    []byteRange
+	}{
+		{"adjacent words", []string{"a", "b", " ", "c"}, []bool{true, true, false, false}, []byteRange{{0, 2}}},
+		{"space gap merged", []string{"a", " ", "b"}, []bool{true, false, true}, []byteRange{{0, 3}}},
+		{"punctuation gap kept", []string{"a", ",", "b"}, []bool{true, false, true}, []byteRange{{0, 1}, {2, 3}}},
+		{"two word gap kept", []string{"a", " ", "x", " ", "b"}, []bool{true, false, false, false, true}, []byteRange{{0, 1}, {4, 5}}},
+		{"nothing changed", []string{"a", " ", "b"}, []bool{false, false, false}, nil},
+	}
+	for _, tt := range tests {
+		if got := wordRanges(tt.words, tt.changed); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: wordRanges = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMarkChanged(t *testing.T) {
+	toks := []token{{kind: tokKeyword, text: "return"}, {kind: tokPlain, text: " "}, {kind: tokPlain, text: "foobar"}}
+	tests := []struct {
+		name   string
+		ranges []byteRange
+		want   []token
+	}{
+		{"no ranges", nil, toks},
+		{
+			"range across token boundaries",
+			[]byteRange{{3, 9}},
+			[]token{
+				{kind: tokKeyword, text: "ret"},
+				{kind: tokKeyword, text: "urn", emph: true},
+				{kind: tokPlain, text: " ", emph: true},
+				{kind: tokPlain, text: "fo", emph: true},
+				{kind: tokPlain, text: "obar"},
+			},
+		},
+		{
+			"two ranges inside one token",
+			[]byteRange{{7, 8}, {10, 13}},
+			[]token{
+				{kind: tokKeyword, text: "return"},
+				{kind: tokPlain, text: " "},
+				{kind: tokPlain, text: "f", emph: true},
+				{kind: tokPlain, text: "oo"},
+				{kind: tokPlain, text: "bar", emph: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		if got := markChanged(toks, tt.ranges); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: markChanged = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPairChangedLines(t *testing.T) {
+	strip := func(line string) string { return line[1:] }
+	tests := []struct {
+		name   string
+		lines  []string
+		inHunk bool
+		want   map[int][]byteRange
+	}{
+		{
+			name: "more removed than added",
+			lines: []string{
+				"@@ -1,3 +1,2 @@",
+				"-a = 1",
+				"-b = 2",
+				"-c = 3",
+				"+a = 9",
+				"+b = 8",
+			},
+			want: map[int][]byteRange{1: {{4, 5}}, 4: {{4, 5}}, 2: {{4, 5}}, 5: {{4, 5}}},
+		},
+		{
+			name:   "more added than removed",
+			lines:  []string{"-x = 1", "+x = 2", "+y = 3"},
+			inHunk: true,
+			want:   map[int][]byteRange{0: {{4, 5}}, 1: {{4, 5}}},
+		},
+		{
+			name: "file headers are not paired",
+			lines: []string{
+				"diff --git a/x b/x",
+				"--- a/x",
+				"+++ b/x",
+				"@@ -1 +1 @@",
+				"-v := 1",
+				"+v := 2",
+			},
+			want: map[int][]byteRange{4: {{5, 6}}, 5: {{5, 6}}},
+		},
+		{
+			name:   "context line splits blocks",
+			lines:  []string{"-a = 1", " keep", "+a = 2"},
+			inHunk: true,
+			want:   map[int][]byteRange{},
+		},
+	}
+	for _, tt := range tests {
+		if got := pairChangedLines(tt.lines, tt.inHunk, strip); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: pairChangedLines = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package tui
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"gitzen/internal/git"
+)
+
+// MinSideBySideWidth là độ rộng tối thiểu để hiển thị hai cột, hẹp hơn thì dùng unified
+const MinSideBySideWidth = 80
+
+// CanSideBySide kiểm tra độ rộng có đủ cho hai cột không
+func CanSideBySide(width int) bool {
+	return width >= MinSideBySideWidth
+}
+
+// RenderSideBySide render diff thành hai cột old/new với số dòng. Header (commit,
+// diff --git, ---/+++) giữ nguyên toàn chiều rộng; hunk không phải unified
+// (e.g. --word-diff) và terminal hẹp fallback về Colorize.
+func (s DiffStyler) RenderSideBySide(diff string, width int) string {
+	if diff == "" || !CanSideBySide(width) {
+		return s.Colorize(diff)
+	}
+
+	lines := strings.Split(strings.ReplaceAll(diff, "\r\n", "\n"), "\n")
+	out := make([]string, 0, len(lines))
+	for _, section := range splitFileSections(lines) {
+		out = append(out, cachedRender("sbs\x00"+strconv.Itoa(width), section, func() string {
+			return s.renderSideBySideSection(section, width)
+		}))
+	}
+	return strings.Join(out, "\n")
+}
+
+func (s DiffStyler) renderSideBySideSection(lines []string, width int) string {
+	hl := newHighlighter("")
+	var out []string
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if !strings.HasPrefix(line, "@@") {
+			if strings.HasPrefix(line, "diff ") {
+				hl.setPath(diffHeaderPath(line))
+			}
+			out = append(out, s.Colorize(line))
+			i++
+			continue
+		}
+
+		j := i + 1
+		unified := true
+		for j < len(lines) && !strings.HasPrefix(lines[j], "@@") && !strings.HasPrefix(lines[j], "diff ") {
+			unified = unified && git.IsUnifiedHunkLine(lines[j])
+			j++
+		}
+		segment := lines[i:j]
+		hunks := git.ParseHunks(strings.Join(segment, "\n"))
+		if !unified || len(hunks) == 0 {
+			out = append(out, s.Colorize(strings.Join(segment, "\n")))
+		} else {
+			hl.resetState()
+			out = append(out, s.Hunk.Render(line))
+			out = append(out, s.renderHunkColumns(hl, hunks[0], width)...)
+		}
+		i = j
+	}
+	return strings.Join(out, "\n")
+}
+
+// renderHunkColumns render các hàng của hunk, dòng dài được wrap trong cột của nó
+func (s DiffStyler) renderHunkColumns(hl *highlighter, h git.Hunk, width int) []string {
+	numW := len(strconv.Itoa(max(h.OldStart+h.OldLines, h.NewStart+h.NewLines)))
+	colW := (width - 1) / 2
+	textW := max(1, colW-numW-1)
+	sep := s.Dim.Render("│")
+
+	var out []string
+	for _, row := range git.AlignHunk(h) {
+		var oldR, newR []byteRange
+		if row.Old.Kind == git.SideRemoved && row.New.Kind == git.SideAdded {
+			oldR, newR, _ = intralineRanges(expandTabs(row.Old.Text), expandTabs(row.New.Text))
+		}
+		left := s.renderCell(hl, row.Old, true, oldR, numW, textW)
+		right := s.renderCell(hl, row.New, false, newR, numW, textW)
+		for len(left) < len(right) {
+			left = append(left, strings.Repeat(" ", colW))
+		}
+		for len(right) < len(left) {
+			right = append(right, strings.Repeat(" ", colW))
+		}
+		for k := range left {
+			out = append(out, left[k]+sep+right[k])
+		}
+	}
+	return out
+}
+
+// renderCell render một ô của cột old hoặc new thành các dòng đã pad đủ độ rộng cột
+func (s DiffStyler) renderCell(hl *highlighter, cell git.SideLine, old bool, changed []byteRange, numW, textW int) []string {
+	colW := numW + 1 + textW
+	if cell.Kind == git.SideEmpty {
+		return []string{s.Dim.Render(strings.Repeat("╱", colW))}
+	}
+
+	tone := toneContext
+	switch cell.Kind {
+	case git.SideRemoved:
+		tone = toneRemoved
+	case git.SideAdded:
+		tone = toneAdded
+	}
+	wrapped := wrapTokens(markChanged(hl.sideTokens(expandTabs(cell.Text), old), changed), textW)
+	out := make([]string, len(wrapped))
+	for i, part := range wrapped {
+		num := strings.Repeat(" ", numW)
+		if i == 0 {
+			num = padLeft(strconv.Itoa(cell.Num), numW)
+		}
+		used := 0
+		for _, t := range part {
+			used += ansi.StringWidth(t.text)
+		}
+		pad := tokenStyles[tone][tokPlain].Render(strings.Repeat(" ", max(0, textW-used)))
+		out[i] = s.Dim.Render(num) + " " + renderTokens(part, tone) + pad
+	}
+	return out
+}
+
+func expandTabs(s string) string {
+	return strings.ReplaceAll(s, "\t", "    ")
+}
+
+func padLeft(s string, w int) string {
+	if len(s) >= w {
+		return s
+	}
+	return strings.Repeat(" ", w-len(s)) + s
+}
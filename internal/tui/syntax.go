@@ -0,0 +1,299 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// tokenKind là loại token cho syntax highlighting
+type tokenKind int
+
+const (
+	tokPlain tokenKind = iota
+	tokKeyword
+	tokType
+	tokString
+	tokNumber
+	tokComment
+	tokenKindCount
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	emph bool // thuộc đoạn thay đổi trong dòng (intra-line highlight)
+}
+
+// language mô tả đủ cú pháp để tô màu từng dòng: keyword, comment, string, số.
+// Không parse thật, chỉ lexer nhẹ theo dòng (block comment được mang sang dòng sau).
+type language struct {
+	keywords     map[string]bool
+	types        map[string]bool
+	lineComments []string
+	blockComment [2]string
+	quotes       string // các ký tự mở/đóng string
+}
+
+func words(s string) map[string]bool {
+	m := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		m[w] = true
+	}
+	return m
+}
+
+var (
+	langGo = &language{
+		keywords: words(`break case chan const continue default defer else fallthrough for func go goto if
+			import interface map package range return select struct switch type var nil true false iota`),
+		types: words(`bool byte complex64 complex128 error float32 float64 int int8 int16 int32 int64 rune
+			string uint uint8 uint16 uint32 uint64 uintptr any comparable`),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'`",
+	}
+	langJS = &language{
+		keywords: words(`async await break case catch class const continue debugger default delete do else
+			export extends finally for from function if import in instanceof let new of return static super
+			switch this throw try typeof var void while with yield null undefined true false interface type
+			enum implements declare readonly as`),
+		types:        words(`string number boolean any unknown never object void bigint symbol`),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'`",
+	}
+	langPython = &language{
+		keywords: words(`and as assert async await break class continue def del elif else except finally for
+			from global if import in is lambda nonlocal not or pass raise return try while with yield None
+			True False self`),
+		types:        words(`int float str bytes bool list dict set tuple object`),
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+	langRust = &language{
+		keywords: words(`as async await break const continue crate dyn else enum extern fn for if impl in let
+			loop match mod move mut pub ref return self Self static struct super trait type unsafe use where
+			while true false`),
+		types: words(`bool char str String i8 i16 i32 i64 i128 isize u8 u16 u32 u64 u128 usize f32 f64 Vec
+			Option Result Box`),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"",
+	}
+	langC = &language{
+		keywords: words(`auto break case catch class const constexpr continue default delete do else enum
+			extern for friend goto if inline namespace new operator private protected public return sizeof
+			static struct switch template this throw try typedef typename union using virtual volatile while
+			true false nullptr NULL #include #define #ifdef #ifndef #endif #if #else #pragma`),
+		types: words(`bool char double float int long short signed unsigned void size_t int8_t int16_t
+			int32_t int64_t uint8_t uint16_t uint32_t uint64_t std string`),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'",
+	}
+	langJava = &language{
+		keywords: words(`abstract assert break case catch class const continue default do else enum extends
+			final finally for if implements import instanceof interface native new package private protected
+			public return static super switch synchronized this throw throws try volatile while var val fun
+			when object null true false`),
+		types:        words(`boolean byte char double float int long short void String Object Int Long`),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'",
+	}
+	langShell = &language{
+		keywords: words(`if then else elif fi for while until do done case esac in function return local
+			export readonly set unset shift exit source echo`),
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+	langRuby = &language{
+		keywords: words(`alias and begin break case class def defined? do else elsif end ensure false for if
+			in module next nil not or redo rescue retry return self super then true undef unless until when
+			while yield require attr_reader attr_accessor`),
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+	langYAML = &language{
+		keywords:     words(`true false null yes no on off`),
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+	langJSON = &language{
+		keywords: words(`true false null`),
+		quotes:   "\"",
+	}
+	langSQL = &language{
+		keywords: words(`select from where insert into values update set delete create table drop alter add
+			index primary key foreign references join left right inner outer on group by order having limit
+			and or not null as distinct union SELECT FROM WHERE INSERT INTO VALUES UPDATE SET DELETE CREATE
+			TABLE DROP ALTER ADD INDEX PRIMARY KEY FOREIGN REFERENCES JOIN LEFT RIGHT INNER OUTER ON GROUP BY
+			ORDER HAVING LIMIT AND OR NOT NULL AS DISTINCT UNION`),
+		lineComments: []string{"--"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "'\"",
+	}
+)
+
+var languagesByExt = map[string]*language{
+	".go":   langGo,
+	".js":   langJS,
+	".jsx":  langJS,
+	".mjs":  langJS,
+	".cjs":  langJS,
+	".ts":   langJS,
+	".tsx":  langJS,
+	".py":   langPython,
+	".rs":   langRust,
+	".c":    langC,
+	".h":    langC,
+	".cc":   langC,
+	".cpp":  langC,
+	".hpp":  langC,
+	".java": langJava,
+	".kt":   langJava,
+	".kts":  langJava,
+	".sh":   langShell,
+	".bash": langShell,
+	".zsh":  langShell,
+	".rb":   langRuby,
+	".yml":  langYAML,
+	".yaml": langYAML,
+	".toml": langYAML,
+	".json": langJSON,
+	".sql":  langSQL,
+}
+
+var languagesByName = map[string]*language{
+	"Makefile":   langShell,
+	"Dockerfile": langShell,
+	"Gemfile":    langRuby,
+	"Rakefile":   langRuby,
+}
+
+// languageForPath đoán ngôn ngữ từ đuôi file, nil khi không hỗ trợ
+func languageForPath(path string) *language {
+	if path == "" {
+		return nil
+	}
+	if lang, ok := languagesByName[filepath.Base(path)]; ok {
+		return lang
+	}
+	return languagesByExt[strings.ToLower(filepath.Ext(path))]
+}
+
+// tokenize tách một dòng code thành token. inComment cho biết dòng bắt đầu bên
+// trong block comment; giá trị trả về thứ hai là trạng thái đó ở cuối dòng.
+func (l *language) tokenize(line string, inComment bool) ([]token, bool) {
+	var toks []token
+	emit := func(kind tokenKind, text string) {
+		if text == "" {
+			return
+		}
+		if n := len(toks); n > 0 && toks[n-1].kind == kind {
+			toks[n-1].text += text
+			return
+		}
+		toks = append(toks, token{kind: kind, text: text})
+	}
+
+	i := 0
+	for i < len(line) {
+		if inComment {
+			end := strings.Index(line[i:], l.blockComment[1])
+			if end < 0 {
+				emit(tokComment, line[i:])
+				return toks, true
+			}
+			end += i + len(l.blockComment[1])
+			emit(tokComment, line[i:end])
+			i = end
+			inComment = false
+			continue
+		}
+
+		rest := line[i:]
+		if l.blockComment[0] != "" && strings.HasPrefix(rest, l.blockComment[0]) {
+			emit(tokComment, l.blockComment[0])
+			i += len(l.blockComment[0])
+			inComment = true
+			continue
+		}
+		if l.isLineComment(rest) {
+			emit(tokComment, rest)
+			break
+		}
+
+		c := line[i]
+		switch {
+		case strings.IndexByte(l.quotes, c) >= 0:
+			end := scanString(line, i)
+			emit(tokString, line[i:end])
+			i = end
+		case isDigit(c) && (i == 0 || !isIdentByte(line[i-1])):
+			end := i + 1
+			for end < len(line) && (isIdentByte(line[end]) || line[end] == '.') {
+				end++
+			}
+			emit(tokNumber, line[i:end])
+			i = end
+		case isIdentByte(c) || c == '#' && l == langC:
+			end := i + 1
+			for end < len(line) && isIdentByte(line[end]) {
+				end++
+			}
+			if end < len(line) && line[end] == '?' && l == langRuby {
+				end++
+			}
+			word := line[i:end]
+			switch {
+			case l.keywords[word]:
+				emit(tokKeyword, word)
+			case l.types[word]:
+				emit(tokType, word)
+			default:
+				emit(tokPlain, word)
+			}
+			i = end
+		default:
+			emit(tokPlain, line[i:i+1])
+			i++
+		}
+	}
+	return toks, inComment
+}
+
+func (l *language) isLineComment(s string) bool {
+	for _, prefix := range l.lineComments {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// scanString trả về vị trí ngay sau dấu đóng của string bắt đầu tại start,
+// hoặc cuối dòng nếu string chưa đóng. Backslash escape trừ raw string `...`.
+func scanString(line string, start int) int {
+	quote := line[start]
+	for i := start + 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			return i + 1
+		}
+	}
+	return len(line)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isIdentByte coi byte UTF-8 không phải ASCII là một phần identifier để không cắt giữa rune
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || isDigit(c) || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
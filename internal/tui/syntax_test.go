@@ -0,0 +1,148 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name      string
+		lang      *language
+		line      string
+		inComment bool
+		want      []token
+		wantIn    bool
+	}{
+		{
+			name: "keywords and types",
+			lang: langGo,
+			line: "func f(s string) error",
+			want: []token{
+				{kind: tokKeyword, text: "func"},
+				{kind: tokPlain, text: " f(s "},
+				{kind: tokType, text: "string"},
+				{kind: tokPlain, text: ") "},
+				{kind: tokType, text: "error"},
+			},
+		},
+		{
+			name: "comment marker inside string",
+			lang: langGo,
+			line: `x := "a // b" // c`,
+			want: []token{
+				{kind: tokPlain, text: "x := "},
+				{kind: tokString, text: `"a // b"`},
+				{kind: tokPlain, text: " "},
+				{kind: tokComment, text: "// c"},
+			},
+		},
+		{
+			name: "escaped quote and number",
+			lang: langGo,
+			line: `s = "a\"b" + 12`,
+			want: []token{
+				{kind: tokPlain, text: "s = "},
+				{kind: tokString, text: `"a\"b"`},
+				{kind: tokPlain, text: " + "},
+				{kind: tokNumber, text: "12"},
+			},
+		},
+		{
+			name: "raw string ignores backslash",
+			lang: langGo,
+			line: "`a\\` + x1",
+			want: []token{
+				{kind: tokString, text: "`a\\`"},
+				{kind: tokPlain, text: " + x1"},
+			},
+		},
+		{
+			name: "unterminated string runs to end of line",
+			lang: langPython,
+			line: `print('oops`,
+			want: []token{
+				{kind: tokPlain, text: "print("},
+				{kind: tokString, text: `'oops`},
+			},
+		},
+		{
+			name: "block comment opens",
+			lang: langGo,
+			line: "a /* start",
+			want: []token{
+				{kind: tokPlain, text: "a "},
+				{kind: tokComment, text: "/* start"},
+			},
+			wantIn: true,
+		},
+		{
+			name:      "block comment carried from previous line closes",
+			lang:      langGo,
+			line:      "still */ return",
+			inComment: true,
+			want: []token{
+				{kind: tokComment, text: "still */"},
+				{kind: tokPlain, text: " "},
+				{kind: tokKeyword, text: "return"},
+			},
+		},
+		{
+			name:      "whole line inside block comment",
+			lang:      langGo,
+			line:      "return 1",
+			inComment: true,
+			want:      []token{{kind: tokComment, text: "return 1"}},
+			wantIn:    true,
+		},
+		{
+			name: "hash comment",
+			lang: langShell,
+			line: "echo hi # note",
+			want: []token{
+				{kind: tokKeyword, text: "echo"},
+				{kind: tokPlain, text: " hi "},
+				{kind: tokComment, text: "# note"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got, in := tt.lang.tokenize(tt.line, tt.inComment)
+		if !reflect.DeepEqual(got, tt.want) || in != tt.wantIn {
+			t.Errorf("%s: tokenize = %+v, %v; want %+v, %v", tt.name, got, in, tt.want, tt.wantIn)
+		}
+	}
+}
+
+func TestHighlighterCarriesCommentState(t *testing.T) {
+	h := newHighlighter("main.go")
+	h.tokens("/* open", toneContext)
+	// dòng xoá đóng comment chỉ ở phía old, phía new vẫn trong comment
+	h.tokens("*/ x", toneRemoved)
+	if got := h.tokens("return", toneAdded); len(got) != 1 || got[0].kind != tokComment {
+		t.Errorf("new side should still be in comment: %+v", got)
+	}
+	if got := h.sideTokens("return", true); len(got) != 1 || got[0].kind != tokKeyword {
+		t.Errorf("old side should be out of comment: %+v", got)
+	}
+
+	h.setPath("notes.txt")
+	if got := h.tokens("/* return", toneContext); !reflect.DeepEqual(got, []token{{kind: tokPlain, text: "/* return"}}) {
+		t.Errorf("unknown language should be plain: %+v", got)
+	}
+}
+
+func TestLanguageForPath(t *testing.T) {
+	tests := map[string]*language{
+		"internal/app/model.go": langGo,
+		"web/App.TSX":           langJS,
+		"Makefile":              languagesByName["Makefile"],
+		"README":                nil,
+		"":                      nil,
+	}
+	for path, want := range tests {
+		if got := languageForPath(path); got != want {
+			t.Errorf("languageForPath(%q) = %p, want %p", path, got, want)
+		}
+	}
+}
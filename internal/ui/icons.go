@@ -33,6 +33,11 @@ type Icons struct {
 	AheadCommits  string // ↑ - up arrow (commits ahead)
 	BehindCommits string // ↓ - down arrow (commits behind)
 
+	// Commit Signature Icons
+	SignatureGood    string // ✓ - checkmark (chữ ký hợp lệ)
+	SignatureBad     string // ✗ - X mark (chữ ký sai/bị revoke)
+	SignatureUnknown string // ? - question mark (không xác minh được)
+
 	// Navigation & UI Icons
 	ExpandedFolder    string // ▼ - down triangle (folder mở)
 	CollapsedFolder   string // ▶ - right triangle (folder đóng)
@@ -72,6 +77,11 @@ var DefaultIcons = Icons{
 	AheadCommits:  "↑", // U+2191 - Upwards Arrow
 	BehindCommits: "↓", // U+2193 - Downwards Arrow
 
+	// Commit Signatures
+	SignatureGood:    "✓", // U+2713 - Check Mark
+	SignatureBad:     "✗", // U+2717 - Ballot X
+	SignatureUnknown: "?", // ASCII question mark
+
 	// Navigation & UI
 	ExpandedFolder:    "▼", // U+25BC - Black Down-Pointing Triangle
 	CollapsedFolder:   "▶", // U+25B6 - Black Right-Pointing Triangle
@@ -111,6 +121,11 @@ var AlternativeIcons = Icons{
 	AheadCommits:  "+", // ASCII plus
 	BehindCommits: "-", // ASCII minus
 
+	// Commit Signatures
+	SignatureGood:    "✓", // Giữ nguyên vì tương thích cao
+	SignatureBad:     "x", // ASCII x
+	SignatureUnknown: "?", // ASCII question mark
+
 	// Navigation & UI
 	ExpandedFolder:    "v", // ASCII v
 	CollapsedFolder:   ">", // ASCII greater than
@@ -166,6 +181,20 @@ func (icons Icons) GetCommitCountIcon(isAhead bool) string {
 	return icons.BehindCommits
 }
 
+// GetSignatureIcon trả về icon cho trạng thái chữ ký commit (good/bad/unknown), rỗng nếu không ký
+func (icons Icons) GetSignatureIcon(status string) string {
+	switch status {
+	case "good":
+		return icons.SignatureGood
+	case "bad":
+		return icons.SignatureBad
+	case "unknown":
+		return icons.SignatureUnknown
+	default:
+		return ""
+	}
+}
+
 // GetToastIcon trả về icon cho toast notification dựa trên string level
 func (icons Icons) GetToastIcon(level string) string {
 	switch level {
@@ -109,6 +109,20 @@ func TestFetchStatusIcons(t *testing.T) {
 	}
 }
 
+// TestSignatureIcons tests commit signature badges
+func TestSignatureIcons(t *testing.T) {
+	icons := DefaultIcons
+
+	for _, status := range []string{"good", "bad", "unknown"} {
+		if icons.GetSignatureIcon(status) == "" {
+			t.Errorf("Signature status %q should have an icon", status)
+		}
+	}
+	if icons.GetSignatureIcon("none") != "" {
+		t.Error("Unsigned commits should not have a signature icon")
+	}
+}
+
 // TestAlternativeIcons ensures fallback icons work
 func TestAlternativeIcons(t *testing.T) {
 	icons := AlternativeIcons
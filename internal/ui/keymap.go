@@ -26,6 +26,7 @@ var DefaultKeymap = Keymap{
 		{Keys: []string{"q"}, Help: "quit", Action: "quit"},
 		{Keys: []string{"p"}, Help: "pull", Action: "git_pull"},
 		{Keys: []string{"P"}, Help: "push", Action: "git_push"},
+		{Keys: []string{"F"}, Help: "force push (with lease)", Action: "git_force_push"},
 		{Keys: []string{"f"}, Help: "fetch", Action: "git_fetch"},
 		{Keys: []string{"R"}, Help: "refresh", Action: "refresh_all"},
 		{Keys: []string{"1"}, Help: "files", Action: "focus_files"},
@@ -33,25 +34,41 @@ var DefaultKeymap = Keymap{
 		{Keys: []string{"3"}, Help: "commits", Action: "focus_commits"},
 		{Keys: []string{"4"}, Help: "stash", Action: "focus_stash"},
 		{Keys: []string{"5"}, Help: "main", Action: "focus_main"},
+		{Keys: []string{"b"}, Help: "branch here (detached)", Action: "create_branch_here"},
+		{Keys: []string{"-"}, Help: "back to branch (detached)", Action: "checkout_previous_branch"},
+		{Keys: []string{"z"}, Help: "undo", Action: "undo"},
+		{Keys: []string{"Z"}, Help: "redo", Action: "redo"},
+		{Keys: []string{"ctrl+x"}, Help: "cancel running command", Action: "cancel_command"},
+		{Keys: []string{"C"}, Help: "continue merge/rebase/cherry-pick", Action: "continue_operation"},
+		{Keys: []string{"X"}, Help: "abort merge/rebase/cherry-pick", Action: "abort_operation"},
+		{Keys: []string{"S"}, Help: "skip current step", Action: "skip_operation"},
 	},
 	Files: []Binding{
 		{Keys: []string{"space"}, Help: "stage/unstage", Action: "toggle_stage"},
 		{Keys: []string{"a"}, Help: "stage all", Action: "stage_all"},
 		{Keys: []string{"c"}, Help: "commit", Action: "open_commit"},
 		{Keys: []string{"A"}, Help: "amend", Action: "open_amend"},
-		{Keys: []string{"d"}, Help: "discard", Action: "discard_changes"},
+		{Keys: []string{"d"}, Help: "discard…", Action: "discard_changes"},
 		{Keys: []string{"e"}, Help: "edit", Action: "edit_file"},
 		{Keys: []string{"o"}, Help: "open", Action: "open_file"},
 		{Keys: []string{"s"}, Help: "stash", Action: "stash_changes"},
-		{Keys: []string{"enter"}, Help: "view diff", Action: "view_file_diff"},
+		{Keys: []string{"i"}, Help: "ignore", Action: "ignore_file"},
+		{Keys: []string{"v", "shift+down", "shift+up"}, Help: "select range", Action: "range_select"},
+		{Keys: []string{"H"}, Help: "stage hunks", Action: "hunk_view"},
+		{Keys: []string{"enter"}, Help: "view diff / fold dir", Action: "view_file_diff"},
+		{Keys: []string{"`"}, Help: "tree/flat view", Action: "toggle_file_tree"},
 	},
 	Branches: []Binding{
 		{Keys: []string{"space"}, Help: "checkout", Action: "checkout_branch"},
 		{Keys: []string{"n"}, Help: "new branch", Action: "create_branch"},
+		{Keys: []string{"R"}, Help: "rename", Action: "rename_branch"},
+		{Keys: []string{"u"}, Help: "upstream", Action: "upstream_options"},
+		{Keys: []string{"f"}, Help: "fast-forward", Action: "fast_forward_branch"},
 		{Keys: []string{"d"}, Help: "delete", Action: "delete_branch"},
 		{Keys: []string{"D"}, Help: "force delete", Action: "force_delete_branch"},
 		{Keys: []string{"r"}, Help: "rebase", Action: "rebase_branch"},
 		{Keys: []string{"m"}, Help: "merge", Action: "merge_branch"},
+		{Keys: []string{"W"}, Help: "compare refs", Action: "compare_refs"},
 		{Keys: []string{"enter"}, Help: "view commits", Action: "view_branch_commits"},
 	},
 	Commits: []Binding{
@@ -60,12 +77,20 @@ var DefaultKeymap = Keymap{
 		{Keys: []string{"R"}, Help: "reset", Action: "reset_to_commit"},
 		{Keys: []string{"c"}, Help: "cherry-pick", Action: "cherry_pick"},
 		{Keys: []string{"space"}, Help: "checkout", Action: "checkout_commit"},
+		{Keys: []string{"F"}, Help: "fixup commit", Action: "fixup_commit"},
+		{Keys: []string{"A"}, Help: "amend commit", Action: "amend_commit"},
+		{Keys: []string{"S"}, Help: "autosquash", Action: "autosquash"},
+		{Keys: []string{"e"}, Help: "export patch", Action: "export_patch"},
+		{Keys: []string{"I"}, Help: "import patches", Action: "import_patches"},
+		{Keys: []string{"B"}, Help: "build custom patch", Action: "build_patch"},
+		{Keys: []string{"W"}, Help: "compare refs", Action: "compare_refs"},
 	},
 	Stash: []Binding{
 		{Keys: []string{"space"}, Help: "apply", Action: "stash_apply"},
 		{Keys: []string{"p"}, Help: "pop", Action: "stash_pop"},
 		{Keys: []string{"d"}, Help: "drop", Action: "stash_drop"},
 		{Keys: []string{"enter"}, Help: "view", Action: "view_stash"},
+		{Keys: []string{"W"}, Help: "compare refs", Action: "compare_refs"},
 	},
 	Main: []Binding{
 		{Keys: []string{"j"}, Help: "down", Action: "scroll_down"},
@@ -74,6 +99,16 @@ var DefaultKeymap = Keymap{
 		{Keys: []string{"u"}, Help: "page up", Action: "page_up"},
 		{Keys: []string{"g"}, Help: "top", Action: "scroll_top"},
 		{Keys: []string{"G"}, Help: "bottom", Action: "scroll_bottom"},
+		{Keys: []string{"w"}, Help: "whitespace", Action: "toggle_diff_whitespace"},
+		{Keys: []string{"{", "}"}, Help: "context -/+", Action: "diff_context"},
+		{Keys: []string{"a"}, Help: "diff algorithm", Action: "cycle_diff_algorithm"},
+		{Keys: []string{"M"}, Help: "rename detection", Action: "cycle_diff_renames"},
+		{Keys: []string{"W"}, Help: "word diff", Action: "toggle_word_diff"},
+		{Keys: []string{"L"}, Help: "LFS smudged diff", Action: "toggle_lfs_smudge"},
+		{Keys: []string{"|"}, Help: "side-by-side", Action: "toggle_side_by_side"},
+		{Keys: []string{"n", "N"}, Help: "next/prev hunk", Action: "jump_hunk"},
+		{Keys: []string{"]", "["}, Help: "next/prev file", Action: "jump_file"},
+		{Keys: []string{"l"}, Help: "jump to file", Action: "diff_file_list"},
 	},
 	CmdLog: []Binding{
 		{Keys: []string{"j"}, Help: "down", Action: "scroll_down"},
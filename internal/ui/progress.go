@@ -0,0 +1,22 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProgressBar render thanh tiến trình dạng "[████░░░░░░]  40%".
+// percent < 0 (không biết tổng) render thanh rỗng không kèm phần trăm.
+func ProgressBar(percent, width int) string {
+	if width < 1 {
+		width = 1
+	}
+	if percent < 0 {
+		return "[" + strings.Repeat("░", width) + "]"
+	}
+	if percent > 100 {
+		percent = 100
+	}
+	filled := percent * width / 100
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", width-filled) + "]" + fmt.Sprintf(" %3d%%", percent)
+}
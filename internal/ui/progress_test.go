@@ -0,0 +1,22 @@
+package ui
+
+import "testing"
+
+func TestProgressBar(t *testing.T) {
+	tests := []struct {
+		percent, width int
+		want           string
+	}{
+		{0, 4, "[░░░░]   0%"},
+		{50, 4, "[██░░]  50%"},
+		{100, 4, "[████] 100%"},
+		{150, 4, "[████] 100%"},
+		{-1, 3, "[░░░]"},
+		{40, 0, "[░]  40%"},
+	}
+	for _, tt := range tests {
+		if got := ProgressBar(tt.percent, tt.width); got != tt.want {
+			t.Errorf("ProgressBar(%d, %d) = %q, want %q", tt.percent, tt.width, got, tt.want)
+		}
+	}
+}
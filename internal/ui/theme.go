@@ -125,6 +125,9 @@ type Styles struct {
 	ErrorStyle   lipgloss.Style
 	OptionsStyle lipgloss.Style
 
+	// Banner (detached HEAD, ...)
+	WarningBannerStyle lipgloss.Style
+
 	// Fetch status
 	FetchingStyle     lipgloss.Style
 	FetchSuccessStyle lipgloss.Style
@@ -183,6 +186,9 @@ func NewStyles(t Theme) Styles {
 		ErrorStyle:   lipgloss.NewStyle().Foreground(t.Error),
 		OptionsStyle: lipgloss.NewStyle().Foreground(t.Options),
 
+		// Banner
+		WarningBannerStyle: lipgloss.NewStyle().Background(t.Warning).Foreground(lipgloss.Color("0")).Bold(true),
+
 		// Fetch status
 		FetchingStyle:     lipgloss.NewStyle().Foreground(t.Fetching),
 		FetchSuccessStyle: lipgloss.NewStyle().Foreground(t.FetchSuccess),